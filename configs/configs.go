@@ -14,6 +14,8 @@ type Config struct {
 	Twilio  TwilioConfig  `json:"twilio"`
 	Redis   RedisConfig   `json:"redis"`
 	CORS   CORSConfig   `json:"cors"`
+	Rent    RentConfig    `json:"rent"`
+	Storage StorageConfig `json:"storage"`
 }
 
 func LoadConfig(configPath string) (*Config, error) {
@@ -58,6 +60,14 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := cfg.Rent.LoadAndValidate(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Storage.LoadAndValidate(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
@@ -115,4 +125,20 @@ func (config *Config) GetCORSConfig() CORSConfig {
 		panic("Config not loaded. Call LoadConfig() first.")
 	}
 	return config.CORS
+}
+
+// GetRentConfig returns the Rent configuration
+func (config *Config) GetRentConfig() RentConfig {
+	if config == nil {
+		panic("Config not loaded. Call LoadConfig() first.")
+	}
+	return config.Rent
+}
+
+// GetStorageConfig returns the Storage configuration
+func (config *Config) GetStorageConfig() StorageConfig {
+	if config == nil {
+		panic("Config not loaded. Call LoadConfig() first.")
+	}
+	return config.Storage
 }
\ No newline at end of file
@@ -0,0 +1,31 @@
+package configs
+
+type RentConfig struct {
+	NoticePeriodInDays                int `json:"notice_period_in_days"`
+	TerminationCheckIntervalInSeconds int `json:"termination_check_interval_in_seconds"`
+	LifecycleCheckIntervalInSeconds   int `json:"lifecycle_check_interval_in_seconds"`
+	// RecordConfirmationWindowInDays is how long a tenant can confirm or dispute a payment the
+	// landlord recorded for them.
+	RecordConfirmationWindowInDays int `json:"record_confirmation_window_in_days"`
+	// ReversalWindowInDays is how long after approving a record the landlord can reverse it.
+	ReversalWindowInDays int `json:"reversal_window_in_days"`
+}
+
+func (r *RentConfig) LoadAndValidate() error {
+	if r.NoticePeriodInDays <= 0 {
+		r.NoticePeriodInDays = 30
+	}
+	if r.TerminationCheckIntervalInSeconds <= 0 {
+		r.TerminationCheckIntervalInSeconds = 3600 // 1 hour
+	}
+	if r.LifecycleCheckIntervalInSeconds <= 0 {
+		r.LifecycleCheckIntervalInSeconds = 900 // 15 minutes
+	}
+	if r.RecordConfirmationWindowInDays <= 0 {
+		r.RecordConfirmationWindowInDays = 7
+	}
+	if r.ReversalWindowInDays <= 0 {
+		r.ReversalWindowInDays = 30
+	}
+	return nil
+}
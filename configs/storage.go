@@ -0,0 +1,72 @@
+package configs
+
+import (
+	"os"
+	customerr "sample-web/errors"
+)
+
+type StorageConfig struct {
+	// Driver is local or s3, local files are served by the API itself through signed URLs.
+	Driver                       string   `json:"driver"`
+	LocalPath                    string   `json:"local_path"`
+	BaseURL                      string   `json:"base_url"`
+	SignedURLExpirationInSeconds int      `json:"signed_url_expiration_in_seconds"`
+	MaxAttachmentSizeInBytes     int64    `json:"max_attachment_size_in_bytes"`
+	S3                           S3Config `json:"s3"`
+	SigningKey                   string   `json:"-"`
+}
+
+type S3Config struct {
+	Endpoint        string `json:"endpoint"`
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	AccessKeyId     string `json:"-"`
+	SecretAccessKey string `json:"-"`
+}
+
+func (storage *StorageConfig) validate() error {
+	switch storage.Driver {
+	case "local":
+		if storage.LocalPath == "" {
+			return customerr.MissingConfigError{Message: "storage local_path is not set"}
+		}
+		if storage.BaseURL == "" {
+			return customerr.MissingConfigError{Message: "storage base_url is not set"}
+		}
+		if storage.SigningKey == "" {
+			return customerr.MissingConfigError{Message: "STORAGE_SIGNING_KEY is not set"}
+		}
+	case "s3":
+		if storage.S3.Endpoint == "" || storage.S3.Region == "" || storage.S3.Bucket == "" {
+			return customerr.MissingConfigError{Message: "storage s3 endpoint, region and bucket must be set"}
+		}
+		if storage.S3.AccessKeyId == "" {
+			return customerr.MissingConfigError{Message: "S3_ACCESS_KEY_ID is not set"}
+		}
+		if storage.S3.SecretAccessKey == "" {
+			return customerr.MissingConfigError{Message: "S3_SECRET_ACCESS_KEY is not set"}
+		}
+	default:
+		return customerr.MissingConfigError{Message: "storage driver must be local or s3"}
+	}
+	return nil
+}
+
+func (storage *StorageConfig) LoadAndValidate() error {
+	if storage.Driver == "" {
+		storage.Driver = "local"
+	}
+	if storage.SignedURLExpirationInSeconds <= 0 {
+		storage.SignedURLExpirationInSeconds = 300 // 5 minutes
+	}
+	if storage.MaxAttachmentSizeInBytes <= 0 {
+		storage.MaxAttachmentSizeInBytes = 5 << 20 // 5 MiB
+	}
+	storage.SigningKey = os.Getenv("STORAGE_SIGNING_KEY")
+	storage.S3.AccessKeyId = os.Getenv("S3_ACCESS_KEY_ID")
+	storage.S3.SecretAccessKey = os.Getenv("S3_SECRET_ACCESS_KEY")
+	if err := storage.validate(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -5,10 +5,16 @@ import (
 	customerr "sample-web/errors"
 )
 
+// TwilioConfig is read from the environment only, the "twilio" section of the config file is empty.
 type TwilioConfig struct {
+	// AccountSID and AuthToken are read from TWILIO_ACCOUNT_SID and TWILIO_AUTH_TOKEN.
 	AccountSID string
 	AuthToken  string
+	// ServiceSID is read from TWILIO_VERIFY_SERVICE_SID, the Verify service sends and checks the login OTPs.
 	ServiceSID string
+	// MessagingServiceSID is read from TWILIO_MESSAGING_SERVICE_SID, the Messaging service sends the
+	// notification SMS and the OTPs that sign agreements.
+	MessagingServiceSID string
 }
 
 func (config *TwilioConfig) validate() error {
@@ -21,6 +27,9 @@ func (config *TwilioConfig) validate() error {
 	if config.ServiceSID == "" {
 		return customerr.MissingConfigError{Message: "TWILIO_VERIFY_SERVICE_SID is not set"}
 	}
+	if config.MessagingServiceSID == "" {
+		return customerr.MissingConfigError{Message: "TWILIO_MESSAGING_SERVICE_SID is not set, it is needed to send notification SMS"}
+	}
 	return nil
 }
 
@@ -28,6 +37,7 @@ func (config *TwilioConfig) LoadAndValidate() error {
 	config.AccountSID = os.Getenv("TWILIO_ACCOUNT_SID")
 	config.AuthToken = os.Getenv("TWILIO_AUTH_TOKEN")
 	config.ServiceSID = os.Getenv("TWILIO_VERIFY_SERVICE_SID")
+	config.MessagingServiceSID = os.Getenv("TWILIO_MESSAGING_SERVICE_SID")
 	if err := config.validate(); err != nil {
 		return err
 	}
@@ -0,0 +1,310 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/pdf"
+	"sample-web/services"
+	"sample-web/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type AgreementController interface {
+	CreateAgreementTemplate(ctx *gin.Context)
+	GetAgreementTemplates(ctx *gin.Context)
+	GenerateAgreement(ctx *gin.Context)
+	GetAgreements(ctx *gin.Context)
+	DownloadAgreement(ctx *gin.Context)
+	RequestSignatureOTP(ctx *gin.Context)
+	SignAgreement(ctx *gin.Context)
+	VerifyAgreement(ctx *gin.Context)
+}
+
+type agreementController struct {
+	agreementService services.AgreementService
+}
+
+func NewAgreementController(agreementService services.AgreementService) AgreementController {
+	return &agreementController{
+		agreementService: agreementService,
+	}
+}
+
+func (a *agreementController) CreateAgreementTemplate(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "AgreementController.CreateAgreementTemplate")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	var templateRequest dto.AgreementTemplateRequest
+	if err := ctx.ShouldBindJSON(&templateRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	agreementTemplate, err := a.agreementService.CreateTemplate(spanCtx, landLordId.(string), templateRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to create agreement template with %s", err.Error()))
+		if mongo.IsDuplicateKeyError(err) {
+			ctx.Error(customerr.NewAppError(http.StatusConflict, "The template was saved concurrently, try again", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	log.Info(spanCtx, "Agreement template created successfully")
+	ctx.JSON(http.StatusCreated, agreementTemplate)
+}
+
+func (a *agreementController) GetAgreementTemplates(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "AgreementController.GetAgreementTemplates")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	templates, err := a.agreementService.GetTemplates(spanCtx, landLordId.(string))
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get agreement templates with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get agreement templates", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, templates)
+}
+
+func (a *agreementController) GenerateAgreement(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "AgreementController.GenerateAgreement")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	// the body is optional, without it the latest template is used
+	var agreementRequest dto.AgreementRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&agreementRequest); err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+			ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+			return
+		}
+	}
+
+	agreement, err := a.agreementService.GenerateAgreement(spanCtx, landLordId.(string), rentId, agreementRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to generate agreement with %s", err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "Rent or template not found", err))
+			return
+		}
+		var textErr pdf.UnsupportedTextError
+		if errors.As(err, &textErr) {
+			ctx.Error(customerr.NewAppError(http.StatusUnprocessableEntity, textErr.Error(), err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Failed to generate agreement", err))
+		return
+	}
+
+	log.Info(spanCtx, "Agreement generated successfully")
+	ctx.JSON(http.StatusCreated, agreement)
+}
+
+func (a *agreementController) GetAgreements(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "AgreementController.GetAgreements")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	agreements, err := a.agreementService.GetAgreements(spanCtx, userId.(string), rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get agreements with %s", err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "Rent not found", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get agreements", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, agreements)
+}
+
+func (a *agreementController) DownloadAgreement(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "AgreementController.DownloadAgreement")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+
+	agreementId := ctx.Param("agreement_id")
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	agreement, body, err := a.agreementService.GetAgreementDocument(spanCtx, userId.(string), rentId, agreementId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get agreement document with %s", err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "Agreement not found", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get agreement document", err))
+		return
+	}
+	defer body.Close()
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "agreement-"+agreement.Id.Hex()+".pdf"))
+	ctx.DataFromReader(http.StatusOK, -1, "application/pdf", body, nil)
+}
+
+func (a *agreementController) RequestSignatureOTP(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "AgreementController.RequestSignatureOTP")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+
+	agreementId := ctx.Param("agreement_id")
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	if err := a.agreementService.RequestSignatureOTP(spanCtx, userId.(string), rentId, agreementId); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to send signature OTP with %s", err.Error()))
+		a.signatureError(ctx, err, "Failed to send signature OTP")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "OTP sent successfully"})
+}
+
+func (a *agreementController) SignAgreement(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "AgreementController.SignAgreement")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+
+	agreementId := ctx.Param("agreement_id")
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	var signatureRequest dto.AgreementSignatureRequest
+	if err := ctx.ShouldBindJSON(&signatureRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	agreement, err := a.agreementService.SignAgreement(spanCtx, userId.(string), rentId, agreementId, signatureRequest.Code, ctx.ClientIP())
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to sign agreement with %s", err.Error()))
+		a.signatureError(ctx, err, "Failed to sign agreement")
+		return
+	}
+
+	log.Info(spanCtx, "Agreement signed successfully")
+	ctx.JSON(http.StatusOK, agreement)
+}
+
+func (a *agreementController) VerifyAgreement(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "AgreementController.VerifyAgreement")
+	defer span.End()
+
+	hash := ctx.Param("hash")
+
+	verification, err := a.agreementService.VerifyAgreement(spanCtx, hash)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to verify agreement with %s", err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "No agreement matches the hash", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to verify agreement", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, verification)
+}
+
+func (a *agreementController) signatureError(ctx *gin.Context, err error, message string) {
+	var otpErr customerr.InvalidOTPError
+	var signedErr customerr.AgreementAlreadySignedError
+	var blockedErr *services.PhoneNumberBlockedError
+	switch {
+	case errors.As(err, &otpErr):
+		ctx.Error(customerr.NewAppError(http.StatusUnauthorized, otpErr.Error(), err))
+	case errors.As(err, &signedErr):
+		ctx.Error(customerr.NewAppError(http.StatusConflict, signedErr.Error(), err))
+	case errors.As(err, &blockedErr):
+		ctx.Error(customerr.NewAppError(http.StatusTooManyRequests, blockedErr.Error(), err))
+	case errors.Is(err, mongo.ErrNoDocuments):
+		ctx.Error(customerr.NewAppError(http.StatusNotFound, "Agreement not found", err))
+	default:
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, message, err))
+	}
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/services"
+	"sample-web/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DashboardController interface {
+	GetLandLordDashboard(ctx *gin.Context)
+	GetTenantDashboard(ctx *gin.Context)
+}
+
+type dashboardController struct {
+	dashboardService services.DashboardService
+}
+
+func NewDashboardController(dashboardService services.DashboardService) DashboardController {
+	return &dashboardController{
+		dashboardService: dashboardService,
+	}
+}
+
+func (d *dashboardController) GetLandLordDashboard(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "DashboardController.GetLandLordDashboard")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	var dashboardQuery dto.DashboardQuery
+	if err := ctx.ShouldBindQuery(&dashboardQuery); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind query with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+
+	// the range defaults to the last twelve months including the current one, to is inclusive
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if dashboardQuery.To != "" {
+		to, _ = time.Parse("2006-01-02", dashboardQuery.To)
+	}
+	from := time.Date(to.Year(), to.Month()-11, 1, 0, 0, 0, 0, time.UTC)
+	if dashboardQuery.From != "" {
+		from, _ = time.Parse("2006-01-02", dashboardQuery.From)
+	}
+
+	dashboard, err := d.dashboardService.GetLandLordDashboard(spanCtx, landLordId.(string), from, to.AddDate(0, 0, 1), dashboardQuery.ReportCurrency)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get landlord dashboard with %s", err.Error()))
+		var roleErr customerr.RoleNotHeldError
+		if errors.As(err, &roleErr) {
+			ctx.Error(customerr.NewAppError(http.StatusForbidden, "Landlord role is required", err))
+			return
+		}
+		var rateErr customerr.MissingExchangeRateError
+		if errors.As(err, &rateErr) {
+			ctx.Error(customerr.NewAppError(http.StatusUnprocessableEntity, rateErr.Error(), err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get landlord dashboard", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dashboard)
+}
+
+func (d *dashboardController) GetTenantDashboard(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "DashboardController.GetTenantDashboard")
+	defer span.End()
+
+	tenantId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Tenant ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Tenant ID is empty", nil))
+		return
+	}
+
+	var dashboardQuery dto.TenantDashboardQuery
+	if err := ctx.ShouldBindQuery(&dashboardQuery); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind query with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+
+	dashboard, err := d.dashboardService.GetTenantDashboard(spanCtx, tenantId.(string), dashboardQuery.ReportCurrency)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get tenant dashboard with %s", err.Error()))
+		var roleErr customerr.RoleNotHeldError
+		if errors.As(err, &roleErr) {
+			ctx.Error(customerr.NewAppError(http.StatusForbidden, "Tenant role is required", err))
+			return
+		}
+		var rateErr customerr.MissingExchangeRateError
+		if errors.As(err, &rateErr) {
+			ctx.Error(customerr.NewAppError(http.StatusUnprocessableEntity, rateErr.Error(), err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get tenant dashboard", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dashboard)
+}
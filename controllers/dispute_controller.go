@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/services"
+	"sample-web/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type DisputeController interface {
+	OpenDispute(ctx *gin.Context)
+	GetRecordDisputes(ctx *gin.Context)
+	GetDisputes(ctx *gin.Context)
+	GetDisputeById(ctx *gin.Context)
+	AddDisputeMessage(ctx *gin.Context)
+	ReviewDispute(ctx *gin.Context)
+	ResolveDispute(ctx *gin.Context)
+}
+
+type disputeController struct {
+	disputeService services.DisputeService
+}
+
+func NewDisputeController(disputeService services.DisputeService) DisputeController {
+	return &disputeController{
+		disputeService: disputeService,
+	}
+}
+
+func (d *disputeController) OpenDispute(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "DisputeController.OpenDispute")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	rentRecordId := ctx.Param("record_id")
+
+	tenantId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Tenant ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Tenant ID is empty", nil))
+		return
+	}
+
+	var disputeRequest dto.DisputeRequest
+	if err := ctx.ShouldBindJSON(&disputeRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	dispute, err := d.disputeService.OpenDispute(spanCtx, tenantId.(string), rentId, rentRecordId, disputeRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to open dispute with %s", err.Error()))
+		d.disputeError(ctx, err, "Failed to open dispute")
+		return
+	}
+
+	log.Info(spanCtx, "Dispute opened successfully")
+	ctx.JSON(http.StatusCreated, dispute)
+}
+
+func (d *disputeController) GetRecordDisputes(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "DisputeController.GetRecordDisputes")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	rentRecordId := ctx.Param("record_id")
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	disputes, err := d.disputeService.GetRecordDisputes(spanCtx, userId.(string), rentId, rentRecordId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get disputes with %s", err.Error()))
+		d.disputeError(ctx, err, "Failed to get disputes")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, disputes)
+}
+
+func (d *disputeController) GetDisputes(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "DisputeController.GetDisputes")
+	defer span.End()
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	userRole, exists := ctx.Get("current_role")
+	if !exists {
+		log.Error(spanCtx, "User role is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User role is empty", nil))
+		return
+	}
+
+	var disputeQuery dto.DisputeQuery
+	if err := ctx.ShouldBindQuery(&disputeQuery); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind query with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+
+	disputes, err := d.disputeService.GetDisputes(spanCtx, userId.(string), userRole.(string), disputeQuery.Status)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get disputes with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get disputes", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, disputes)
+}
+
+func (d *disputeController) GetDisputeById(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "DisputeController.GetDisputeById")
+	defer span.End()
+
+	disputeId := ctx.Param("dispute_id")
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	dispute, err := d.disputeService.GetDisputeById(spanCtx, userId.(string), disputeId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get dispute with %s", err.Error()))
+		d.disputeError(ctx, err, "Failed to get dispute")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dispute)
+}
+
+func (d *disputeController) AddDisputeMessage(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "DisputeController.AddDisputeMessage")
+	defer span.End()
+
+	disputeId := ctx.Param("dispute_id")
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	var messageRequest dto.DisputeMessageRequest
+	if err := ctx.ShouldBindJSON(&messageRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	dispute, err := d.disputeService.AddMessage(spanCtx, userId.(string), disputeId, messageRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to add dispute message with %s", err.Error()))
+		d.disputeError(ctx, err, "Failed to add dispute message")
+		return
+	}
+
+	log.Info(spanCtx, "Dispute message added successfully")
+	ctx.JSON(http.StatusCreated, dispute)
+}
+
+func (d *disputeController) ReviewDispute(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "DisputeController.ReviewDispute")
+	defer span.End()
+
+	disputeId := ctx.Param("dispute_id")
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	dispute, err := d.disputeService.ReviewDispute(spanCtx, landLordId.(string), disputeId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to review dispute with %s", err.Error()))
+		d.disputeError(ctx, err, "Failed to review dispute")
+		return
+	}
+
+	log.Info(spanCtx, "Dispute under review")
+	ctx.JSON(http.StatusOK, dispute)
+}
+
+func (d *disputeController) ResolveDispute(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "DisputeController.ResolveDispute")
+	defer span.End()
+
+	disputeId := ctx.Param("dispute_id")
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	var resolveRequest dto.ResolveDisputeRequest
+	if err := ctx.ShouldBindJSON(&resolveRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	dispute, err := d.disputeService.ResolveDispute(spanCtx, landLordId.(string), disputeId, resolveRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to resolve dispute with %s", err.Error()))
+		d.disputeError(ctx, err, "Failed to resolve dispute")
+		return
+	}
+
+	log.Info(spanCtx, "Dispute resolved successfully")
+	ctx.JSON(http.StatusOK, dispute)
+}
+
+// disputeError maps the errors of the dispute service, everything that is not a missing document or
+// a conflicting reference is a request the dispute does not allow.
+func (d *disputeController) disputeError(ctx *gin.Context, err error, message string) {
+	var duplicateErr customerr.DuplicatePaymentReferenceError
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		ctx.Error(customerr.NewAppError(http.StatusNotFound, "Dispute or rent record not found", err))
+	case errors.As(err, &duplicateErr):
+		ctx.Error(customerr.NewAppError(http.StatusConflict, duplicateErr.Error(), err))
+	default:
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err.Error()), err))
+	}
+}
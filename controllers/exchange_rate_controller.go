@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/services"
+	"sample-web/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type ExchangeRateController interface {
+	CreateExchangeRate(ctx *gin.Context)
+	GetExchangeRates(ctx *gin.Context)
+}
+
+type exchangeRateController struct {
+	exchangeRateService services.ExchangeRateService
+}
+
+func NewExchangeRateController(exchangeRateService services.ExchangeRateService) ExchangeRateController {
+	return &exchangeRateController{
+		exchangeRateService: exchangeRateService,
+	}
+}
+
+func (e *exchangeRateController) CreateExchangeRate(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "ExchangeRateController.CreateExchangeRate")
+	defer span.End()
+
+	adminId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Admin ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Admin ID is empty", nil))
+		return
+	}
+
+	var rateRequest dto.ExchangeRateRequest
+	if err := ctx.ShouldBindJSON(&rateRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	rate, err := e.exchangeRateService.CreateRate(spanCtx, adminId.(string), rateRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to create exchange rate with %s", err.Error()))
+		var roleErr customerr.RoleNotHeldError
+		if errors.As(err, &roleErr) {
+			ctx.Error(customerr.NewAppError(http.StatusForbidden, "Admin role is required", err))
+			return
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			ctx.Error(customerr.NewAppError(http.StatusConflict, "A rate for this currency pair and date exists already", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Failed to create exchange rate", err))
+		return
+	}
+
+	log.Info(spanCtx, "Exchange rate created successfully")
+	ctx.JSON(http.StatusCreated, rate)
+}
+
+func (e *exchangeRateController) GetExchangeRates(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "ExchangeRateController.GetExchangeRates")
+	defer span.End()
+
+	var rateQuery dto.ExchangeRateQuery
+	if err := ctx.ShouldBindQuery(&rateQuery); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind query with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+
+	rates, err := e.exchangeRateService.GetRates(spanCtx, rateQuery.Currency)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get exchange rates with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get exchange rates", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rates)
+}
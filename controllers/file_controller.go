@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	customerr "sample-web/errors"
+	"sample-web/storage"
+	"sample-web/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FileController interface {
+	GetFile(ctx *gin.Context)
+}
+
+type fileController struct {
+	localStorage *storage.LocalStorage
+}
+
+// NewFileController serves the files of the local storage, localStorage is nil when files are kept
+// in a bucket and clients download them from it directly.
+func NewFileController(localStorage *storage.LocalStorage) FileController {
+	return &fileController{
+		localStorage: localStorage,
+	}
+}
+
+func (f *fileController) GetFile(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "FileController.GetFile")
+	defer span.End()
+
+	if f.localStorage == nil {
+		ctx.Error(customerr.NewAppError(http.StatusNotFound, "file not found", nil))
+		return
+	}
+
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+
+	// the signature is the only authorization of the request, the url is handed out to rent participants
+	if err := f.localStorage.Verify(key, ctx.Query("expires"), ctx.Query("signature")); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("invalid signed url for %s with %s", key, err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusForbidden, "invalid or expired url", err))
+		return
+	}
+
+	file, err := f.localStorage.Get(spanCtx, key)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("get file failed with error %s", err.Error()))
+		if errors.Is(err, storage.ErrNotFound) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "file not found", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "get file failed", err))
+		return
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Status(http.StatusOK)
+	if _, err := io.Copy(ctx.Writer, file); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("failed to write file with %s", err.Error()))
+	}
+}
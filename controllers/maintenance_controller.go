@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/services"
+	"sample-web/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type MaintenanceController interface {
+	CreateMaintenanceRequest(ctx *gin.Context)
+	GetMaintenanceRequests(ctx *gin.Context)
+	GetRentMaintenanceRequests(ctx *gin.Context)
+	GetMaintenanceRequestById(ctx *gin.Context)
+	UpdateMaintenanceStatus(ctx *gin.Context)
+	AddMaintenanceComment(ctx *gin.Context)
+	UploadMaintenancePhoto(ctx *gin.Context)
+	GetMaintenancePhoto(ctx *gin.Context)
+}
+
+type maintenanceController struct {
+	maintenanceService services.MaintenanceService
+}
+
+func NewMaintenanceController(maintenanceService services.MaintenanceService) MaintenanceController {
+	return &maintenanceController{
+		maintenanceService: maintenanceService,
+	}
+}
+
+func (m *maintenanceController) CreateMaintenanceRequest(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "MaintenanceController.CreateMaintenanceRequest")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+
+	tenantId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Tenant ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Tenant ID is empty", nil))
+		return
+	}
+
+	var maintenanceRequest dto.MaintenanceRequestRequest
+	if err := ctx.ShouldBindJSON(&maintenanceRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	request, err := m.maintenanceService.CreateMaintenanceRequest(spanCtx, tenantId.(string), rentId, maintenanceRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to create maintenance request with %s", err.Error()))
+		m.maintenanceError(ctx, err, "Failed to create maintenance request")
+		return
+	}
+
+	log.Info(spanCtx, "Maintenance request created successfully")
+	ctx.JSON(http.StatusCreated, request)
+}
+
+func (m *maintenanceController) GetMaintenanceRequests(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "MaintenanceController.GetMaintenanceRequests")
+	defer span.End()
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	userRole, exists := ctx.Get("current_role")
+	if !exists {
+		log.Error(spanCtx, "User role is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User role is empty", nil))
+		return
+	}
+
+	var maintenanceQuery dto.MaintenanceQuery
+	if err := ctx.ShouldBindQuery(&maintenanceQuery); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind query with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+
+	requests, err := m.maintenanceService.GetMaintenanceRequests(spanCtx, userId.(string), userRole.(string), maintenanceQuery.Status)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get maintenance requests with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get maintenance requests", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, requests)
+}
+
+func (m *maintenanceController) GetRentMaintenanceRequests(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "MaintenanceController.GetRentMaintenanceRequests")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	var maintenanceQuery dto.MaintenanceQuery
+	if err := ctx.ShouldBindQuery(&maintenanceQuery); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind query with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid query parameters", err))
+		return
+	}
+
+	requests, err := m.maintenanceService.GetRentMaintenanceRequests(spanCtx, userId.(string), rentId, maintenanceQuery.Status)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get maintenance requests with %s", err.Error()))
+		m.maintenanceError(ctx, err, "Failed to get maintenance requests")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, requests)
+}
+
+func (m *maintenanceController) GetMaintenanceRequestById(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "MaintenanceController.GetMaintenanceRequestById")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	requestId := ctx.Param("request_id")
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	request, err := m.maintenanceService.GetMaintenanceRequestById(spanCtx, userId.(string), rentId, requestId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get maintenance request with %s", err.Error()))
+		m.maintenanceError(ctx, err, "Failed to get maintenance request")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, request)
+}
+
+func (m *maintenanceController) UpdateMaintenanceStatus(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "MaintenanceController.UpdateMaintenanceStatus")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	requestId := ctx.Param("request_id")
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	var statusRequest dto.MaintenanceStatusRequest
+	if err := ctx.ShouldBindJSON(&statusRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	request, err := m.maintenanceService.UpdateStatus(spanCtx, landLordId.(string), rentId, requestId, statusRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to update maintenance request with %s", err.Error()))
+		m.maintenanceError(ctx, err, "Failed to update maintenance request")
+		return
+	}
+
+	log.Info(spanCtx, "Maintenance request status updated successfully")
+	ctx.JSON(http.StatusOK, request)
+}
+
+func (m *maintenanceController) AddMaintenanceComment(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "MaintenanceController.AddMaintenanceComment")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	requestId := ctx.Param("request_id")
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	var commentRequest dto.MaintenanceCommentRequest
+	if err := ctx.ShouldBindJSON(&commentRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	request, err := m.maintenanceService.AddComment(spanCtx, userId.(string), rentId, requestId, commentRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to comment on maintenance request with %s", err.Error()))
+		m.maintenanceError(ctx, err, "Failed to comment on maintenance request")
+		return
+	}
+
+	log.Info(spanCtx, "Maintenance comment added successfully")
+	ctx.JSON(http.StatusCreated, request)
+}
+
+func (m *maintenanceController) UploadMaintenancePhoto(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "MaintenanceController.UploadMaintenancePhoto")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	requestId := ctx.Param("request_id")
+
+	tenantId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Tenant ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Tenant ID is empty", nil))
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to read uploaded file with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "file is required", err))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to open uploaded file with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "invalid file", err))
+		return
+	}
+	defer file.Close()
+
+	photoResponse, err := m.maintenanceService.AddPhoto(spanCtx, tenantId.(string), rentId, requestId, fileHeader.Filename, file)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to upload maintenance photo with %s", err.Error()))
+		m.maintenanceError(ctx, err, "Failed to upload photo")
+		return
+	}
+
+	log.Info(spanCtx, "Maintenance photo uploaded successfully")
+	ctx.JSON(http.StatusCreated, photoResponse)
+}
+
+func (m *maintenanceController) GetMaintenancePhoto(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "MaintenanceController.GetMaintenancePhoto")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	requestId := ctx.Param("request_id")
+	photoId := ctx.Param("photo_id")
+
+	userId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	photoResponse, err := m.maintenanceService.GetPhoto(spanCtx, userId.(string), rentId, requestId, photoId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get maintenance photo with %s", err.Error()))
+		m.maintenanceError(ctx, err, "Failed to get photo")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, photoResponse)
+}
+
+// maintenanceError maps the errors of the maintenance service, everything that is not a missing
+// document or a rejected upload is a request the maintenance request does not allow.
+func (m *maintenanceController) maintenanceError(ctx *gin.Context, err error, message string) {
+	var tooLargeErr customerr.AttachmentTooLargeError
+	var contentTypeErr customerr.UnsupportedContentTypeError
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		ctx.Error(customerr.NewAppError(http.StatusNotFound, "Maintenance request or rent not found", err))
+	case errors.As(err, &tooLargeErr):
+		ctx.Error(customerr.NewAppError(http.StatusRequestEntityTooLarge, tooLargeErr.Error(), err))
+	case errors.As(err, &contentTypeErr):
+		ctx.Error(customerr.NewAppError(http.StatusUnsupportedMediaType, contentTypeErr.Error(), err))
+	default:
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, fmt.Sprintf("%s: %s", message, err.Error()), err))
+	}
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/services"
+	"sample-web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PropertyController interface {
+	CreateProperty(ctx *gin.Context)
+	GetAllProperties(ctx *gin.Context)
+	GetPropertyById(ctx *gin.Context)
+	UpdateProperty(ctx *gin.Context)
+	DeleteProperty(ctx *gin.Context)
+	GetPropertyOccupancy(ctx *gin.Context)
+}
+
+type propertyController struct {
+	propertyService services.PropertyService
+}
+
+func NewPropertyController(propertyService services.PropertyService) PropertyController {
+	return &propertyController{
+		propertyService: propertyService,
+	}
+}
+
+func (p *propertyController) CreateProperty(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "PropertyController.CreateProperty")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	var propertyRequest dto.PropertyRequest
+	if err := ctx.ShouldBindJSON(&propertyRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	property, err := p.propertyService.CreateProperty(spanCtx, landLordId.(string), propertyRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to create property with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to create property", err))
+		return
+	}
+
+	log.Info(spanCtx, "Property created successfully")
+	ctx.JSON(http.StatusCreated, property)
+}
+
+func (p *propertyController) GetAllProperties(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "PropertyController.GetAllProperties")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	properties, err := p.propertyService.GetAllProperties(spanCtx, landLordId.(string))
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get properties with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get properties", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, properties)
+}
+
+func (p *propertyController) GetPropertyById(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "PropertyController.GetPropertyById")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	propertyId := ctx.Param("property_id")
+
+	property, err := p.propertyService.GetPropertyById(spanCtx, landLordId.(string), propertyId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get property with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get property", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, property)
+}
+
+func (p *propertyController) UpdateProperty(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "PropertyController.UpdateProperty")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	propertyId := ctx.Param("property_id")
+
+	var propertyRequest dto.PropertyRequest
+	if err := ctx.ShouldBindJSON(&propertyRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	property, err := p.propertyService.UpdateProperty(spanCtx, landLordId.(string), propertyId, propertyRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to update property with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to update property", err))
+		return
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Property updated successfully with ID: %s", propertyId))
+	ctx.JSON(http.StatusOK, property)
+}
+
+func (p *propertyController) DeleteProperty(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "PropertyController.DeleteProperty")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	propertyId := ctx.Param("property_id")
+
+	if err := p.propertyService.DeleteProperty(spanCtx, landLordId.(string), propertyId); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to delete property with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to delete property", err))
+		return
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Property deleted successfully with ID: %s", propertyId))
+	ctx.Status(http.StatusNoContent)
+}
+
+func (p *propertyController) GetPropertyOccupancy(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "PropertyController.GetPropertyOccupancy")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	propertyId := ctx.Param("property_id")
+
+	occupancy, err := p.propertyService.GetPropertyOccupancy(spanCtx, landLordId.(string), propertyId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get property occupancy with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get property occupancy", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, occupancy)
+}
@@ -18,6 +18,9 @@ type RentController interface {
 	UpdateRent(ctx *gin.Context)
 	CloseRent(ctx *gin.Context)
 	SummariseRent(ctx *gin.Context)
+	GetRentAmendments(ctx *gin.Context)
+	AcknowledgeAmendment(ctx *gin.Context)
+	TerminateRent(ctx *gin.Context)
 }
 
 type rentController struct {
@@ -214,5 +217,146 @@ func (r *rentController) CloseRent(ctx *gin.Context) {
 
 // SummariseRent implements RentController.
 func (r *rentController) SummariseRent(ctx *gin.Context) {
-	panic("unimplemented")
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentController.SummariseRent")
+	defer span.End()
+
+	userId, exists := ctx.Get("user_id")
+
+	if !exists {
+		log.Error(spanCtx, "User Id is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	rentId := ctx.Param("rent_id")
+	if rentId == "" {
+		log.Error(spanCtx, "Rent ID not provided")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Rent ID not provided", nil))
+		return
+	}
+
+	summary, err := r.rentService.SummariseRent(spanCtx, userId.(string), rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to summarise rent with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to summarise rent", err))
+		return
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent summarised successfully with ID: %s", rentId))
+
+	ctx.JSON(http.StatusOK, summary)
+}
+
+// GetRentAmendments implements RentController.
+func (r *rentController) GetRentAmendments(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentController.GetRentAmendments")
+	defer span.End()
+
+	userId, exists := ctx.Get("user_id")
+
+	if !exists {
+		log.Error(spanCtx, "User Id is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	rentId := ctx.Param("rent_id")
+	if rentId == "" {
+		log.Error(spanCtx, "Rent ID not provided")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Rent ID not provided", nil))
+		return
+	}
+
+	amendments, err := r.rentService.GetRentAmendments(spanCtx, userId.(string), rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get rent amendments with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get rent amendments", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, amendments)
+}
+
+// AcknowledgeAmendment implements RentController.
+func (r *rentController) AcknowledgeAmendment(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentController.AcknowledgeAmendment")
+	defer span.End()
+
+	tenantId, exists := ctx.Get("user_id")
+
+	if !exists {
+		log.Error(spanCtx, "Tenant Id is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Tenant ID is empty", nil))
+		return
+	}
+
+	rentId := ctx.Param("rent_id")
+	amendmentId := ctx.Param("amendment_id")
+	if rentId == "" || amendmentId == "" {
+		log.Error(spanCtx, "Rent ID or amendment ID not provided")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Rent ID or amendment ID not provided", nil))
+		return
+	}
+
+	amendment, err := r.rentService.AcknowledgeAmendment(spanCtx, tenantId.(string), rentId, amendmentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to acknowledge rent amendment with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to acknowledge rent amendment", err))
+		return
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent amendment acknowledged successfully with ID: %s", amendmentId))
+
+	ctx.JSON(http.StatusOK, amendment)
+}
+
+// TerminateRent implements RentController.
+func (r *rentController) TerminateRent(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentController.TerminateRent")
+	defer span.End()
+
+	userId, exists := ctx.Get("user_id")
+
+	if !exists {
+		log.Error(spanCtx, "User Id is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	rentId := ctx.Param("rent_id")
+	if rentId == "" {
+		log.Error(spanCtx, "Rent ID not provided")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Rent ID not provided", nil))
+		return
+	}
+
+	var terminationRequest dto.RentTerminationRequest
+	if err := ctx.ShouldBindJSON(&terminationRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	rent, err := r.rentService.TerminateRent(spanCtx, userId.(string), rentId, terminationRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to terminate rent with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to terminate rent", err))
+		return
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Notice given successfully for rent with ID: %s", rentId))
+
+	ctx.JSON(http.StatusOK, rent)
 }
@@ -1,14 +1,17 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"sample-web/dto"
 	customerr "sample-web/errors"
+	"sample-web/pdf"
 	"sample-web/services"
 	"sample-web/utils"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type RentRecordController interface {
@@ -17,6 +20,18 @@ type RentRecordController interface {
 	GetRentRecordById(ctx *gin.Context)
 	ApproveRentRecord(ctx *gin.Context)
 	RejectRentRecord(ctx *gin.Context)
+	ResubmitRentRecord(ctx *gin.Context)
+	EditRentRecord(ctx *gin.Context)
+	WithdrawRentRecord(ctx *gin.Context)
+	CreateLandLordRentRecord(ctx *gin.Context)
+	ConfirmRentRecord(ctx *gin.Context)
+	DisputeRentRecord(ctx *gin.Context)
+	BatchReviewRentRecords(ctx *gin.Context)
+	ReverseRentRecord(ctx *gin.Context)
+	GetRentLedger(ctx *gin.Context)
+	UploadAttachment(ctx *gin.Context)
+	GetAttachment(ctx *gin.Context)
+	GetReceipt(ctx *gin.Context)
 }
 
 type rentRecorController struct {
@@ -56,6 +71,11 @@ func (r *rentRecorController) CreateRentRecord(ctx *gin.Context) {
 
 	if err != nil {
 		log.Error(spanCtx, fmt.Sprintf("create rent failed with error %s", err.Error()))
+		var duplicateErr customerr.DuplicatePaymentReferenceError
+		if errors.As(err, &duplicateErr) {
+			ctx.Error(customerr.NewAppError(http.StatusConflict, duplicateErr.Error(), err))
+			return
+		}
 		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "create rent failed", err))
 		return
 	}
@@ -89,9 +109,16 @@ func (r *rentRecorController) GetAllRentRecords(ctx *gin.Context) {
 		return
 	}
 
+	var rentRecordQuery dto.RentRecordQuery
+	if err := ctx.ShouldBindQuery(&rentRecordQuery); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("failed to bind query with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "invalid query parameters", err))
+		return
+	}
+
 	log.Info(spanCtx, fmt.Sprintf("userId: %s, userRole: %s", userId.(string), userRole.(string)))
 
-	rentRecords, err := r.rentRecordService.GetAllRentRecords(spanCtx, userId.(string), userRole.(string),rentId)
+	rentRecords, err := r.rentRecordService.GetAllRentRecords(spanCtx, userId.(string), userRole.(string),rentId, rentRecordQuery.PaymentMethod)
 
 	if err != nil {
 		log.Error(spanCtx, fmt.Sprintf("get all rent records failed with error %s", err.Error()))
@@ -156,7 +183,17 @@ func (r *rentRecorController) ApproveRentRecord(ctx *gin.Context) {
 		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Tenant ID is empty", nil))
 	}
 
-	rentRecordResponse, err := r.rentRecordService.ApproveRentRecord(spanCtx, userId.(string), rentId, rentRecordId)
+	// the body is optional, without it the submitted amount is approved
+	var approveRequest dto.ApproveRentRecordRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&approveRequest); err != nil {
+			log.Error(spanCtx, fmt.Sprintf("failed to bind request body with %s", err.Error()))
+			ctx.Error(customerr.NewAppError(http.StatusBadRequest, "invalid request body", err))
+			return
+		}
+	}
+
+	rentRecordResponse, err := r.rentRecordService.ApproveRentRecord(spanCtx, userId.(string), rentId, rentRecordId, approveRequest)
 
 	if err != nil {
 		log.Error(spanCtx, fmt.Sprintf("approve rent record failed with error %s", err.Error()))
@@ -186,7 +223,14 @@ func (r *rentRecorController) RejectRentRecord(ctx *gin.Context) {
 		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Tenant ID is empty", nil))
 	}
 
-	rentRecordResponse, err := r.rentRecordService.RejectRentRecord(spanCtx, userId.(string), rentId, rentRecordId)
+	var rejectRequest dto.RejectRentRecordRequest
+	if err := ctx.ShouldBindBodyWithJSON(&rejectRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "invalid request body", err))
+		return
+	}
+
+	rentRecordResponse, err := r.rentRecordService.RejectRentRecord(spanCtx, userId.(string), rentId, rentRecordId, rejectRequest)
 
 	if err != nil {
 		log.Error(spanCtx, fmt.Sprintf("reject rent record failed with error %s", err.Error()))
@@ -197,3 +241,473 @@ func (r *rentRecorController) RejectRentRecord(ctx *gin.Context) {
 	log.Info(spanCtx, "reject rent record successfully")
 	ctx.JSON(http.StatusOK, rentRecordResponse)
 }
+
+func (r *rentRecorController) ResubmitRentRecord(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentRecordController.ResubmitRentRecord")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	rentRecordId := ctx.Param("record_id")
+
+	tenantId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Tenant ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Tenant ID is empty", nil))
+		return
+	}
+
+	var rentRecordRequest dto.RentRecordRequest
+	if err := ctx.ShouldBindBodyWithJSON(&rentRecordRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "invalid request body", err))
+		return
+	}
+
+	rentRecordResponse, err := r.rentRecordService.ResubmitRentRecord(spanCtx, tenantId.(string), rentId, rentRecordId, rentRecordRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("resubmit rent record failed with error %s", err.Error()))
+		var duplicateErr customerr.DuplicatePaymentReferenceError
+		if errors.As(err, &duplicateErr) {
+			ctx.Error(customerr.NewAppError(http.StatusConflict, duplicateErr.Error(), err))
+			return
+		}
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "rent record not found", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	log.Info(spanCtx, "resubmit rent record successfully")
+	ctx.JSON(http.StatusCreated, rentRecordResponse)
+}
+
+func (r *rentRecorController) EditRentRecord(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentRecordController.EditRentRecord")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	rentRecordId := ctx.Param("record_id")
+
+	tenantId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Tenant ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Tenant ID is empty", nil))
+		return
+	}
+
+	var rentRecordRequest dto.RentRecordRequest
+	if err := ctx.ShouldBindBodyWithJSON(&rentRecordRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "invalid request body", err))
+		return
+	}
+
+	rentRecordResponse, err := r.rentRecordService.EditRentRecord(spanCtx, tenantId.(string), rentId, rentRecordId, rentRecordRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("edit rent record failed with error %s", err.Error()))
+		var duplicateErr customerr.DuplicatePaymentReferenceError
+		if errors.As(err, &duplicateErr) {
+			ctx.Error(customerr.NewAppError(http.StatusConflict, duplicateErr.Error(), err))
+			return
+		}
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "rent record not found", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	log.Info(spanCtx, "edit rent record successfully")
+	ctx.JSON(http.StatusOK, rentRecordResponse)
+}
+
+func (r *rentRecorController) WithdrawRentRecord(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentRecordController.WithdrawRentRecord")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	rentRecordId := ctx.Param("record_id")
+
+	tenantId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Tenant ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Tenant ID is empty", nil))
+		return
+	}
+
+	rentRecordResponse, err := r.rentRecordService.WithdrawRentRecord(spanCtx, tenantId.(string), rentId, rentRecordId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("withdraw rent record failed with error %s", err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "rent record not found", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	log.Info(spanCtx, "withdraw rent record successfully")
+	ctx.JSON(http.StatusOK, rentRecordResponse)
+}
+
+func (r *rentRecorController) CreateLandLordRentRecord(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentRecordController.CreateLandLordRentRecord")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	var rentRecordRequest dto.LandLordRentRecordRequest
+	if err := ctx.ShouldBindBodyWithJSON(&rentRecordRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "invalid request body", err))
+		return
+	}
+
+	rentRecordResponse, err := r.rentRecordService.CreateLandLordRentRecord(spanCtx, landLordId.(string), rentId, rentRecordRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("record payment failed with error %s", err.Error()))
+		var duplicateErr customerr.DuplicatePaymentReferenceError
+		if errors.As(err, &duplicateErr) {
+			ctx.Error(customerr.NewAppError(http.StatusConflict, duplicateErr.Error(), err))
+			return
+		}
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "rent not found", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	log.Info(spanCtx, "record payment successfully")
+	ctx.JSON(http.StatusCreated, rentRecordResponse)
+}
+
+func (r *rentRecorController) ConfirmRentRecord(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentRecordController.ConfirmRentRecord")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	rentRecordId := ctx.Param("record_id")
+
+	tenantId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Tenant ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Tenant ID is empty", nil))
+		return
+	}
+
+	rentRecordResponse, err := r.rentRecordService.ConfirmRentRecord(spanCtx, tenantId.(string), rentId, rentRecordId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("confirm rent record failed with error %s", err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "rent record not found", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	log.Info(spanCtx, "confirm rent record successfully")
+	ctx.JSON(http.StatusOK, rentRecordResponse)
+}
+
+func (r *rentRecorController) DisputeRentRecord(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentRecordController.DisputeRentRecord")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	rentRecordId := ctx.Param("record_id")
+
+	tenantId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Tenant ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Tenant ID is empty", nil))
+		return
+	}
+
+	var disputeRequest dto.DisputeRentRecordRequest
+	if err := ctx.ShouldBindBodyWithJSON(&disputeRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "invalid request body", err))
+		return
+	}
+
+	rentRecordResponse, err := r.rentRecordService.DisputeRentRecord(spanCtx, tenantId.(string), rentId, rentRecordId, disputeRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("dispute rent record failed with error %s", err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "rent record not found", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	log.Info(spanCtx, "dispute rent record successfully")
+	ctx.JSON(http.StatusOK, rentRecordResponse)
+}
+
+func (r *rentRecorController) BatchReviewRentRecords(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentRecordController.BatchReviewRentRecords")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	var batchRequest dto.BatchRentRecordRequest
+	if err := ctx.ShouldBindBodyWithJSON(&batchRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "invalid request body", err))
+		return
+	}
+
+	batchResponse, err := r.rentRecordService.BatchReviewRentRecords(spanCtx, landLordId.(string), batchRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("batch review of rent records failed with error %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	log.Info(spanCtx, "batch review of rent records successfully")
+	ctx.JSON(http.StatusOK, batchResponse)
+}
+
+func (r *rentRecorController) ReverseRentRecord(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentRecordController.ReverseRentRecord")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+	rentRecordId := ctx.Param("record_id")
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	var reverseRequest dto.ReverseRentRecordRequest
+	if err := ctx.ShouldBindBodyWithJSON(&reverseRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "invalid request body", err))
+		return
+	}
+
+	reversalResponse, err := r.rentRecordService.ReverseRentRecord(spanCtx, landLordId.(string), rentId, rentRecordId, reverseRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("reverse rent record failed with error %s", err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "rent record not found", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, err.Error(), err))
+		return
+	}
+
+	log.Info(spanCtx, "reverse rent record successfully")
+	ctx.JSON(http.StatusOK, reversalResponse)
+}
+
+func (r *rentRecorController) GetRentLedger(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentRecordController.GetRentLedger")
+	defer span.End()
+
+	rentId := ctx.Param("rent_id")
+
+	userId, exists := ctx.Get("user_id")
+
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	ledger, err := r.rentRecordService.GetRentLedger(spanCtx, userId.(string), rentId)
+
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("get rent ledger failed with error %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "get rent ledger failed", err))
+		return
+	}
+
+	log.Info(spanCtx, "get rent ledger successfully")
+	ctx.JSON(http.StatusOK, ledger)
+}
+
+func (r *rentRecorController) UploadAttachment(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentRecordController.UploadAttachment")
+	defer span.End()
+
+	rentRecordId := ctx.Param("record_id")
+
+	rentId := ctx.Param("rent_id")
+
+	userId, exists := ctx.Get("user_id")
+
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("failed to read uploaded file with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "file is required", err))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("failed to open uploaded file with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "invalid file", err))
+		return
+	}
+	defer file.Close()
+
+	attachmentResponse, err := r.rentRecordService.AddAttachment(spanCtx, userId.(string), rentId, rentRecordId, fileHeader.Filename, file)
+
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("upload attachment failed with error %s", err.Error()))
+		var tooLargeErr customerr.AttachmentTooLargeError
+		var contentTypeErr customerr.UnsupportedContentTypeError
+		switch {
+		case errors.As(err, &tooLargeErr):
+			ctx.Error(customerr.NewAppError(http.StatusRequestEntityTooLarge, tooLargeErr.Error(), err))
+		case errors.As(err, &contentTypeErr):
+			ctx.Error(customerr.NewAppError(http.StatusUnsupportedMediaType, contentTypeErr.Error(), err))
+		case errors.Is(err, mongo.ErrNoDocuments):
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "rent record not found", err))
+		default:
+			ctx.Error(customerr.NewAppError(http.StatusBadRequest, "upload attachment failed", err))
+		}
+		return
+	}
+
+	log.Info(spanCtx, "upload attachment successfully")
+	ctx.JSON(http.StatusCreated, attachmentResponse)
+}
+
+func (r *rentRecorController) GetAttachment(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentRecordController.GetAttachment")
+	defer span.End()
+
+	rentRecordId := ctx.Param("record_id")
+
+	rentId := ctx.Param("rent_id")
+
+	attachmentId := ctx.Param("attachment_id")
+
+	userId, exists := ctx.Get("user_id")
+
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	attachmentResponse, err := r.rentRecordService.GetAttachment(spanCtx, userId.(string), rentId, rentRecordId, attachmentId)
+
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("get attachment failed with error %s", err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "attachment not found", err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "get attachment failed", err))
+		return
+	}
+
+	log.Info(spanCtx, "get attachment successfully")
+	ctx.JSON(http.StatusOK, attachmentResponse)
+}
+
+func (r *rentRecorController) GetReceipt(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "RentRecordController.GetReceipt")
+	defer span.End()
+
+	rentRecordId := ctx.Param("record_id")
+
+	rentId := ctx.Param("rent_id")
+
+	userId, exists := ctx.Get("user_id")
+
+	if !exists {
+		log.Error(spanCtx, "User ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "User ID is empty", nil))
+		return
+	}
+
+	receipt, body, err := r.rentRecordService.GetReceipt(spanCtx, userId.(string), rentId, rentRecordId)
+
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("get receipt failed with error %s", err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			ctx.Error(customerr.NewAppError(http.StatusNotFound, "rent record not found", err))
+			return
+		}
+		var textErr pdf.UnsupportedTextError
+		if errors.As(err, &textErr) {
+			ctx.Error(customerr.NewAppError(http.StatusUnprocessableEntity, textErr.Error(), err))
+			return
+		}
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "get receipt failed", err))
+		return
+	}
+	defer body.Close()
+
+	log.Info(spanCtx, "get receipt successfully")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", receipt.Number+".pdf"))
+	ctx.DataFromReader(http.StatusOK, -1, "application/pdf", body, nil)
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/services"
+	"sample-web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UnitController interface {
+	CreateUnit(ctx *gin.Context)
+	GetUnitsByProperty(ctx *gin.Context)
+	GetUnitById(ctx *gin.Context)
+	UpdateUnit(ctx *gin.Context)
+	DeleteUnit(ctx *gin.Context)
+}
+
+type unitController struct {
+	unitService services.UnitService
+}
+
+func NewUnitController(unitService services.UnitService) UnitController {
+	return &unitController{
+		unitService: unitService,
+	}
+}
+
+func (u *unitController) CreateUnit(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "UnitController.CreateUnit")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	propertyId := ctx.Param("property_id")
+
+	var unitRequest dto.UnitRequest
+	if err := ctx.ShouldBindJSON(&unitRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	unit, err := u.unitService.CreateUnit(spanCtx, landLordId.(string), propertyId, unitRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to create unit with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to create unit", err))
+		return
+	}
+
+	log.Info(spanCtx, "Unit created successfully")
+	ctx.JSON(http.StatusCreated, unit)
+}
+
+func (u *unitController) GetUnitsByProperty(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "UnitController.GetUnitsByProperty")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	propertyId := ctx.Param("property_id")
+
+	units, err := u.unitService.GetUnitsByProperty(spanCtx, landLordId.(string), propertyId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get units with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get units", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, units)
+}
+
+func (u *unitController) GetUnitById(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "UnitController.GetUnitById")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	propertyId := ctx.Param("property_id")
+	unitId := ctx.Param("unit_id")
+
+	unit, err := u.unitService.GetUnitById(spanCtx, landLordId.(string), propertyId, unitId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get unit with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to get unit", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, unit)
+}
+
+func (u *unitController) UpdateUnit(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "UnitController.UpdateUnit")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	propertyId := ctx.Param("property_id")
+	unitId := ctx.Param("unit_id")
+
+	var unitRequest dto.UnitRequest
+	if err := ctx.ShouldBindJSON(&unitRequest); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to bind request body with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Invalid request body", err))
+		return
+	}
+
+	unit, err := u.unitService.UpdateUnit(spanCtx, landLordId.(string), propertyId, unitId, unitRequest)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to update unit with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to update unit", err))
+		return
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Unit updated successfully with ID: %s", unitId))
+	ctx.JSON(http.StatusOK, unit)
+}
+
+func (u *unitController) DeleteUnit(ctx *gin.Context) {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx.Request.Context(), "UnitController.DeleteUnit")
+	defer span.End()
+
+	landLordId, exists := ctx.Get("user_id")
+	if !exists {
+		log.Error(spanCtx, "Landlord ID is empty")
+		ctx.Error(customerr.NewAppError(http.StatusBadRequest, "Landlord ID is empty", nil))
+		return
+	}
+
+	propertyId := ctx.Param("property_id")
+	unitId := ctx.Param("unit_id")
+
+	if err := u.unitService.DeleteUnit(spanCtx, landLordId.(string), propertyId, unitId); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to delete unit with %s", err.Error()))
+		ctx.Error(customerr.NewAppError(http.StatusInternalServerError, "Failed to delete unit", err))
+		return
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Unit deleted successfully with ID: %s", unitId))
+	ctx.Status(http.StatusNoContent)
+}
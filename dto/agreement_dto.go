@@ -0,0 +1,45 @@
+package dto
+
+import "sample-web/models"
+
+// AgreementTemplateRequest is a new version of the agreement template of a landlord. The body is a
+// Go text/template executed with the rent, the parties and the schedule, lines starting with "# "
+// are rendered as headings.
+type AgreementTemplateRequest struct {
+	Body string `json:"body" binding:"required,max=50000"`
+}
+
+type AgreementTemplateResponse struct {
+	Templates []models.AgreementTemplate `json:"templates"`
+}
+
+// AgreementRequest renders an agreement with a version of the template, the latest version is
+// used when it is not set.
+type AgreementRequest struct {
+	TemplateVersion int `json:"template_version" binding:"omitempty,min=1"`
+}
+
+type AgreementResponse struct {
+	Agreements []models.Agreement `json:"agreements"`
+}
+
+type AgreementSignatureRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// AgreementVerificationResponse confirms a PDF is an agreement generated by the service, it leaves
+// out the phone numbers and IP addresses of the signers as anyone holding the file can verify it.
+type AgreementVerificationResponse struct {
+	AgreementId string                  `json:"agreement_id"`
+	RentId      string                  `json:"rent_id"`
+	Hash        string                  `json:"hash"`
+	Status      string                  `json:"status"`
+	SignedAt    string                  `json:"signed_at,omitempty"`
+	Signatures  []VerifiedSignatureItem `json:"signatures"`
+}
+
+type VerifiedSignatureItem struct {
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	SignedAt string `json:"signed_at"`
+}
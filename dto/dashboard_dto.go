@@ -0,0 +1,137 @@
+package dto
+
+import "sample-web/models"
+
+type MonthlyIncomeEntry struct {
+	Month    string       `json:"month"`
+	Amount   models.Money `json:"amount"`
+	Payments int          `json:"payments"`
+}
+
+type PendingApprovalEntry struct {
+	RecordId      string       `json:"record_id"`
+	RentId        string       `json:"rent_id"`
+	RentTitle     string       `json:"rent_title"`
+	TenantId      string       `json:"tenant_id"`
+	TenantName    string       `json:"tenant_name"`
+	Amount        models.Money `json:"amount"`
+	PaymentMethod string       `json:"payment_method,omitempty"`
+	Reference     string       `json:"reference,omitempty"`
+	PaidAt        string       `json:"paid_at"`
+	SubmittedAt   string       `json:"submitted_at"`
+	// Converted is the amount in the report currency when one is requested.
+	Converted *ConvertedAmount `json:"converted,omitempty"`
+}
+
+// PendingApprovals totals the records awaiting approval, Amount holds one total per currency. Records
+// lists the oldest of them only, Count and Amount cover all.
+type PendingApprovals struct {
+	Count   int                    `json:"count"`
+	Amount  []models.Money         `json:"amount"`
+	Records []PendingApprovalEntry `json:"records"`
+}
+
+type Occupancy struct {
+	Units    int     `json:"units"`
+	Let      int     `json:"let"`
+	Vacant   int     `json:"vacant"`
+	Upcoming int     `json:"upcoming"`
+	Rate     float64 `json:"rate"`
+}
+
+type ExpiringRentEntry struct {
+	RentId   string `json:"rent_id"`
+	Title    string `json:"title"`
+	EndDate  string `json:"end_date"`
+	DaysLeft int    `json:"days_left"`
+}
+
+type ExpiringRents struct {
+	Within30Days []ExpiringRentEntry `json:"within_30_days"`
+	Within60Days []ExpiringRentEntry `json:"within_60_days"`
+	Within90Days []ExpiringRentEntry `json:"within_90_days"`
+}
+
+type LandLordDashboardResponse struct {
+	From             string               `json:"from"`
+	To               string               `json:"to"`
+	IncomeByMonth    []MonthlyIncomeEntry `json:"income_by_month"`
+	TotalIncome      []models.Money       `json:"total_income"`
+	PendingApprovals PendingApprovals     `json:"pending_approvals"`
+	TotalArrears     []models.Money       `json:"total_arrears"`
+	Occupancy        Occupancy            `json:"occupancy"`
+	ExpiringRents    ExpiringRents        `json:"expiring_rents"`
+	// Report holds the figures converted to the report currency when one is requested.
+	Report *LandLordDashboardReport `json:"report,omitempty"`
+}
+
+type ConvertedMonthlyIncome struct {
+	Month    string          `json:"month"`
+	Amount   ConvertedAmount `json:"amount"`
+	Payments int             `json:"payments"`
+}
+
+// LandLordDashboardReport converts income and pending approvals with the rate in effect on the
+// payment date, arrears are converted with the rate in effect today.
+type LandLordDashboardReport struct {
+	Currency         string                   `json:"currency"`
+	IncomeByMonth    []ConvertedMonthlyIncome `json:"income_by_month"`
+	TotalIncome      ConvertedAmount          `json:"total_income"`
+	PendingApprovals ConvertedAmount          `json:"pending_approvals"`
+	TotalArrears     ConvertedAmount          `json:"total_arrears"`
+}
+
+type DashboardQuery struct {
+	From           string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To             string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	ReportCurrency string `form:"report_currency" binding:"omitempty,iso4217"`
+}
+
+type TenantDashboardQuery struct {
+	ReportCurrency string `form:"report_currency" binding:"omitempty,iso4217"`
+}
+
+type TenantRentEntry struct {
+	RentId          string       `json:"rent_id"`
+	Title           string       `json:"title"`
+	LandLordName    string       `json:"landlord_name"`
+	Schedule        string       `json:"schedule"`
+	ShareAmount     models.Money `json:"share_amount"`
+	NextDueDate     string       `json:"next_due_date,omitempty"`
+	NextDueAmount   models.Money `json:"next_due_amount"`
+	OverdueAmount   models.Money `json:"overdue_amount"`
+	PendingApproval models.Money `json:"pending_approval"`
+	EndDate         string       `json:"end_date"`
+}
+
+type TenantPaymentEntry struct {
+	RecordId      string       `json:"record_id"`
+	RentId        string       `json:"rent_id"`
+	RentTitle     string       `json:"rent_title"`
+	Amount        models.Money `json:"amount"`
+	PaymentMethod string       `json:"payment_method,omitempty"`
+	PaidAt        string       `json:"paid_at"`
+	Status        string       `json:"status"`
+	DueDate       string       `json:"due_date,omitempty"`
+	SubmittedAt   string       `json:"submitted_at"`
+	ApprovedAt    string       `json:"approved_at,omitempty"`
+	// Converted is the amount in the report currency when one is requested.
+	Converted *ConvertedAmount `json:"converted,omitempty"`
+}
+
+type TenantDashboardResponse struct {
+	Rents            []TenantRentEntry    `json:"rents"`
+	TotalOverdue     []models.Money       `json:"total_overdue"`
+	AwaitingApproval []TenantPaymentEntry `json:"awaiting_approval"`
+	PaymentHistory   []TenantPaymentEntry `json:"payment_history"`
+	// Report holds the figures converted to the report currency when one is requested.
+	Report *TenantDashboardReport `json:"report,omitempty"`
+}
+
+// TenantDashboardReport converts payments with the rate in effect on the payment date, overdue
+// amounts are converted with the rate in effect today.
+type TenantDashboardReport struct {
+	Currency     string          `json:"currency"`
+	TotalOverdue ConvertedAmount `json:"total_overdue"`
+	TotalPaid    ConvertedAmount `json:"total_paid"`
+}
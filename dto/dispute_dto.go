@@ -0,0 +1,26 @@
+package dto
+
+import "sample-web/models"
+
+type DisputeRequest struct {
+	Reason string `json:"reason" binding:"required,max=1000"`
+}
+
+type DisputeMessageRequest struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
+
+// ResolveDisputeRequest resolves a dispute, reverse approves a rejected record or rejects an approved
+// one and keep leaves the record as it is.
+type ResolveDisputeRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=reverse keep"`
+	Note     string `json:"note" binding:"required,max=1000"`
+}
+
+type DisputeQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=open under_review resolved"`
+}
+
+type DisputeResponse struct {
+	Disputes []models.Dispute `json:"disputes"`
+}
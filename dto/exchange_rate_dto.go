@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"encoding/json"
+	"sample-web/models"
+)
+
+type ExchangeRateRequest struct {
+	BaseCurrency  string      `json:"base_currency" binding:"required,iso4217"`
+	QuoteCurrency string      `json:"quote_currency" binding:"required,iso4217,nefield=BaseCurrency"`
+	Rate          json.Number `json:"rate" binding:"required"`
+	EffectiveFrom string      `json:"effective_from" binding:"required,datetime=2006-01-02"`
+}
+
+type ExchangeRateQuery struct {
+	Currency string `form:"currency" binding:"omitempty,iso4217"`
+}
+
+type ExchangeRateResponse struct {
+	Rates []models.ExchangeRate `json:"rates"`
+}
+
+// AppliedRate is an exchange rate used for a conversion, Inverted is set when the rate was stored
+// for the opposite direction and its inverse was used.
+type AppliedRate struct {
+	RateId        string `json:"rate_id"`
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+	EffectiveFrom string `json:"effective_from"`
+	Inverted      bool   `json:"inverted,omitempty"`
+}
+
+// ConvertedAmount is an amount in the report currency with the rates used to convert it, Rates is
+// empty when every amount was in the report currency already.
+type ConvertedAmount struct {
+	Amount models.Money  `json:"amount"`
+	Rates  []AppliedRate `json:"rates"`
+}
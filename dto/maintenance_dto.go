@@ -0,0 +1,27 @@
+package dto
+
+import "sample-web/models"
+
+type MaintenanceRequestRequest struct {
+	Category    string `json:"category" binding:"required,oneof=plumbing electrical appliance structural pest_control other"`
+	Priority    string `json:"priority" binding:"required,oneof=low medium high urgent"`
+	Description string `json:"description" binding:"required,max=2000"`
+}
+
+// MaintenanceStatusRequest moves a request forward, Note is shown to the tenant with the change.
+type MaintenanceStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=acknowledged in_progress resolved"`
+	Note   string `json:"note" binding:"max=1000"`
+}
+
+type MaintenanceCommentRequest struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
+
+type MaintenanceQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=open acknowledged in_progress resolved"`
+}
+
+type MaintenanceResponse struct {
+	Requests []models.MaintenanceRequest `json:"requests"`
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "sample-web/models"
+
+type AddressRequest struct {
+	Line1      string `json:"line1" binding:"required"`
+	Line2      string `json:"line2"`
+	City       string `json:"city" binding:"required"`
+	State      string `json:"state"`
+	PostalCode string `json:"postal_code" binding:"required"`
+	Country    string `json:"country" binding:"required,iso3166_1_alpha2"`
+}
+
+type PropertyRequest struct {
+	Name    string         `json:"name" binding:"required"`
+	Type    string         `json:"type" binding:"required,oneof=apartment_building house commercial other"`
+	Address AddressRequest `json:"address" binding:"required"`
+}
+
+type PropertyResponse struct {
+	Properties []models.Property `json:"properties"`
+}
+
+type UnitRequest struct {
+	UnitNumber string `json:"unit_number" binding:"required"`
+	Type       string `json:"type" binding:"required,oneof=apartment room shop office other"`
+}
+
+type UnitResponse struct {
+	Units []models.Unit `json:"units"`
+}
+
+type UnitOccupancyEntry struct {
+	UnitId     string `json:"unit_id"`
+	UnitNumber string `json:"unit_number"`
+	Type       string `json:"type"`
+	Occupancy  string `json:"occupancy"`
+	RentId     string `json:"rent_id,omitempty"`
+	RentTitle  string `json:"rent_title,omitempty"`
+	StartDate  string `json:"start_date,omitempty"`
+	EndDate    string `json:"end_date,omitempty"`
+}
+
+type PropertyOccupancyResponse struct {
+	PropertyId string               `json:"property_id"`
+	Name       string               `json:"name"`
+	Let        int                  `json:"let"`
+	Vacant     int                  `json:"vacant"`
+	Upcoming   int                  `json:"upcoming"`
+	Units      []UnitOccupancyEntry `json:"units"`
+}
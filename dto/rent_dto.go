@@ -1,24 +1,99 @@
 package dto
 
-import "sample-web/models"
+import (
+	"encoding/json"
+	"sample-web/models"
+)
 
 type RentResponse struct {
 	Rents []models.Rent `json:"rents"`
 }
 
+type RentTenantRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,e164"`
+	ShareType   string `json:"share_type" binding:"required,oneof=amount percentage"`
+	// ShareValue is an amount in the rent currency or a percentage with at most two decimal places.
+	ShareValue json.Number `json:"share_value" binding:"required"`
+}
+
+type ScheduleIntervalRequest struct {
+	Every int    `json:"every" binding:"required,min=1,max=366"`
+	Unit  string `json:"unit" binding:"required,oneof=days months"`
+}
+
 type RentRequest struct {
-	TenantPhoneNumber string  `json:"tenant_phone_number" binding:"required"`
-	Title             string  `json:"title" binding:"required"`
-	Amount            float64 `json:"amount" binding:"required"`
-	Schedule          string  `json:"schedule" binding:"required,oneof=weekly monthly querterly"`
-	Status            string  `json:"status"`
-	StartDate         string  `json:"start_date" binding:"required"`
-	EndDate           string  `json:"end_date" binding:"required"`
+	// TenantPhoneNumber creates a rent with a single tenant paying the full amount,
+	// Tenants is used for flat-shares with several co-tenants.
+	TenantPhoneNumber string              `json:"tenant_phone_number" binding:"required_without=Tenants,omitempty,e164"`
+	Tenants           []RentTenantRequest `json:"tenants" binding:"required_without=TenantPhoneNumber,omitempty,dive"`
+	UnitId            string              `json:"unit_id" binding:"omitempty,mongodb"`
+	Title             string              `json:"title" binding:"required"`
+	Amount            json.Number         `json:"amount" binding:"required"`
+	Currency          string              `json:"currency" binding:"required,iso4217"`
+	Schedule          string              `json:"schedule" binding:"required,oneof=weekly monthly quarterly half_yearly yearly custom"`
+	// Interval is required for custom schedules, AnchorDay is the day of the month installments
+	// of month based schedules are due on.
+	Interval  *ScheduleIntervalRequest `json:"interval" binding:"required_if=Schedule custom,omitempty"`
+	AnchorDay int                      `json:"anchor_day" binding:"omitempty,min=1,max=31"`
+	Status    string                   `json:"status"`
+	StartDate string                   `json:"start_date" binding:"required"`
+	EndDate   string                   `json:"end_date" binding:"required"`
 }
 
 type RentUpdateRequest struct {
-	Title    string  `json:"title" binding:"required"`
-	Amount   float64 `json:"amount" binding:"required"`
-	Schedule string  `json:"schedule" binding:"required,oneof=weekly monthly querterly"`
-	EndDate  string  `json:"end_date" binding:"required"`
+	Title     string                   `json:"title" binding:"required"`
+	Amount    json.Number              `json:"amount" binding:"required"`
+	Schedule  string                   `json:"schedule" binding:"required,oneof=weekly monthly quarterly half_yearly yearly custom"`
+	Interval  *ScheduleIntervalRequest `json:"interval" binding:"required_if=Schedule custom,omitempty"`
+	AnchorDay int                      `json:"anchor_day" binding:"omitempty,min=1,max=31"`
+	EndDate   string                   `json:"end_date" binding:"required"`
+	Reason    string                   `json:"reason" binding:"omitempty,max=500"`
+}
+
+type RentTerminationRequest struct {
+	// MoveOutDate defaults to the end of the notice period.
+	MoveOutDate string `json:"move_out_date" binding:"omitempty,datetime=2006-01-02"`
+	Reason      string `json:"reason" binding:"omitempty,max=500"`
+}
+
+type RentAmendmentResponse struct {
+	Amendments []models.RentAmendment `json:"amendments"`
+}
+
+type TenantLedgerEntry struct {
+	TenantId    string       `json:"tenant_id,omitempty"`
+	Name        string       `json:"name"`
+	PhoneNumber string       `json:"phone_number"`
+	Invited     bool         `json:"invited"`
+	ShareType   string       `json:"share_type"`
+	ShareValue  string       `json:"share_value"`
+	ShareAmount models.Money `json:"share_amount"`
+	Paid        models.Money `json:"paid"`
+	Pending     models.Money `json:"pending"`
+	Reversed    models.Money `json:"reversed"`
+}
+
+type RentLedgerResponse struct {
+	RentId   string              `json:"rent_id"`
+	Amount   models.Money        `json:"amount"`
+	Schedule string              `json:"schedule"`
+	Paid     models.Money        `json:"paid"`
+	Pending  models.Money        `json:"pending"`
+	Reversed models.Money        `json:"reversed"`
+	Tenants  []TenantLedgerEntry `json:"tenants"`
+}
+
+type RentSummaryResponse struct {
+	RentId          string       `json:"rent_id"`
+	InstallmentsDue int          `json:"installments_due"`
+	TotalExpected   models.Money `json:"total_expected"`
+	TotalApproved   models.Money `json:"total_approved"`
+	Pending         models.Money `json:"pending"`
+	Rejected        models.Money `json:"rejected"`
+	DisputedRecords int          `json:"disputed_records"`
+	Outstanding     models.Money `json:"outstanding"`
+	LastPaymentDate string       `json:"last_payment_date,omitempty"`
+	OnTimePayments  int          `json:"on_time_payments"`
+	LatePayments    int          `json:"late_payments"`
+	DaysUntilEnd    int          `json:"days_until_end"`
 }
@@ -1,14 +1,115 @@
 package dto
 
+import (
+	"encoding/json"
+	"sample-web/models"
+)
+
 type RentRecordRequest struct {
-	Amount float64 `json:"amount" binding:"required"`
+	Amount json.Number `json:"amount" binding:"required"`
+	// Currency defaults to the currency of the rent and has to match it when set.
+	Currency      string `json:"currency" binding:"omitempty,iso4217"`
+	PaymentMethod string `json:"payment_method" binding:"required,oneof=cash bank_transfer upi cheque card"`
+	// Reference is the transaction reference on the bank statement, only cash payments can omit it.
+	Reference string `json:"reference" binding:"required_unless=PaymentMethod cash,omitempty,max=100"`
+	// PaidAt is the date the tenant paid on, it defaults to today.
+	PaidAt string `json:"paid_at" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// LandLordRentRecordRequest is a payment the landlord collected on behalf of a co-tenant. TenantId
+// can be omitted when the rent has a single registered co-tenant.
+type LandLordRentRecordRequest struct {
+	RentRecordRequest
+	TenantId string `json:"tenant_id" binding:"omitempty,mongodb"`
+}
+
+// ApproveRentRecordRequest is optional, ApprovedAmount is what actually arrived when it differs from
+// the submitted amount and Note explains the difference to the tenant. It can be more than the
+// submitted amount when the tenant paid more than they recorded.
+type ApproveRentRecordRequest struct {
+	ApprovedAmount json.Number `json:"approved_amount" binding:"omitempty"`
+	Note           string      `json:"note" binding:"required_with=ApprovedAmount,max=500"`
+}
+
+type ReverseRentRecordRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type DisputeRentRecordRequest struct {
+	Comment string `json:"comment" binding:"required,max=500"`
+}
+
+type RejectRentRecordRequest struct {
+	Code    string `json:"code" binding:"required,oneof=amount_mismatch payment_not_received invalid_reference duplicate other"`
+	Message string `json:"message" binding:"required,max=500"`
+}
+
+// BatchRentRecordRequest approves or rejects records of any of the landlord's rents, Reason is
+// required to reject them.
+type BatchRentRecordRequest struct {
+	Action    string                   `json:"action" binding:"required,oneof=approve reject"`
+	RecordIds []string                 `json:"record_ids" binding:"required,min=1,max=100,unique,dive,mongodb"`
+	Reason    *RejectRentRecordRequest `json:"reason" binding:"required_if=Action reject"`
+}
+
+type RentRecordQuery struct {
+	PaymentMethod string `form:"payment_method" binding:"omitempty,oneof=cash bank_transfer upi cheque card"`
 }
 
 type RentRecordResponse struct {
-	Id          string  `json:"id"`
-	RentId      string  `json:"rent_id"`
-	Amount      float64 `json:"amount"`
-	SubmittedAt string  `json:"submitted_at"`
-	ApprovedAt  string  `json:"approved_at"`
-	Status      string  `json:"status"`
+	Id             string                      `json:"id"`
+	RentId         string                      `json:"rent_id"`
+	Amount         models.Money                `json:"amount"`
+	ApprovedAmount *models.Money               `json:"approved_amount,omitempty"`
+	ApprovalNote   string                      `json:"approval_note,omitempty"`
+	PaymentMethod  string                      `json:"payment_method,omitempty"`
+	Reference      string                      `json:"reference,omitempty"`
+	PaidAt         string                      `json:"paid_at"`
+	SubmittedAt    string                      `json:"submitted_at"`
+	ApprovedAt     string                      `json:"approved_at"`
+	Status         string                      `json:"status"`
+	Attachments    []models.Attachment         `json:"attachments,omitempty"`
+	ReceiptNumber  string                      `json:"receipt_number,omitempty"`
+	Rejection      *models.RecordRejection     `json:"rejection,omitempty"`
+	ResubmissionOf string                      `json:"resubmission_of,omitempty"`
+	ResubmittedBy  string                      `json:"resubmitted_by,omitempty"`
+	Edits          []models.RentRecordEdit     `json:"edits,omitempty"`
+	WithdrawnAt    string                      `json:"withdrawn_at,omitempty"`
+	RecordedBy     string                      `json:"recorded_by,omitempty"`
+	ConfirmBy      string                      `json:"confirm_by,omitempty"`
+	Confirmation   *models.RecordConfirmation  `json:"confirmation,omitempty"`
+	Reversal       *models.RecordReversal      `json:"reversal,omitempty"`
+	Reverses       string                      `json:"reverses,omitempty"`
+	StatusHistory  []models.RecordStatusChange `json:"status_history,omitempty"`
+	Dispute        *models.RecordDispute       `json:"dispute,omitempty"`
+	// Disputed is true while the record has a dispute that is not resolved.
+	Disputed bool `json:"disputed"`
+	// History holds the earlier records of a resubmission chain, oldest first.
+	History []RentRecordResponse `json:"history,omitempty"`
+}
+
+type AttachmentResponse struct {
+	Attachment models.Attachment `json:"attachment"`
+	URL        string            `json:"url"`
+	ExpiresAt  string            `json:"expires_at"`
+}
+
+// BatchRentRecordResponse has a result for every record of the request, in the same order.
+type BatchRentRecordResponse struct {
+	Results   []BatchRentRecordResult `json:"results"`
+	Succeeded int                     `json:"succeeded"`
+	Failed    int                     `json:"failed"`
+}
+
+type BatchRentRecordResult struct {
+	RecordId string              `json:"record_id"`
+	Success  bool                `json:"success"`
+	Record   *RentRecordResponse `json:"record,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// RentRecordReversalResponse is the reversed record and the entry that compensates it.
+type RentRecordReversalResponse struct {
+	Record            RentRecordResponse `json:"record"`
+	CompensatingEntry RentRecordResponse `json:"compensating_entry"`
 }
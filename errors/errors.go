@@ -1,5 +1,7 @@
 package errors
 
+import "fmt"
+
 type AppError struct {
 	Code    int
 	Message string
@@ -37,3 +39,62 @@ type MissingConfigError struct {
 func (m MissingConfigError) Error() string {
 	return m.Message
 }
+
+type RoleNotHeldError struct {
+	Role string
+}
+
+func (r RoleNotHeldError) Error() string {
+	return "user does not hold the " + r.Role + " role"
+}
+
+type MissingExchangeRateError struct {
+	From string
+	To   string
+	At   string
+}
+
+func (m MissingExchangeRateError) Error() string {
+	return "no exchange rate from " + m.From + " to " + m.To + " in effect on " + m.At
+}
+
+type DuplicatePaymentReferenceError struct {
+	Reference string
+	RecordId  string
+}
+
+func (d DuplicatePaymentReferenceError) Error() string {
+	return "payment reference " + d.Reference + " is already used by rent record " + d.RecordId
+}
+
+type AttachmentTooLargeError struct {
+	MaxBytes int64
+}
+
+func (a AttachmentTooLargeError) Error() string {
+	return fmt.Sprintf("attachment is larger than %d bytes", a.MaxBytes)
+}
+
+type UnsupportedContentTypeError struct {
+	ContentType string
+}
+
+func (u UnsupportedContentTypeError) Error() string {
+	return "content type " + u.ContentType + " is not supported"
+}
+
+type InvalidOTPError struct {
+	Reason string
+}
+
+func (i InvalidOTPError) Error() string {
+	return "invalid OTP: " + i.Reason
+}
+
+type AgreementAlreadySignedError struct {
+	AgreementId string
+}
+
+func (a AgreementAlreadySignedError) Error() string {
+	return "agreement " + a.AgreementId + " is already signed by the user"
+}
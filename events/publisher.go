@@ -0,0 +1,68 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sample-web/utils"
+	"sync"
+	"time"
+)
+
+// Event is a domain event, Data holds the payload type that belongs to the event name.
+type Event struct {
+	Name       string
+	OccurredAt time.Time
+	Data       interface{}
+}
+
+type Handler func(ctx context.Context, event Event)
+
+// Publisher dispatches domain events to the handlers subscribed to their name within the process.
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+	Subscribe(name string, handler Handler)
+}
+
+type inProcessPublisher struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewInProcessPublisher() Publisher {
+	return &inProcessPublisher{
+		handlers: make(map[string][]Handler),
+	}
+}
+
+// Publish calls the handlers one after the other, a handler that panics does not stop the others.
+func (p *inProcessPublisher) Publish(ctx context.Context, event Event) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "Publisher.Publish")
+	defer span.End()
+
+	p.mu.RLock()
+	handlers := p.handlers[event.Name]
+	p.mu.RUnlock()
+
+	log.Info(spanCtx, fmt.Sprintf("Publishing %s to %d handlers", event.Name, len(handlers)))
+
+	for _, handler := range handlers {
+		p.dispatch(spanCtx, handler, event)
+	}
+}
+
+func (p *inProcessPublisher) Subscribe(name string, handler Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[name] = append(p.handlers[name], handler)
+}
+
+func (p *inProcessPublisher) dispatch(ctx context.Context, handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.GetLogger().Error(ctx, fmt.Sprintf("Handler for %s panicked with %v", event.Name, r))
+		}
+	}()
+	handler(ctx, event)
+}
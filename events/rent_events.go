@@ -0,0 +1,36 @@
+package events
+
+import (
+	"sample-web/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	RentCreated       = "rent.created"
+	RentStatusChanged = "rent.status_changed"
+	RentTermsChanged  = "rent.terms_changed"
+)
+
+// RentCreatedData is the payload of RentCreated.
+type RentCreatedData struct {
+	RentId     bson.ObjectID
+	LandLordId bson.ObjectID
+}
+
+// RentStatusChangedData is the payload of RentStatusChanged.
+type RentStatusChangedData struct {
+	RentId     bson.ObjectID
+	LandLordId bson.ObjectID
+	TenantIds  []bson.ObjectID
+	From       models.RentStatus
+	To         models.RentStatus
+}
+
+// RentTermsChangedData is the payload of RentTermsChanged, Fields are the amended terms. Giving notice
+// moves the end date without amending the terms and does not publish it.
+type RentTermsChangedData struct {
+	RentId     bson.ObjectID
+	LandLordId bson.ObjectID
+	Fields     []string
+}
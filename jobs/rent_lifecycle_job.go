@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sample-web/services"
+	"sample-web/utils"
+	"time"
+)
+
+const rentLifecycleLockKey = "jobs:rent_lifecycle"
+
+// RentLifecycleJob moves rents between upcoming, active and expired as their dates pass. Only one
+// replica runs it at a time, the others skip the run while the lock is held.
+type RentLifecycleJob interface {
+	Run(ctx context.Context)
+}
+
+type rentLifecycleJob struct {
+	rentService services.RentService
+	lockService services.LockService
+	interval    time.Duration
+}
+
+func NewRentLifecycleJob(rentService services.RentService, lockService services.LockService, interval time.Duration) RentLifecycleJob {
+	return &rentLifecycleJob{
+		rentService: rentService,
+		lockService: lockService,
+		interval:    interval,
+	}
+}
+
+// Run transitions the rents right away and then on every interval until the context is done.
+func (j *rentLifecycleJob) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.transitionRents(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *rentLifecycleJob) transitionRents(ctx context.Context) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentLifecycleJob.transitionRents")
+	defer span.End()
+
+	release, acquired, err := j.lockService.TryLock(spanCtx, rentLifecycleLockKey, j.interval)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to take the rent lifecycle lock with %s", err.Error()))
+		return
+	}
+	if !acquired {
+		log.Info(spanCtx, "Rent lifecycle job is running on another replica")
+		return
+	}
+	defer release(spanCtx)
+
+	if _, err := j.rentService.TransitionRentLifecycles(spanCtx, time.Now()); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to transition rents with %s", err.Error()))
+	}
+}
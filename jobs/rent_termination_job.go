@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sample-web/services"
+	"sample-web/utils"
+	"time"
+)
+
+// RentTerminationJob closes the rents under notice once their move-out date is reached.
+type RentTerminationJob interface {
+	Run(ctx context.Context)
+}
+
+type rentTerminationJob struct {
+	rentService services.RentService
+	interval    time.Duration
+}
+
+func NewRentTerminationJob(rentService services.RentService, interval time.Duration) RentTerminationJob {
+	return &rentTerminationJob{
+		rentService: rentService,
+		interval:    interval,
+	}
+}
+
+// Run checks for rents to close right away and then on every interval until the context is done.
+func (j *rentTerminationJob) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.closeTerminatedRents(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *rentTerminationJob) closeTerminatedRents(ctx context.Context) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentTerminationJob.closeTerminatedRents")
+	defer span.End()
+
+	closed, err := j.rentService.CloseTerminatedRents(spanCtx, time.Now())
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to close terminated rents with %s", err.Error()))
+		return
+	}
+
+	if closed > 0 {
+		log.Info(spanCtx, fmt.Sprintf("Closed %d rents at the end of their notice", closed))
+	}
+}
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"os"
 	"sample-web/clients"
 	"sample-web/configs"
 	"sample-web/controllers"
+	"sample-web/events"
+	"sample-web/jobs"
 	"sample-web/repositories"
 	"sample-web/routes"
 	"sample-web/services"
+	"sample-web/storage"
 	"sample-web/utils"
+	"time"
 )
 
 const (
@@ -31,6 +36,8 @@ func main() {
 	jwtConfig := appConfigs.GetJWTConfig()
 	tracingConfig := appConfigs.GetTracingConfig()
 	twilioConfig := appConfigs.GetTwilioConfig()
+	rentConfig := appConfigs.GetRentConfig()
+	storageConfig := appConfigs.GetStorageConfig()
 
 	utils.InitLogger(tracingConfig)
 
@@ -56,30 +63,96 @@ func main() {
 	otpService := services.NewTwilioOTPService(twilioConfig, redisClient)
 	// otpService := services.NewDummyOTPService(redisClient)
 
+	// Initialize notification service
+	notificationService := services.NewTwilioNotificationService(twilioConfig)
+	// notificationService := services.NewDummyNotificationService()
+
 	// Initialize the user repository, service, and controller
 	userRepo := repositories.NewUserRepository(mongoClient.Database)
 	userService := services.NewUserService(userRepo)
 	userController := controllers.NewUserController(userService)
 
+	// Initialize rent, rent record and unit repositories
+	rentRepo := repositories.NewRentRepository(mongoClient.Database)
+	rentRecordRepo := repositories.NewRentRecordRepository(mongoClient.Database)
+	unitRepo := repositories.NewUnitRepository(mongoClient.Database)
+
 	// Initialize the auth service and controller
-	authService := services.NewAuthService(userRepo, jwtService)
+	authService := services.NewAuthService(userRepo, rentRepo, jwtService)
 	authController := controllers.NewAuthController(authService, otpService)
 
-	// Initialize rent repository, service, and controller
-	rentRepo := repositories.NewRentRepository(mongoClient.Database)
-	rentService := services.NewRentService(rentRepo, userRepo)
+	// Initialize the domain event publisher and the lock shared by the replicas
+	publisher := events.NewInProcessPublisher()
+	lockService := services.NewRedisLockService(redisClient)
+
+	// Initialize rent amendment repository, rent service, and controller
+	rentAmendmentRepo := repositories.NewRentAmendmentRepository(mongoClient.Database)
+	rentService := services.NewRentService(rentRepo, rentRecordRepo, userRepo, unitRepo, rentAmendmentRepo, notificationService, rentConfig, publisher)
 	rentController := controllers.NewRentController(rentService)
 
-	// initialize rent record	repository, service, and controller
-	rentRecordRepo := repositories.NewRentRecordRepository(mongoClient.Database)
-	rentRecordService := services.NewRentRecordService(rentRecordRepo, rentRepo, userRepo)
+	// Initialize the blob storage of attachments, files of the local storage are served by the API
+	blobStorage, err := storage.NewBlobStorage(storageConfig)
+	if err != nil {
+		panic(err)
+	}
+	localStorage, _ := blobStorage.(*storage.LocalStorage)
+	fileController := controllers.NewFileController(localStorage)
+
+	// initialize rent record service, and controller
+	counterRepo := repositories.NewCounterRepository(mongoClient.Database)
+	rentRecordService := services.NewRentRecordService(rentRecordRepo, rentRepo, userRepo, counterRepo, blobStorage, storageConfig, notificationService, rentConfig)
 	rentRecordController := controllers.NewRentRecordController(rentRecordService)
 
+	// Initialize agreement repository, service, and controller, agreements are drafted for new rents
+	agreementRepo := repositories.NewAgreementRepository(mongoClient.Database)
+	signatureOTPService := services.NewSignatureOTPService(redisClient, notificationService)
+	agreementService := services.NewAgreementService(agreementRepo, rentRepo, userRepo, blobStorage, signatureOTPService)
+	agreementController := controllers.NewAgreementController(agreementService)
+	publisher.Subscribe(events.RentCreated, agreementService.HandleRentCreated)
+	publisher.Subscribe(events.RentTermsChanged, agreementService.HandleRentTermsChanged)
+
+	// Initialize dispute repository, service, and controller
+	disputeRepo := repositories.NewDisputeRepository(mongoClient.Database)
+	disputeService := services.NewDisputeService(disputeRepo, rentRecordRepo, rentRepo, userRepo, notificationService)
+	disputeController := controllers.NewDisputeController(disputeService)
+
+	// Initialize maintenance repository, service, and controller
+	maintenanceRepo := repositories.NewMaintenanceRepository(mongoClient.Database)
+	maintenanceService := services.NewMaintenanceService(maintenanceRepo, rentRepo, userRepo, blobStorage, storageConfig, notificationService)
+	maintenanceController := controllers.NewMaintenanceController(maintenanceService)
+
+	// Initialize property repository, service, and controller
+	propertyRepo := repositories.NewPropertyRepository(mongoClient.Database)
+	propertyService := services.NewPropertyService(propertyRepo, unitRepo, rentRepo, userRepo)
+	propertyController := controllers.NewPropertyController(propertyService)
+
+	// Initialize unit service, and controller
+	unitService := services.NewUnitService(unitRepo, propertyRepo, rentRepo)
+	unitController := controllers.NewUnitController(unitService)
+
+	// Initialize exchange rate repository, service, and controller
+	exchangeRateRepo := repositories.NewExchangeRateRepository(mongoClient.Database)
+	exchangeRateService := services.NewExchangeRateService(exchangeRateRepo, userRepo)
+	exchangeRateController := controllers.NewExchangeRateController(exchangeRateService)
+
+	// Initialize dashboard repository, service, and controller
+	dashboardRepo := repositories.NewDashboardRepository(mongoClient.Database)
+	dashboardService := services.NewDashboardService(dashboardRepo, rentRepo, unitRepo, userRepo, exchangeRateService)
+	dashboardController := controllers.NewDashboardController(dashboardService)
+
 	// Initialize the health controller
 	healthController := controllers.NewHealthController()
 
+	// Start the job closing rents at the end of their notice period
+	rentTerminationJob := jobs.NewRentTerminationJob(rentService, time.Duration(rentConfig.TerminationCheckIntervalInSeconds)*time.Second)
+	go rentTerminationJob.Run(context.Background())
+
+	// Start the job moving rents between upcoming, active and expired
+	rentLifecycleJob := jobs.NewRentLifecycleJob(rentService, lockService, time.Duration(rentConfig.LifecycleCheckIntervalInSeconds)*time.Second)
+	go rentLifecycleJob.Run(context.Background())
+
 	// Set up router with all routes
-	r := routes.SetupRouter(healthController,userController, authController, rentController, rentRecordController, jwtService)
+	r := routes.SetupRouter(healthController,userController, authController, rentController, rentRecordController, propertyController, unitController, dashboardController, exchangeRateController, fileController, agreementController, disputeController, maintenanceController, jwtService)
 	// Start the server
 	r.Run(":8080")
 }
@@ -0,0 +1,28 @@
+package mappers
+
+import (
+	"sample-web/dto"
+	"sample-web/models"
+	"time"
+)
+
+func ToAgreementVerificationResponse(agreement models.Agreement) dto.AgreementVerificationResponse {
+	verificationResponse := dto.AgreementVerificationResponse{
+		AgreementId: agreement.Id.Hex(),
+		RentId:      agreement.RentId.Hex(),
+		Hash:        agreement.Hash,
+		Status:      string(agreement.Status),
+		Signatures:  []dto.VerifiedSignatureItem{},
+	}
+	if !agreement.SignedAt.IsZero() {
+		verificationResponse.SignedAt = agreement.SignedAt.Format(time.RFC3339)
+	}
+	for _, signature := range agreement.Signatures {
+		verificationResponse.Signatures = append(verificationResponse.Signatures, dto.VerifiedSignatureItem{
+			Name:     signature.Signer.Name,
+			Role:     string(signature.Role),
+			SignedAt: signature.SignedAt.Format(time.RFC3339),
+		})
+	}
+	return verificationResponse
+}
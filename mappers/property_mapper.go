@@ -0,0 +1,17 @@
+package mappers
+
+import (
+	"sample-web/dto"
+	"sample-web/models"
+)
+
+func ToAddressModel(address dto.AddressRequest) models.Address {
+	return models.Address{
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		State:      address.State,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+}
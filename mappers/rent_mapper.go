@@ -0,0 +1,16 @@
+package mappers
+
+import (
+	"sample-web/dto"
+	"sample-web/models"
+)
+
+func ToScheduleIntervalModel(interval *dto.ScheduleIntervalRequest) *models.ScheduleInterval {
+	if interval == nil {
+		return nil
+	}
+	return &models.ScheduleInterval{
+		Every: interval.Every,
+		Unit:  models.IntervalUnit(interval.Unit),
+	}
+}
@@ -0,0 +1,53 @@
+package mappers
+
+import (
+	"sample-web/dto"
+	"sample-web/models"
+	"time"
+)
+
+func ToRentRecordResponse(rentRecord models.RentRecord) dto.RentRecordResponse {
+	rentRecordResponse := dto.RentRecordResponse{
+		Id:             rentRecord.Id.Hex(),
+		RentId:         rentRecord.RentId.Hex(),
+		Amount:         rentRecord.Amount,
+		ApprovedAmount: rentRecord.ApprovedAmount,
+		ApprovalNote:   rentRecord.ApprovalNote,
+		PaymentMethod:  string(rentRecord.PaymentMethod),
+		Reference:      rentRecord.Reference,
+		PaidAt:         rentRecord.PaymentDate().Format("2006-01-02"),
+		SubmittedAt:    rentRecord.SubmittedAt.Format(time.RFC3339),
+		Status:         string(rentRecord.Status),
+		Attachments:    rentRecord.Attachments,
+		Rejection:      rentRecord.Rejection,
+		Edits:          rentRecord.Edits,
+		RecordedBy:     string(rentRecord.RecordedBy),
+		Confirmation:   rentRecord.Confirmation,
+		Dispute:        rentRecord.Dispute,
+		Disputed:       rentRecord.IsDisputed(),
+		Reversal:       rentRecord.Reversal,
+		StatusHistory:  rentRecord.StatusHistory,
+	}
+	if rentRecord.ResubmissionOf != nil {
+		rentRecordResponse.ResubmissionOf = rentRecord.ResubmissionOf.Hex()
+	}
+	if rentRecord.Reverses != nil {
+		rentRecordResponse.Reverses = rentRecord.Reverses.Hex()
+	}
+	if rentRecord.ResubmittedBy != nil {
+		rentRecordResponse.ResubmittedBy = rentRecord.ResubmittedBy.Hex()
+	}
+	if rentRecord.Receipt != nil {
+		rentRecordResponse.ReceiptNumber = rentRecord.Receipt.Number
+	}
+	if !rentRecord.ConfirmBy.IsZero() {
+		rentRecordResponse.ConfirmBy = rentRecord.ConfirmBy.Format(time.RFC3339)
+	}
+	if !rentRecord.WithdrawnAt.IsZero() {
+		rentRecordResponse.WithdrawnAt = rentRecord.WithdrawnAt.Format(time.RFC3339)
+	}
+	if !rentRecord.ApprovedAt.IsZero() {
+		rentRecordResponse.ApprovedAt = rentRecord.ApprovedAt.Format(time.RFC3339)
+	}
+	return rentRecordResponse
+}
@@ -12,6 +12,8 @@ func ToUserRole(role string) models.UserRole {
 		return models.LandLord
 	case string(models.Tenant):
 		return models.Tenant
+	case string(models.Admin):
+		return models.Admin
 	default:
 		return models.Tenant
 	}
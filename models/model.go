@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -10,30 +11,174 @@ type UserRole string
 
 type RentSchedule string
 
+type IntervalUnit string
+
 type RentStatus string
 
 type RentRecordStatus string
 
+type ShareType string
+
+type PropertyType string
+
+type UnitType string
+
+type UnitOccupancy string
+
+type RentAmendmentStatus string
+
+type PaymentMethod string
+
+type AgreementStatus string
+
+type DisputeStatus string
+
+type DisputeDecision string
+
+type RejectionReasonCode string
+
+type MaintenanceStatus string
+
+type MaintenanceCategory string
+
+type MaintenancePriority string
+
 const (
 	LandLord UserRole = "landlord"
 	Tenant   UserRole = "tenant"
+	// Admin manages shared data like exchange rates, it is granted in the database and cannot be
+	// picked by users themselves.
+	Admin UserRole = "admin"
+)
+
+const (
+	RentScheduleWeekly     RentSchedule = "weekly"
+	RentScheduleMonthly    RentSchedule = "monthly"
+	RentScheduleQuarterly  RentSchedule = "quarterly"
+	RentScheduleHalfYearly RentSchedule = "half_yearly"
+	RentScheduleYearly     RentSchedule = "yearly"
+	RentScheduleCustom     RentSchedule = "custom"
+)
+
+const (
+	IntervalUnitDays   IntervalUnit = "days"
+	IntervalUnitMonths IntervalUnit = "months"
+)
+
+const (
+	RentStatusUpcoming    RentStatus = "upcoming"
+	RentStatusActive      RentStatus = "active"
+	RentStatusExpired     RentStatus = "expired"
+	RentStatusNoticeGiven RentStatus = "notice_given"
+	RentStatusClosed      RentStatus = "closed"
+)
+
+const (
+	ShareTypeAmount     ShareType = "amount"
+	ShareTypePercentage ShareType = "percentage"
 )
 
 const (
-	RentScheduleWeekly    RentSchedule = "weekly"
-	RentScheduleMonthly   RentSchedule = "monthly"
-	RentScheduleQuarterly RentSchedule = "quarterly"
+	PropertyTypeApartmentBuilding PropertyType = "apartment_building"
+	PropertyTypeHouse             PropertyType = "house"
+	PropertyTypeCommercial        PropertyType = "commercial"
+	PropertyTypeOther             PropertyType = "other"
 )
 
 const (
-	RentStatusActive   RentStatus = "active"
-	RentStatusInactive RentStatus = "inactive"
+	UnitTypeApartment UnitType = "apartment"
+	UnitTypeRoom      UnitType = "room"
+	UnitTypeShop      UnitType = "shop"
+	UnitTypeOffice    UnitType = "office"
+	UnitTypeOther     UnitType = "other"
+)
+
+const (
+	UnitOccupancyLet      UnitOccupancy = "let"
+	UnitOccupancyVacant   UnitOccupancy = "vacant"
+	UnitOccupancyUpcoming UnitOccupancy = "upcoming"
+)
+
+const (
+	RentAmendmentStatusPending RentAmendmentStatus = "pending_acknowledgement"
+	RentAmendmentStatusApplied RentAmendmentStatus = "applied"
 )
 
 const (
 	RentRecordStatusPending  RentRecordStatus = "pending"
 	RentRecordStatusApproved RentRecordStatus = "approved"
 	RentRecordStatusRejected RentRecordStatus = "rejected"
+	// RentRecordStatusWithdrawn is a pending record the tenant took back before it was reviewed.
+	RentRecordStatusWithdrawn RentRecordStatus = "withdrawn"
+	// RentRecordStatusReversed is an approved record whose payment did not go through, like a
+	// bounced transfer, and the compensating entry that cancels it.
+	RentRecordStatusReversed RentRecordStatus = "reversed"
+)
+
+const (
+	DisputeStatusOpen        DisputeStatus = "open"
+	DisputeStatusUnderReview DisputeStatus = "under_review"
+	DisputeStatusResolved    DisputeStatus = "resolved"
+)
+
+const (
+	// DisputeDecisionReverse approves a rejected record or rejects an approved one.
+	DisputeDecisionReverse DisputeDecision = "reverse"
+	DisputeDecisionKeep    DisputeDecision = "keep"
+)
+
+// Maintenance requests only move forward, from open to resolved.
+const (
+	MaintenanceStatusOpen         MaintenanceStatus = "open"
+	MaintenanceStatusAcknowledged MaintenanceStatus = "acknowledged"
+	MaintenanceStatusInProgress   MaintenanceStatus = "in_progress"
+	MaintenanceStatusResolved     MaintenanceStatus = "resolved"
+)
+
+const (
+	MaintenanceCategoryPlumbing    MaintenanceCategory = "plumbing"
+	MaintenanceCategoryElectrical  MaintenanceCategory = "electrical"
+	MaintenanceCategoryAppliance   MaintenanceCategory = "appliance"
+	MaintenanceCategoryStructural  MaintenanceCategory = "structural"
+	MaintenanceCategoryPestControl MaintenanceCategory = "pest_control"
+	MaintenanceCategoryOther       MaintenanceCategory = "other"
+)
+
+const (
+	MaintenancePriorityLow    MaintenancePriority = "low"
+	MaintenancePriorityMedium MaintenancePriority = "medium"
+	MaintenancePriorityHigh   MaintenancePriority = "high"
+	MaintenancePriorityUrgent MaintenancePriority = "urgent"
+)
+
+// RecordConfirmationStatus is the answer of a tenant to a payment the landlord recorded for them.
+type RecordConfirmationStatus string
+
+const (
+	RecordConfirmationStatusConfirmed RecordConfirmationStatus = "confirmed"
+	RecordConfirmationStatusDisputed  RecordConfirmationStatus = "disputed"
+)
+
+const (
+	PaymentMethodCash         PaymentMethod = "cash"
+	PaymentMethodBankTransfer PaymentMethod = "bank_transfer"
+	PaymentMethodUPI          PaymentMethod = "upi"
+	PaymentMethodCheque       PaymentMethod = "cheque"
+	PaymentMethodCard         PaymentMethod = "card"
+)
+
+const (
+	RejectionReasonAmountMismatch     RejectionReasonCode = "amount_mismatch"
+	RejectionReasonPaymentNotReceived RejectionReasonCode = "payment_not_received"
+	RejectionReasonInvalidReference   RejectionReasonCode = "invalid_reference"
+	RejectionReasonDuplicate          RejectionReasonCode = "duplicate"
+	RejectionReasonOther              RejectionReasonCode = "other"
+)
+
+const (
+	AgreementStatusPendingSignatures AgreementStatus = "pending_signatures"
+	AgreementStatusSigned            AgreementStatus = "signed"
+	AgreementStatusSuperseded        AgreementStatus = "superseded"
 )
 
 type RefreshToken struct {
@@ -52,35 +197,267 @@ type User struct {
 	CurrentRole  UserRole      `bson:"current_role" json:"current_role"`
 }
 
+// HasRole reports whether the user holds the role, users created before roles were tracked only
+// hold their current role.
+func (user User) HasRole(role UserRole) bool {
+	if user.CurrentRole == role {
+		return true
+	}
+	for _, userRole := range user.Roles {
+		if userRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+// AddRole adds the role to the roles of the user if it is not held yet.
+func (user *User) AddRole(role UserRole) {
+	for _, userRole := range user.Roles {
+		if userRole == role {
+			return
+		}
+	}
+	user.Roles = append(user.Roles, role)
+}
+
 type PersonRef struct {
 	Id   bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
 	Name string        `bson:"name" json:"name"`
 }
 
+// TenantShare is the part of the rent a co-tenant pays, either a fixed amount or a percentage of the
+// rent. Percentage is in basis points, 2500 is 25%.
+type TenantShare struct {
+	Type       ShareType `bson:"type" json:"type"`
+	Percentage int64     `bson:"percentage,omitempty" json:"percentage,omitempty"`
+	Amount     *Money    `bson:"amount,omitempty" json:"amount,omitempty"`
+}
+
+type RentTenant struct {
+	Id          bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	Name        string        `bson:"name" json:"name"`
+	PhoneNumber string        `bson:"phone_number" json:"phone_number"`
+	Share       TenantShare   `bson:"share" json:"share"`
+	// Invited is true while the co-tenant has not registered yet, the tenant is
+	// attached once a user registers with PhoneNumber.
+	Invited bool `bson:"invited" json:"invited"`
+}
+
 type RentInfo struct {
-	Amount   float64      `bson:"amount" json:"amount"`
-	Schedule RentSchedule `bson:"schedule" json:"schedule"`
+	Amount      Money        `bson:"amount" json:"amount"`
+	ShareAmount Money        `bson:"share_amount" json:"share_amount"`
+	Schedule    RentSchedule `bson:"schedule" json:"schedule"`
 }
 
-type Rent struct {
+type Address struct {
+	Line1      string `bson:"line1" json:"line1"`
+	Line2      string `bson:"line2,omitempty" json:"line2,omitempty"`
+	City       string `bson:"city" json:"city"`
+	State      string `bson:"state,omitempty" json:"state,omitempty"`
+	PostalCode string `bson:"postal_code" json:"postal_code"`
+	Country    string `bson:"country" json:"country"`
+}
+
+type Property struct {
 	Id        bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
 	LandLord  PersonRef     `bson:"landlord" json:"landlord"`
-	Tenant    PersonRef     `bson:"tenant" json:"tenant"`
-	Title     string        `bson:"title" json:"title"`
-	Amount    float64       `bson:"amount" json:"amount"`
-	Schedule  RentSchedule  `bson:"schedule" json:"schedule"`
-	Status    RentStatus    `bson:"status" json:"status"`
-	StartDate time.Time     `bson:"start_date" json:"start_date"`
-	EndDate   time.Time     `bson:"end_date" json:"end_date"`
+	Name      string        `bson:"name" json:"name"`
+	Type      PropertyType  `bson:"type" json:"type"`
+	Address   Address       `bson:"address" json:"address"`
 	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time     `bson:"updated_at" json:"updated_at"`
 }
 
+type Unit struct {
+	Id         bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	PropertyId bson.ObjectID `bson:"property_id" json:"property_id"`
+	LandLord   PersonRef     `bson:"landlord" json:"landlord"`
+	UnitNumber string        `bson:"unit_number" json:"unit_number"`
+	Type       UnitType      `bson:"type" json:"type"`
+	CreatedAt  time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// ScheduleInterval is the period between installments of a custom schedule.
+type ScheduleInterval struct {
+	Every int          `bson:"every" json:"every"`
+	Unit  IntervalUnit `bson:"unit" json:"unit"`
+}
+
+// RentTermination is the notice given by the landlord or a co-tenant to end a rent, the end date of
+// the rent is moved to the move-out date.
+type RentTermination struct {
+	RequestedBy     PersonRef `bson:"requested_by" json:"requested_by"`
+	RequestedByRole UserRole  `bson:"requested_by_role" json:"requested_by_role"`
+	Reason          string    `bson:"reason,omitempty" json:"reason,omitempty"`
+	NoticeGivenAt   time.Time `bson:"notice_given_at" json:"notice_given_at"`
+	MoveOutDate     time.Time `bson:"move_out_date" json:"move_out_date"`
+	OriginalEndDate time.Time `bson:"original_end_date" json:"original_end_date"`
+}
+
+type UnitRef struct {
+	Id         bson.ObjectID `bson:"_id" json:"_id"`
+	PropertyId bson.ObjectID `bson:"property_id" json:"property_id"`
+	UnitNumber string        `bson:"unit_number" json:"unit_number"`
+}
+
+type Rent struct {
+	Id       bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	LandLord PersonRef     `bson:"landlord" json:"landlord"`
+	Tenants  []RentTenant  `bson:"tenants" json:"tenants"`
+	Unit     *UnitRef      `bson:"unit,omitempty" json:"unit,omitempty"`
+	Title    string        `bson:"title" json:"title"`
+	Amount   Money         `bson:"amount" json:"amount"`
+	Schedule RentSchedule  `bson:"schedule" json:"schedule"`
+	// Interval is only set for custom schedules.
+	Interval *ScheduleInterval `bson:"interval" json:"interval,omitempty"`
+	// AnchorDay is the day of the month installments of month based schedules are due on, it is
+	// moved to the last day of shorter months. The day of the start date is used when it is not set.
+	AnchorDay int        `bson:"anchor_day" json:"anchor_day,omitempty"`
+	Status    RentStatus `bson:"status" json:"status"`
+	// Termination is set once notice is given.
+	Termination *RentTermination `bson:"termination,omitempty" json:"termination,omitempty"`
+	// AgreementStatus is signed once the landlord and every co-tenant signed SignedAgreementId, it is
+	// superseded once the amount, schedule or end date change and a new agreement can be generated.
+	AgreementStatus   AgreementStatus `bson:"agreement_status,omitempty" json:"agreement_status,omitempty"`
+	SignedAgreementId *bson.ObjectID  `bson:"signed_agreement_id,omitempty" json:"signed_agreement_id,omitempty"`
+	StartDate         time.Time       `bson:"start_date" json:"start_date"`
+	EndDate           time.Time       `bson:"end_date" json:"end_date"`
+	CreatedAt         time.Time       `bson:"created_at" json:"created_at"`
+	UpdatedAt         time.Time       `bson:"updated_at" json:"updated_at"`
+}
+
+// Terms returns the amendable terms of the rent.
+func (rent Rent) Terms() RentTerms {
+	return RentTerms{
+		Title:     rent.Title,
+		Amount:    rent.Amount,
+		Schedule:  rent.Schedule,
+		Interval:  rent.Interval,
+		AnchorDay: rent.AnchorDay,
+		EndDate:   rent.EndDate,
+	}
+}
+
+// FindTenant returns the co-tenant with the given user id.
+func (rent Rent) FindTenant(userId bson.ObjectID) (RentTenant, bool) {
+	for _, tenant := range rent.Tenants {
+		if !tenant.Invited && tenant.Id == userId {
+			return tenant, true
+		}
+	}
+	return RentTenant{}, false
+}
+
+// String formats the share value, a percentage like 33.33 or an amount like 1500.50.
+func (share TenantShare) String() string {
+	if share.Type == ShareTypePercentage {
+		return fmt.Sprintf("%d.%02d", share.Percentage/100, share.Percentage%100)
+	}
+	if share.Amount == nil {
+		return ""
+	}
+	return share.Amount.String()
+}
+
+// ShareAmount returns the amount the co-tenant pays for each installment of the rent.
+func (rent Rent) ShareAmount(tenant RentTenant) Money {
+	shareAmounts := rent.ShareAmounts()
+	for i := range rent.Tenants {
+		if rent.Tenants[i].PhoneNumber == tenant.PhoneNumber {
+			return shareAmounts[i]
+		}
+	}
+	return NewMoney(0, rent.Amount.Currency)
+}
+
+// ShareAmounts returns the amount each co-tenant pays for each installment of the rent, in the order
+// of Tenants. Percentages are rounded down and the minor units left over are handed out one by one
+// to the co-tenants paying a percentage, so the shares always add up to the rent amount.
+func (rent Rent) ShareAmounts() []Money {
+	shareAmounts := make([]Money, len(rent.Tenants))
+	remaining := rent.Amount
+
+	var percentageTenants []int
+	for i, tenant := range rent.Tenants {
+		if tenant.Share.Type == ShareTypePercentage {
+			minor := rent.Amount.Minor * tenant.Share.Percentage / 10000
+			shareAmounts[i] = NewMoney(minor, rent.Amount.Currency)
+			percentageTenants = append(percentageTenants, i)
+		} else if tenant.Share.Amount != nil {
+			shareAmounts[i] = *tenant.Share.Amount
+		} else {
+			shareAmounts[i] = NewMoney(0, rent.Amount.Currency)
+		}
+		remaining = remaining.Sub(shareAmounts[i])
+	}
+
+	for n := 0; remaining.IsPositive() && n < len(percentageTenants); n++ {
+		i := percentageTenants[n]
+		shareAmounts[i] = shareAmounts[i].Add(NewMoney(1, rent.Amount.Currency))
+		remaining = remaining.Sub(NewMoney(1, rent.Amount.Currency))
+	}
+
+	return shareAmounts
+}
+
+// RentTerms are the terms of a rent that can be amended.
+type RentTerms struct {
+	Title     string            `bson:"title" json:"title"`
+	Amount    Money             `bson:"amount" json:"amount"`
+	Schedule  RentSchedule      `bson:"schedule" json:"schedule"`
+	Interval  *ScheduleInterval `bson:"interval" json:"interval,omitempty"`
+	AnchorDay int               `bson:"anchor_day" json:"anchor_day,omitempty"`
+	EndDate   time.Time         `bson:"end_date" json:"end_date"`
+}
+
+type RentAcknowledgement struct {
+	Tenant         PersonRef `bson:"tenant" json:"tenant"`
+	AcknowledgedAt time.Time `bson:"acknowledged_at" json:"acknowledged_at"`
+}
+
+// RentAmendment is an entry of the amendment log of a rent, amendments are never changed once
+// applied. Fields are the names of the terms that differ between Previous and Proposed.
+type RentAmendment struct {
+	Id               bson.ObjectID         `bson:"_id,omitempty" json:"_id,omitempty"`
+	RentId           bson.ObjectID         `bson:"rent_id" json:"rent_id"`
+	Version          int                   `bson:"version" json:"version"`
+	ChangedBy        PersonRef             `bson:"changed_by" json:"changed_by"`
+	Reason           string                `bson:"reason,omitempty" json:"reason,omitempty"`
+	Fields           []string              `bson:"fields" json:"fields"`
+	Previous         RentTerms             `bson:"previous" json:"previous"`
+	Proposed         RentTerms             `bson:"proposed" json:"proposed"`
+	Status           RentAmendmentStatus   `bson:"status" json:"status"`
+	Acknowledgements []RentAcknowledgement `bson:"acknowledgements" json:"acknowledgements"`
+	CreatedAt        time.Time             `bson:"created_at" json:"created_at"`
+	AppliedAt        *time.Time            `bson:"applied_at,omitempty" json:"applied_at,omitempty"`
+}
+
+// IsAcknowledgedBy reports whether the co-tenant acknowledged the amendment.
+func (amendment RentAmendment) IsAcknowledgedBy(tenantId bson.ObjectID) bool {
+	for _, acknowledgement := range amendment.Acknowledgements {
+		if acknowledgement.Tenant.Id == tenantId {
+			return true
+		}
+	}
+	return false
+}
+
+// RentRecord is a payment submitted by a co-tenant, or one the landlord collected offline and
+// recorded as approved.
 type RentRecord struct {
-	Id          bson.ObjectID    `bson:"_id,omitempty" json:"_id,omitempty"`
-	RentId      bson.ObjectID    `bson:"rent_id" json:"rent_id"`
-	Rent        RentInfo         `bson:"rent" json:"rent"`
-	Amount      float64          `bson:"amount" json:"amount"`
+	Id            bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	RentId        bson.ObjectID `bson:"rent_id" json:"rent_id"`
+	Rent          RentInfo      `bson:"rent" json:"rent"`
+	Amount        Money         `bson:"amount" json:"amount"`
+	DueDate       time.Time     `bson:"due_date,omitempty" json:"due_date"`
+	PaymentMethod PaymentMethod `bson:"payment_method,omitempty" json:"payment_method,omitempty"`
+	// Reference is the transaction reference of the payment, like a UTR or cheque number. It is
+	// unique among the pending and approved records of a rent.
+	Reference string `bson:"reference,omitempty" json:"reference,omitempty"`
+	// PaidAt is when the tenant paid, which can be before the record was submitted.
+	PaidAt      time.Time        `bson:"paid_at,omitempty" json:"paid_at"`
 	SubmittedAt time.Time        `bson:"submitted_at" json:"submitted_at"`
 	ApprovedAt  time.Time        `bson:"approved_at" json:"approved_at"`
 	Status      RentRecordStatus `bson:"status" json:"status"`
@@ -88,4 +465,351 @@ type RentRecord struct {
 	UpdatedAt   time.Time        `bson:"updated_at" json:"updated_at"`
 	LandLord    PersonRef        `bson:"landlord" json:"landlord"`
 	Tenant      PersonRef        `bson:"tenant" json:"tenant"`
+	Attachments []Attachment     `bson:"attachments,omitempty" json:"attachments,omitempty"`
+	Receipt     *Receipt         `bson:"receipt,omitempty" json:"receipt,omitempty"`
+	Rejection   *RecordRejection `bson:"rejection,omitempty" json:"rejection,omitempty"`
+	// ResubmissionOf and ResubmittedBy link a rejected record and the one it was resubmitted as, a
+	// record can be resubmitted once.
+	ResubmissionOf *bson.ObjectID `bson:"resubmission_of,omitempty" json:"resubmission_of,omitempty"`
+	ResubmittedBy  *bson.ObjectID `bson:"resubmitted_by,omitempty" json:"resubmitted_by,omitempty"`
+	// Edits are the changes the tenant made while the record was pending.
+	Edits       []RentRecordEdit `bson:"edits,omitempty" json:"edits,omitempty"`
+	WithdrawnAt time.Time        `bson:"withdrawn_at,omitempty" json:"withdrawn_at,omitempty"`
+	RecordedBy  UserRole         `bson:"recorded_by,omitempty" json:"recorded_by,omitempty"`
+	// ConfirmBy is until when the tenant can confirm or dispute a record the landlord recorded.
+	ConfirmBy    time.Time           `bson:"confirm_by,omitempty" json:"confirm_by,omitempty"`
+	Confirmation *RecordConfirmation `bson:"confirmation,omitempty" json:"confirmation,omitempty"`
+	Dispute      *RecordDispute      `bson:"dispute,omitempty" json:"dispute,omitempty"`
+	Reversal     *RecordReversal     `bson:"reversal,omitempty" json:"reversal,omitempty"`
+	// Reverses is set on the compensating entry with the negative amount, it is the record it cancels.
+	Reverses *bson.ObjectID `bson:"reverses,omitempty" json:"reverses,omitempty"`
+	// StatusHistory keeps every status the record moved to.
+	StatusHistory []RecordStatusChange `bson:"status_history,omitempty" json:"status_history,omitempty"`
+	// ApprovedAmount is what actually arrived when the landlord approved more or less than Amount,
+	// ApprovalNote explains the difference to the tenant.
+	ApprovedAmount *Money `bson:"approved_amount,omitempty" json:"approved_amount,omitempty"`
+	ApprovalNote   string `bson:"approval_note,omitempty" json:"approval_note,omitempty"`
+}
+
+// RecordStatusChange is a status a rent record moved to, Actor is who moved it.
+type RecordStatusChange struct {
+	Status    RentRecordStatus `bson:"status" json:"status"`
+	Actor     PersonRef        `bson:"actor" json:"actor"`
+	Role      UserRole         `bson:"role" json:"role"`
+	Reason    string           `bson:"reason,omitempty" json:"reason,omitempty"`
+	ChangedAt time.Time        `bson:"changed_at" json:"changed_at"`
+}
+
+// RecordReversal is why the landlord reversed an approved record, EntryId is the compensating entry.
+type RecordReversal struct {
+	Reason     string        `bson:"reason" json:"reason"`
+	EntryId    bson.ObjectID `bson:"entry_id" json:"entry_id"`
+	ReversedBy PersonRef     `bson:"reversed_by" json:"reversed_by"`
+	ReversedAt time.Time     `bson:"reversed_at" json:"reversed_at"`
+}
+
+// RecordDispute flags a record with its latest dispute.
+type RecordDispute struct {
+	Id     bson.ObjectID `bson:"_id" json:"id"`
+	Status DisputeStatus `bson:"status" json:"status"`
+}
+
+// IsDisputed reports whether the record has a dispute that is not resolved.
+func (rentRecord RentRecord) IsDisputed() bool {
+	return rentRecord.Dispute != nil && rentRecord.Dispute.Status != DisputeStatusResolved
+}
+
+// RecordConfirmation is the answer of the tenant to a record the landlord recorded for them, Comment
+// explains a dispute.
+type RecordConfirmation struct {
+	Status      RecordConfirmationStatus `bson:"status" json:"status"`
+	Comment     string                   `bson:"comment,omitempty" json:"comment,omitempty"`
+	RespondedAt time.Time                `bson:"responded_at" json:"responded_at"`
+}
+
+// RentRecordEdit is an edit the tenant made to a pending rent record.
+type RentRecordEdit struct {
+	Changes  []RentRecordChange `bson:"changes" json:"changes"`
+	EditedBy PersonRef          `bson:"edited_by" json:"edited_by"`
+	EditedAt time.Time          `bson:"edited_at" json:"edited_at"`
+}
+
+// RentRecordChange is the value of a field before and after an edit.
+type RentRecordChange struct {
+	Field string `bson:"field" json:"field"`
+	From  string `bson:"from" json:"from"`
+	To    string `bson:"to" json:"to"`
+}
+
+// RecordRejection is why the landlord rejected a rent record.
+type RecordRejection struct {
+	Code       RejectionReasonCode `bson:"code" json:"code"`
+	Message    string              `bson:"message" json:"message"`
+	RejectedAt time.Time           `bson:"rejected_at" json:"rejected_at"`
+}
+
+// Receipt is the PDF receipt of an approved rent record, its number is sequential per landlord.
+type Receipt struct {
+	Number      string    `bson:"number" json:"number"`
+	StorageKey  string    `bson:"storage_key" json:"-"`
+	GeneratedAt time.Time `bson:"generated_at" json:"generated_at"`
+}
+
+// Attachment is a file kept in blob storage under StorageKey, like a proof of payment.
+type Attachment struct {
+	Id          bson.ObjectID `bson:"_id" json:"id"`
+	FileName    string        `bson:"file_name" json:"file_name"`
+	ContentType string        `bson:"content_type" json:"content_type"`
+	Size        int64         `bson:"size" json:"size"`
+	StorageKey  string        `bson:"storage_key" json:"-"`
+	UploadedBy  PersonRef     `bson:"uploaded_by" json:"uploaded_by"`
+	UploadedAt  time.Time     `bson:"uploaded_at" json:"uploaded_at"`
+}
+
+// FindAttachment returns the attachment of the record with the id.
+func (rentRecord RentRecord) FindAttachment(attachmentId bson.ObjectID) (Attachment, bool) {
+	for _, attachment := range rentRecord.Attachments {
+		if attachment.Id == attachmentId {
+			return attachment, true
+		}
+	}
+	return Attachment{}, false
+}
+
+// SettledAmount is the amount the landlord approved, which is the submitted amount unless they
+// approved an adjusted one.
+func (rentRecord RentRecord) SettledAmount() Money {
+	if rentRecord.ApprovedAmount != nil {
+		return *rentRecord.ApprovedAmount
+	}
+	return rentRecord.Amount
+}
+
+// PaymentDate returns when the tenant paid, records created before the payment date was recorded
+// fall back to their submission date.
+func (rentRecord RentRecord) PaymentDate() time.Time {
+	if rentRecord.PaidAt.IsZero() {
+		return rentRecord.SubmittedAt
+	}
+	return rentRecord.PaidAt
+}
+
+// TenantRecordTotals is the sum of a co-tenant's rent record amounts by status, in minor units of
+// the rent currency.
+type TenantRecordTotals struct {
+	TenantId bson.ObjectID `bson:"_id" json:"tenant_id"`
+	Approved int64         `bson:"approved" json:"approved"`
+	Pending  int64         `bson:"pending" json:"pending"`
+	// Reversed is the sum of the compensating entries, it is zero or negative.
+	Reversed int64 `bson:"reversed" json:"reversed"`
+}
+
+// RentRecordSummary is the aggregate of the rent records of a rent, amounts are in minor units of
+// the rent currency.
+type RentRecordSummary struct {
+	Approved        int64      `bson:"approved" json:"approved"`
+	Pending         int64      `bson:"pending" json:"pending"`
+	Rejected        int64      `bson:"rejected" json:"rejected"`
+	Disputed        int        `bson:"disputed" json:"disputed"`
+	LastPaymentDate *time.Time `bson:"last_payment_date" json:"last_payment_date"`
+	OnTimePayments  int        `bson:"on_time_payments" json:"on_time_payments"`
+	LatePayments    int        `bson:"late_payments" json:"late_payments"`
+}
+
+// DailyIncome is the approved rent paid on a day in one currency, Date is formatted as YYYY-MM-DD.
+type DailyIncome struct {
+	Date     string `bson:"date" json:"date"`
+	Amount   Money  `bson:"amount" json:"amount"`
+	Payments int    `bson:"payments" json:"payments"`
+}
+
+// RentPaymentTotal is the approved amount collected for a rent, in minor units of the rent currency.
+type RentPaymentTotal struct {
+	RentId   bson.ObjectID `bson:"_id" json:"rent_id"`
+	Approved int64         `bson:"approved" json:"approved"`
+}
+
+// LandLordRecordStats is the aggregate of all the rent records of a landlord. PendingApprovals only
+// holds the oldest pending records with the fields the dashboard lists, PendingByDay totals all of
+// them by the day they were paid.
+type LandLordRecordStats struct {
+	DailyIncome      []DailyIncome      `bson:"daily_income" json:"daily_income"`
+	PendingApprovals []RentRecord       `bson:"pending_approvals" json:"pending_approvals"`
+	PendingByDay     []DailyIncome      `bson:"pending_by_day" json:"pending_by_day"`
+	ApprovedByRent   []RentPaymentTotal `bson:"approved_by_rent" json:"approved_by_rent"`
+}
+
+// TenantRentTotals is the sum of a tenant's rent record amounts for a rent, in minor units of the
+// rent currency.
+type TenantRentTotals struct {
+	RentId   bson.ObjectID `bson:"_id" json:"rent_id"`
+	Approved int64         `bson:"approved" json:"approved"`
+	Pending  int64         `bson:"pending" json:"pending"`
+	// CoveredDueDates are the installments the tenant has a pending or approved record for.
+	CoveredDueDates []time.Time `bson:"covered_due_dates" json:"covered_due_dates"`
+}
+
+// TenantRecordStats is the aggregate of all the rent records of a tenant.
+type TenantRecordStats struct {
+	TotalsByRent []TenantRentTotals `bson:"totals_by_rent" json:"totals_by_rent"`
+	History      []RentRecord       `bson:"history" json:"history"`
+}
+
+// ExchangeRate converts BaseCurrency to QuoteCurrency from EffectiveFrom until the next rate of the
+// same pair takes effect, one unit of BaseCurrency is Rate units of QuoteCurrency.
+type ExchangeRate struct {
+	Id            bson.ObjectID   `bson:"_id,omitempty" json:"id"`
+	BaseCurrency  string          `bson:"base_currency" json:"base_currency"`
+	QuoteCurrency string          `bson:"quote_currency" json:"quote_currency"`
+	Rate          bson.Decimal128 `bson:"rate" json:"rate"`
+	EffectiveFrom time.Time       `bson:"effective_from" json:"effective_from"`
+	CreatedBy     PersonRef       `bson:"created_by" json:"created_by"`
+	CreatedAt     time.Time       `bson:"created_at" json:"created_at"`
+}
+
+// AgreementTemplate is a version of the rental agreement template of a landlord, saving a template
+// adds a new version so agreements keep pointing at the text they were rendered from.
+type AgreementTemplate struct {
+	Id        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	LandLord  PersonRef     `bson:"landlord" json:"landlord"`
+	Version   int           `bson:"version" json:"version"`
+	Body      string        `bson:"body" json:"body"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// Agreement is a rental agreement of a rent rendered as a PDF. TemplateVersion is zero when it was
+// rendered from the built in template and Hash is the hex encoded SHA-256 of the PDF. An agreement
+// still waiting for signatures is superseded when the terms it was rendered with change.
+type Agreement struct {
+	Id              bson.ObjectID        `bson:"_id,omitempty" json:"id"`
+	RentId          bson.ObjectID        `bson:"rent_id" json:"rent_id"`
+	LandLord        PersonRef            `bson:"landlord" json:"landlord"`
+	TemplateVersion int                  `bson:"template_version" json:"template_version"`
+	StorageKey      string               `bson:"storage_key" json:"-"`
+	Hash            string               `bson:"hash" json:"hash"`
+	Status          AgreementStatus      `bson:"status" json:"status"`
+	Signatures      []AgreementSignature `bson:"signatures,omitempty" json:"signatures,omitempty"`
+	SignedAt        time.Time            `bson:"signed_at,omitempty" json:"signed_at,omitempty"`
+	SupersededAt    time.Time            `bson:"superseded_at,omitempty" json:"superseded_at,omitempty"`
+	CreatedAt       time.Time            `bson:"created_at" json:"created_at"`
+}
+
+// IsSignedBy reports whether the user signed the agreement.
+func (agreement Agreement) IsSignedBy(userId bson.ObjectID) bool {
+	for _, signature := range agreement.Signatures {
+		if signature.Signer.Id == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// AgreementSignature is a signature confirmed with an OTP sent to the phone number of the signer,
+// DocumentHash is the hash of the PDF the signer was shown.
+type AgreementSignature struct {
+	Signer       PersonRef `bson:"signer" json:"signer"`
+	Role         UserRole  `bson:"role" json:"role"`
+	PhoneNumber  string    `bson:"phone_number" json:"phone_number"`
+	DocumentHash string    `bson:"document_hash" json:"document_hash"`
+	IPAddress    string    `bson:"ip_address" json:"ip_address"`
+	SignedAt     time.Time `bson:"signed_at" json:"signed_at"`
+}
+
+// Dispute is a tenant contesting the review of an approved or rejected rent record. The landlord and
+// the tenant discuss it in Messages until the landlord resolves it, the outcome can reverse the status
+// of the record.
+type Dispute struct {
+	Id           bson.ObjectID    `bson:"_id,omitempty" json:"id"`
+	RentId       bson.ObjectID    `bson:"rent_id" json:"rent_id"`
+	RentRecordId bson.ObjectID    `bson:"rent_record_id" json:"rent_record_id"`
+	LandLord     PersonRef        `bson:"landlord" json:"landlord"`
+	Tenant       PersonRef        `bson:"tenant" json:"tenant"`
+	RecordStatus RentRecordStatus `bson:"record_status" json:"record_status"`
+	Reason       string           `bson:"reason" json:"reason"`
+	Status       DisputeStatus    `bson:"status" json:"status"`
+	Messages     []DisputeMessage `bson:"messages" json:"messages"`
+	Outcome      *DisputeOutcome  `bson:"outcome,omitempty" json:"outcome,omitempty"`
+	CreatedAt    time.Time        `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time        `bson:"updated_at" json:"updated_at"`
+}
+
+type DisputeMessage struct {
+	Id        bson.ObjectID `bson:"_id" json:"id"`
+	Author    PersonRef     `bson:"author" json:"author"`
+	Role      UserRole      `bson:"role" json:"role"`
+	Body      string        `bson:"body" json:"body"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// DisputeOutcome is how the landlord resolved a dispute, RecordStatus is the status of the record
+// after it.
+type DisputeOutcome struct {
+	Decision     DisputeDecision  `bson:"decision" json:"decision"`
+	Note         string           `bson:"note" json:"note"`
+	RecordStatus RentRecordStatus `bson:"record_status" json:"record_status"`
+	ResolvedAt   time.Time        `bson:"resolved_at" json:"resolved_at"`
+}
+
+// MaintenanceRequest is a repair a co-tenant asked the landlord for. The landlord moves it from open
+// to resolved, every move is kept in StatusHistory, and both sides can comment on it.
+type MaintenanceRequest struct {
+	Id            bson.ObjectID             `bson:"_id,omitempty" json:"id"`
+	RentId        bson.ObjectID             `bson:"rent_id" json:"rent_id"`
+	RentTitle     string                    `bson:"rent_title" json:"rent_title"`
+	LandLord      PersonRef                 `bson:"landlord" json:"landlord"`
+	Tenant        PersonRef                 `bson:"tenant" json:"tenant"`
+	Category      MaintenanceCategory       `bson:"category" json:"category"`
+	Priority      MaintenancePriority       `bson:"priority" json:"priority"`
+	Description   string                    `bson:"description" json:"description"`
+	Photos        []Attachment              `bson:"photos" json:"photos"`
+	Status        MaintenanceStatus         `bson:"status" json:"status"`
+	StatusHistory []MaintenanceStatusChange `bson:"status_history" json:"status_history"`
+	Comments      []MaintenanceComment      `bson:"comments" json:"comments"`
+	CreatedAt     time.Time                 `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time                 `bson:"updated_at" json:"updated_at"`
+}
+
+// MaintenanceStatusChange is a status a maintenance request moved to, ChangedBy is who moved it.
+type MaintenanceStatusChange struct {
+	Status    MaintenanceStatus `bson:"status" json:"status"`
+	ChangedBy PersonRef         `bson:"changed_by" json:"changed_by"`
+	Note      string            `bson:"note,omitempty" json:"note,omitempty"`
+	ChangedAt time.Time         `bson:"changed_at" json:"changed_at"`
+}
+
+type MaintenanceComment struct {
+	Id        bson.ObjectID `bson:"_id" json:"id"`
+	Author    PersonRef     `bson:"author" json:"author"`
+	Role      UserRole      `bson:"role" json:"role"`
+	Body      string        `bson:"body" json:"body"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// maintenanceStatusOrder is the position of each status in the life of a maintenance request.
+var maintenanceStatusOrder = map[MaintenanceStatus]int{
+	MaintenanceStatusOpen:         0,
+	MaintenanceStatusAcknowledged: 1,
+	MaintenanceStatusInProgress:   2,
+	MaintenanceStatusResolved:     3,
+}
+
+// CanMoveTo reports whether a request can move from the status to next, statuses can be skipped but
+// a request never moves back.
+func (status MaintenanceStatus) CanMoveTo(next MaintenanceStatus) bool {
+	from, ok := maintenanceStatusOrder[status]
+	if !ok {
+		return false
+	}
+	to, ok := maintenanceStatusOrder[next]
+	return ok && to > from
+}
+
+// FindPhoto returns the photo of the request with the id.
+func (request MaintenanceRequest) FindPhoto(photoId bson.ObjectID) (Attachment, bool) {
+	for _, photo := range request.Photos {
+		if photo.Id == photoId {
+			return photo, true
+		}
+	}
+	return Attachment{}, false
 }
@@ -0,0 +1,195 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// currencyExponents lists the ISO-4217 currencies that do not have two decimal places.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyExponent returns the number of decimal places of the currency.
+func CurrencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// Money is an amount in the minor unit of its currency, 1500.50 INR is stored as 150050.
+type Money struct {
+	Minor    int64  `bson:"minor"`
+	Currency string `bson:"currency"`
+}
+
+// NewMoney returns an amount of minor units of the currency.
+func NewMoney(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: currency}
+}
+
+// ParseMoney parses a positive decimal amount in the major unit of the currency, the amount cannot
+// have more decimal places than the currency.
+func ParseMoney(amount string, currency string) (Money, error) {
+	minor, err := parseDecimal(amount, CurrencyExponent(currency))
+	if err != nil {
+		return Money{}, fmt.Errorf("amount %w for %s", err, currency)
+	}
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// ParsePercentage parses a positive percentage with at most two decimal places into basis points,
+// 33.33 is 3333.
+func ParsePercentage(percentage string) (int64, error) {
+	basisPoints, err := parseDecimal(percentage, 2)
+	if err != nil {
+		return 0, fmt.Errorf("percentage %w", err)
+	}
+	return basisPoints, nil
+}
+
+// parseDecimal parses a positive decimal number with at most the given number of decimal places
+// into an integer scaled by that many places.
+func parseDecimal(value string, places int) (int64, error) {
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" || strings.Trim(whole, "0123456789") != "" || strings.Trim(fraction, "0123456789") != "" {
+		return 0, fmt.Errorf("%q is not a valid decimal number", value)
+	}
+	if len(fraction) > places {
+		return 0, fmt.Errorf("%q has more than %d decimal places", value, places)
+	}
+	fraction += strings.Repeat("0", places-len(fraction))
+
+	var scaled int64
+	for _, digit := range whole + fraction {
+		if scaled > (math.MaxInt64-9)/10 {
+			return 0, fmt.Errorf("%q is too large", value)
+		}
+		scaled = scaled*10 + int64(digit-'0')
+	}
+
+	if scaled <= 0 {
+		return 0, errors.New("must be positive")
+	}
+
+	return scaled, nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Minor > 0
+}
+
+// Add returns the sum of both amounts, adding amounts in different currencies is a programming error.
+func (m Money) Add(other Money) Money {
+	m.mustMatch(other)
+	if m.Currency == "" {
+		m.Currency = other.Currency
+	}
+	return Money{Minor: m.Minor + other.Minor, Currency: m.Currency}
+}
+
+// Sub returns the difference of both amounts, subtracting amounts in different currencies is a
+// programming error.
+func (m Money) Sub(other Money) Money {
+	m.mustMatch(other)
+	if m.Currency == "" {
+		m.Currency = other.Currency
+	}
+	return Money{Minor: m.Minor - other.Minor, Currency: m.Currency}
+}
+
+// Times returns the amount multiplied by n.
+func (m Money) Times(n int) Money {
+	return Money{Minor: m.Minor * int64(n), Currency: m.Currency}
+}
+
+// AddByCurrency adds the amount to the total of its currency, totals holds one amount per currency
+// in the order they were first seen.
+func AddByCurrency(totals []Money, amount Money) []Money {
+	for i := range totals {
+		if totals[i].Currency == amount.Currency {
+			totals[i].Minor += amount.Minor
+			return totals
+		}
+	}
+	return append(totals, amount)
+}
+
+// Convert returns the amount in the currency using the rate, one major unit of the amount currency
+// is rate major units of the currency. The result is rounded half away from zero to the minor unit.
+func (m Money) Convert(rate *big.Rat, currency string) Money {
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Minor), rate)
+
+	shift := CurrencyExponent(currency) - CurrencyExponent(m.Currency)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil))
+	if shift >= 0 {
+		converted.Mul(converted, scale)
+	} else {
+		converted.Quo(converted, scale)
+	}
+
+	// rounding half away from zero is truncating the amount moved half a minor unit away from zero
+	half := big.NewRat(1, 2)
+	if converted.Sign() < 0 {
+		half.Neg(half)
+	}
+	converted.Add(converted, half)
+	minor := new(big.Int).Quo(converted.Num(), converted.Denom())
+
+	return Money{Minor: minor.Int64(), Currency: currency}
+}
+
+// String formats the amount in the major unit of the currency, like 1500.50.
+func (m Money) String() string {
+	exponent := CurrencyExponent(m.Currency)
+
+	sign := ""
+	minor := m.Minor
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	if exponent == 0 {
+		return fmt.Sprintf("%s%d", sign, minor)
+	}
+
+	scale := int64(math.Pow10(exponent))
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/scale, exponent, minor%scale)
+}
+
+// MarshalJSON writes the amount as a decimal string so clients never see a rounded float.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{
+		Amount:   m.String(),
+		Currency: m.Currency,
+	})
+}
+
+// mustMatch panics when the currencies differ, a zero amount without currency matches any.
+func (m Money) mustMatch(other Money) {
+	if m.Currency != "" && other.Currency != "" && m.Currency != other.Currency {
+		panic(fmt.Sprintf("money currency mismatch: %s and %s", m.Currency, other.Currency))
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
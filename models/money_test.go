@@ -0,0 +1,90 @@
+package models
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		value   string
+		places  int
+		want    int64
+		wantErr bool
+	}{
+		{value: "1500.50", places: 2, want: 150050},
+		{value: "1500.5", places: 2, want: 150050},
+		{value: "1500", places: 2, want: 150000},
+		{value: "1500.", places: 2, want: 150000},
+		{value: "0.01", places: 2, want: 1},
+		{value: "007.10", places: 2, want: 710},
+		{value: "12", places: 0, want: 12},
+		{value: "1.234", places: 3, want: 1234},
+		{value: "1.234", places: 2, wantErr: true},
+		{value: "12.5", places: 0, wantErr: true},
+		{value: "0", places: 2, wantErr: true},
+		{value: "0.00", places: 2, wantErr: true},
+		{value: "-1", places: 2, wantErr: true},
+		{value: "+1", places: 2, wantErr: true},
+		{value: "", places: 2, wantErr: true},
+		{value: ".5", places: 2, wantErr: true},
+		{value: "1e3", places: 2, wantErr: true},
+		{value: "1,000", places: 2, wantErr: true},
+		{value: "1.2.3", places: 2, wantErr: true},
+		{value: " 1", places: 2, wantErr: true},
+		{value: "92233720368547757.99", places: 2, want: 9223372036854775799},
+		{value: "92233720368547758.08", places: 2, wantErr: true},
+		{value: "99999999999999999999", places: 0, wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseDecimal(test.value, test.places)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseDecimal(%q, %d) = %d, want an error", test.value, test.places, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseDecimal(%q, %d) failed with %v", test.value, test.places, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseDecimal(%q, %d) = %d, want %d", test.value, test.places, got, test.want)
+		}
+	}
+}
+
+func TestMoneyConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Money
+		rate     string
+		currency string
+		want     Money
+	}{
+		{name: "same exponent", amount: NewMoney(1000, "USD"), rate: "83.1234", currency: "INR", want: NewMoney(83123, "INR")},
+		{name: "rounds half up", amount: NewMoney(1, "USD"), rate: "0.5", currency: "EUR", want: NewMoney(1, "EUR")},
+		{name: "rounds below half down", amount: NewMoney(1, "USD"), rate: "0.49", currency: "EUR", want: NewMoney(0, "EUR")},
+		{name: "rounds negative half away from zero", amount: NewMoney(-1, "USD"), rate: "0.5", currency: "EUR", want: NewMoney(-1, "EUR")},
+		{name: "rounds negative below half toward zero", amount: NewMoney(-1, "USD"), rate: "0.25", currency: "EUR", want: NewMoney(0, "EUR")},
+		{name: "to fewer decimals", amount: NewMoney(1000, "USD"), rate: "150", currency: "JPY", want: NewMoney(1500, "JPY")},
+		{name: "to fewer decimals rounded", amount: NewMoney(1050, "USD"), rate: "1", currency: "JPY", want: NewMoney(11, "JPY")},
+		{name: "to more decimals", amount: NewMoney(1500, "JPY"), rate: "1/150", currency: "USD", want: NewMoney(1000, "USD")},
+		{name: "to more decimals rounded", amount: NewMoney(1, "JPY"), rate: "1/150", currency: "USD", want: NewMoney(1, "USD")},
+		{name: "to three decimals", amount: NewMoney(1000, "USD"), rate: "0.3075", currency: "KWD", want: NewMoney(3075, "KWD")},
+		{name: "zero", amount: NewMoney(0, "USD"), rate: "83.1234", currency: "INR", want: NewMoney(0, "INR")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rate, ok := new(big.Rat).SetString(test.rate)
+			if !ok {
+				t.Fatalf("invalid rate %q", test.rate)
+			}
+			if got := test.amount.Convert(rate, test.currency); got != test.want {
+				t.Errorf("%v.Convert(%s, %s) = %v, want %v", test.amount, test.rate, test.currency, got, test.want)
+			}
+		})
+	}
+}
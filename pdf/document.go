@@ -0,0 +1,295 @@
+// Package pdf writes simple text documents as PDF. It only uses the standard Helvetica fonts every
+// PDF reader ships with, so nothing has to be embedded and no native libraries are needed. These
+// fonts cover the WinAnsi characters, documents with other characters fail to write instead of
+// showing something else. Writable tells whether text users entered can be written.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+// A4 page size and margins in points.
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	margin       = 56.0
+	contentWidth = pageWidth - 2*margin
+)
+
+const (
+	bodySize    = 10.0
+	headingSize = 16.0
+	lineSpacing = 1.4
+	labelWidth  = 150.0
+)
+
+type font struct {
+	resource string
+	widths   *[95]int
+}
+
+var (
+	regular = font{resource: "F1", widths: &helveticaWidths}
+	bold    = font{resource: "F2", widths: &helveticaBoldWidths}
+)
+
+// UnsupportedTextError is returned for text with a character the standard fonts cannot show.
+type UnsupportedTextError struct {
+	Text string
+	Char rune
+}
+
+func (e UnsupportedTextError) Error() string {
+	return fmt.Sprintf("%q in %q cannot be written to a PDF, only Latin characters are supported", e.Char, e.Text)
+}
+
+// Document lays out text top to bottom, starting a new page when the current one is full. The
+// first text that cannot be written is kept and returned by Bytes.
+type Document struct {
+	title string
+	pages []*bytes.Buffer
+	y     float64
+	err   error
+}
+
+func NewDocument(title string) *Document {
+	d := &Document{title: title}
+	d.newPage()
+	return d
+}
+
+// Heading writes a bold line in a larger size.
+func (d *Document) Heading(text string) {
+	d.lines(wrap(text, bold, headingSize, contentWidth), bold, headingSize, margin)
+	d.Space(headingSize / 2)
+}
+
+// Paragraph writes the text wrapped to the width of the page, line breaks in the text are kept.
+func (d *Document) Paragraph(text string) {
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			d.Space(bodySize * lineSpacing)
+			continue
+		}
+		d.lines(wrap(line, regular, bodySize, contentWidth), regular, bodySize, margin)
+	}
+}
+
+// Field writes a bold label with its value next to it, the value wraps within its own column.
+func (d *Document) Field(label string, value string) {
+	valueLines := wrap(value, regular, bodySize, contentWidth-labelWidth)
+	d.ensureSpace(bodySize * lineSpacing)
+	d.text(margin, d.y, bold, bodySize, label)
+	d.lines(valueLines, regular, bodySize, margin+labelWidth)
+}
+
+// Rule draws a horizontal line across the page.
+func (d *Document) Rule() {
+	d.ensureSpace(bodySize)
+	fmt.Fprintf(d.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", margin, d.y-bodySize/2, pageWidth-margin, d.y-bodySize/2)
+	d.Space(bodySize)
+}
+
+// Space moves the cursor down by the given points.
+func (d *Document) Space(points float64) {
+	d.y -= points
+}
+
+// Bytes returns the PDF file of the document, or an UnsupportedTextError when any of its text
+// cannot be written.
+func (d *Document) Bytes() ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	title, err := escape(d.title)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// objects 1 to 5 are the catalog, the page tree, both fonts and the info dictionary, every page
+	// is followed by its content stream
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (sample-web) >>", title))
+
+	for i, page := range d.pages {
+		var compressed bytes.Buffer
+		writer := zlib.NewWriter(&compressed)
+		if _, err := writer.Write(page.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 7+2*i))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes(), nil
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - margin
+}
+
+func (d *Document) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+func (d *Document) ensureSpace(height float64) {
+	if d.y-height < margin {
+		d.newPage()
+	}
+}
+
+func (d *Document) lines(lines []string, f font, size float64, x float64) {
+	for _, line := range lines {
+		d.ensureSpace(size * lineSpacing)
+		d.text(x, d.y, f, size, line)
+		d.Space(size * lineSpacing)
+	}
+}
+
+// text writes a line with its baseline one font size below y.
+func (d *Document) text(x float64, y float64, f font, size float64, text string) {
+	escaped, err := escape(text)
+	if err != nil {
+		if d.err == nil {
+			d.err = err
+		}
+		return
+	}
+	fmt.Fprintf(d.page(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", f.resource, size, x, y-size, escaped)
+}
+
+// wrap breaks the text into lines that fit the width, words longer than a line are split.
+func wrap(text string, f font, size float64, width float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if textWidth(candidate, f, size) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for textWidth(word, f, size) > width {
+			split := 1
+			for split < len([]rune(word)) && textWidth(string([]rune(word)[:split+1]), f, size) <= width {
+				split++
+			}
+			lines = append(lines, string([]rune(word)[:split]))
+			word = string([]rune(word)[split:])
+		}
+		line = word
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func textWidth(text string, f font, size float64) float64 {
+	width := 0
+	for _, r := range text {
+		if r >= 32 && r <= 126 {
+			width += f.widths[r-32]
+		} else {
+			width += 556
+		}
+	}
+	return float64(width) * size / 1000
+}
+
+// Writable reports whether the standard fonts can show every character of the text.
+func Writable(text string) bool {
+	_, err := escape(text)
+	return err == nil
+}
+
+// escape encodes the text as a WinAnsi string literal, it fails on characters WinAnsi does not have.
+func escape(text string) (string, error) {
+	var escaped strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r >= 32 && r <= 126:
+			escaped.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&escaped, "\\%03o", r)
+		case winAnsiCodes[r] != 0:
+			fmt.Fprintf(&escaped, "\\%03o", winAnsiCodes[r])
+		case r == '\t':
+			escaped.WriteByte(' ')
+		case r == '\r':
+		default:
+			return "", UnsupportedTextError{Text: text, Char: r}
+		}
+	}
+	return escaped.String(), nil
+}
+
+// winAnsiCodes maps the characters WinAnsi places between 128 and 159 to their code.
+var winAnsiCodes = map[rune]byte{
+	'€': 128, '‚': 130, 'ƒ': 131, '„': 132, '…': 133, '†': 134, '‡': 135, 'ˆ': 136,
+	'‰': 137, 'Š': 138, '‹': 139, 'Œ': 140, 'Ž': 142, '‘': 145, '’': 146, '“': 147,
+	'”': 148, '•': 149, '–': 150, '—': 151, '˜': 152, '™': 153, 'š': 154, '›': 155,
+	'œ': 156, 'ž': 158, 'Ÿ': 159,
+}
+
+// helveticaWidths and helveticaBoldWidths are the advance widths of the printable ASCII characters
+// in thousandths of the font size, taken from the Adobe font metrics.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
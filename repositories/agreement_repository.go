@@ -0,0 +1,404 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sample-web/models"
+	"sample-web/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AgreementRepository interface {
+	CreateTemplate(ctx context.Context, template models.AgreementTemplate) (models.AgreementTemplate, error)
+	FindTemplate(ctx context.Context, landLordId string, version int) (models.AgreementTemplate, error)
+	FindLatestTemplate(ctx context.Context, landLordId string) (models.AgreementTemplate, error)
+	GetTemplates(ctx context.Context, landLordId string) ([]models.AgreementTemplate, error)
+	CreateAgreement(ctx context.Context, agreement models.Agreement) (models.Agreement, error)
+	FindAgreementById(ctx context.Context, rentId string, agreementId string) (models.Agreement, error)
+	GetAgreementsByRent(ctx context.Context, rentId string) ([]models.Agreement, error)
+	FindAgreementByHash(ctx context.Context, hash string) (models.Agreement, error)
+	AddSignature(ctx context.Context, agreementId bson.ObjectID, signature models.AgreementSignature) (models.Agreement, error)
+	MarkSigned(ctx context.Context, agreementId bson.ObjectID, at time.Time) (bool, error)
+	SupersedePendingAgreements(ctx context.Context, rentId bson.ObjectID, at time.Time) (int64, error)
+}
+
+type agreementRepository struct {
+	db *mongo.Database
+}
+
+func NewAgreementRepository(db *mongo.Database) AgreementRepository {
+	return &agreementRepository{
+		db: db,
+	}
+}
+
+func (agreementRepository *agreementRepository) CreateTemplate(ctx context.Context, template models.AgreementTemplate) (models.AgreementTemplate, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementRepository.CreateTemplate")
+	defer span.End()
+
+	span.AddEvent("mongo.InsertOne", trace.WithAttributes(
+		attribute.String("collection", "agreement_templates"),
+		attribute.String("operation", "insert_one"),
+		attribute.String("landlord_id", template.LandLord.Id.Hex()),
+	))
+
+	templatesCollection := agreementRepository.db.Collection("agreement_templates")
+	result, err := templatesCollection.InsertOne(spanCtx, template)
+	if err != nil {
+		span.RecordError(err)
+		span.AddEvent("AgreementTemplateCreationFailed")
+		return models.AgreementTemplate{}, err
+	}
+
+	span.AddEvent("AgreementTemplateCreated")
+
+	template.Id = result.InsertedID.(bson.ObjectID)
+
+	log.Info(spanCtx, fmt.Sprintf("Agreement template version %d created with ID: %s", template.Version, template.Id.Hex()))
+
+	return template, nil
+}
+
+func (agreementRepository *agreementRepository) FindTemplate(ctx context.Context, landLordId string, version int) (models.AgreementTemplate, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementRepository.FindTemplate")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOne", trace.WithAttributes(
+		attribute.String("collection", "agreement_templates"),
+		attribute.String("operation", "find_one"),
+		attribute.String("landlord_id", landLordId),
+		attribute.Int("version", version),
+	))
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return models.AgreementTemplate{}, err
+	}
+
+	templatesCollection := agreementRepository.db.Collection("agreement_templates")
+
+	var template models.AgreementTemplate
+	err = templatesCollection.FindOne(spanCtx, bson.M{"landlord._id": landLordObjectId, "version": version}).Decode(&template)
+	if err != nil {
+		span.RecordError(err)
+		return models.AgreementTemplate{}, err
+	}
+
+	span.AddEvent("AgreementTemplateFound")
+
+	return template, nil
+}
+
+// FindLatestTemplate returns the highest version of the template of the landlord, it returns
+// mongo.ErrNoDocuments when the landlord has not saved a template yet.
+func (agreementRepository *agreementRepository) FindLatestTemplate(ctx context.Context, landLordId string) (models.AgreementTemplate, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementRepository.FindLatestTemplate")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOne", trace.WithAttributes(
+		attribute.String("collection", "agreement_templates"),
+		attribute.String("operation", "find_one"),
+		attribute.String("landlord_id", landLordId),
+	))
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return models.AgreementTemplate{}, err
+	}
+
+	templatesCollection := agreementRepository.db.Collection("agreement_templates")
+
+	var template models.AgreementTemplate
+	err = templatesCollection.FindOne(spanCtx,
+		bson.M{"landlord._id": landLordObjectId},
+		options.FindOne().SetSort(bson.M{"version": -1}),
+	).Decode(&template)
+	if err != nil {
+		span.RecordError(err)
+		return models.AgreementTemplate{}, err
+	}
+
+	span.AddEvent("AgreementTemplateFound")
+
+	return template, nil
+}
+
+func (agreementRepository *agreementRepository) GetTemplates(ctx context.Context, landLordId string) ([]models.AgreementTemplate, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementRepository.GetTemplates")
+	defer span.End()
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "agreement_templates"),
+		attribute.String("operation", "find"),
+		attribute.String("landlord_id", landLordId),
+	))
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	templatesCollection := agreementRepository.db.Collection("agreement_templates")
+
+	cursor, err := templatesCollection.Find(spanCtx, bson.M{"landlord._id": landLordObjectId}, options.Find().SetSort(bson.M{"version": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer cursor.Close(spanCtx)
+
+	var templates []models.AgreementTemplate
+	if err := cursor.All(spanCtx, &templates); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Found %d agreement templates", len(templates)))
+
+	span.AddEvent("AgreementTemplatesFound")
+	return templates, nil
+}
+
+func (agreementRepository *agreementRepository) CreateAgreement(ctx context.Context, agreement models.Agreement) (models.Agreement, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementRepository.CreateAgreement")
+	defer span.End()
+
+	span.AddEvent("mongo.InsertOne", trace.WithAttributes(
+		attribute.String("collection", "agreements"),
+		attribute.String("operation", "insert_one"),
+		attribute.String("rent_id", agreement.RentId.Hex()),
+	))
+
+	agreementsCollection := agreementRepository.db.Collection("agreements")
+	result, err := agreementsCollection.InsertOne(spanCtx, agreement)
+	if err != nil {
+		span.RecordError(err)
+		span.AddEvent("AgreementCreationFailed")
+		return models.Agreement{}, err
+	}
+
+	span.AddEvent("AgreementCreated")
+
+	id := result.InsertedID.(bson.ObjectID).Hex()
+
+	log.Info(spanCtx, fmt.Sprintf("Agreement created with ID: %s", id))
+
+	return agreementRepository.FindAgreementById(spanCtx, agreement.RentId.Hex(), id)
+}
+
+func (agreementRepository *agreementRepository) FindAgreementById(ctx context.Context, rentId string, agreementId string) (models.Agreement, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementRepository.FindAgreementById")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOne", trace.WithAttributes(
+		attribute.String("collection", "agreements"),
+		attribute.String("operation", "find_one"),
+		attribute.String("_id", agreementId),
+	))
+
+	agreementObjectId, err := bson.ObjectIDFromHex(agreementId)
+	if err != nil {
+		span.RecordError(err)
+		return models.Agreement{}, err
+	}
+
+	rentObjectId, err := bson.ObjectIDFromHex(rentId)
+	if err != nil {
+		span.RecordError(err)
+		return models.Agreement{}, err
+	}
+
+	agreementsCollection := agreementRepository.db.Collection("agreements")
+
+	var agreement models.Agreement
+	err = agreementsCollection.FindOne(spanCtx, bson.M{"_id": agreementObjectId, "rent_id": rentObjectId}).Decode(&agreement)
+	if err != nil {
+		span.RecordError(err)
+		return models.Agreement{}, err
+	}
+
+	span.AddEvent("AgreementFound")
+
+	return agreement, nil
+}
+
+// GetAgreementsByRent returns the agreements of the rent, newest first.
+func (agreementRepository *agreementRepository) GetAgreementsByRent(ctx context.Context, rentId string) ([]models.Agreement, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementRepository.GetAgreementsByRent")
+	defer span.End()
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "agreements"),
+		attribute.String("operation", "find"),
+		attribute.String("rent_id", rentId),
+	))
+
+	rentObjectId, err := bson.ObjectIDFromHex(rentId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	agreementsCollection := agreementRepository.db.Collection("agreements")
+
+	cursor, err := agreementsCollection.Find(spanCtx, bson.M{"rent_id": rentObjectId}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer cursor.Close(spanCtx)
+
+	var agreements []models.Agreement
+	if err := cursor.All(spanCtx, &agreements); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Found %d agreements", len(agreements)))
+
+	span.AddEvent("AgreementsFound")
+	return agreements, nil
+}
+
+func (agreementRepository *agreementRepository) FindAgreementByHash(ctx context.Context, hash string) (models.Agreement, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementRepository.FindAgreementByHash")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOne", trace.WithAttributes(
+		attribute.String("collection", "agreements"),
+		attribute.String("operation", "find_one"),
+		attribute.String("hash", hash),
+	))
+
+	agreementsCollection := agreementRepository.db.Collection("agreements")
+
+	var agreement models.Agreement
+	err := agreementsCollection.FindOne(spanCtx, bson.M{"hash": hash}).Decode(&agreement)
+	if err != nil {
+		span.RecordError(err)
+		return models.Agreement{}, err
+	}
+
+	span.AddEvent("AgreementFound")
+
+	return agreement, nil
+}
+
+// AddSignature adds the signature to an agreement that is waiting for signatures and was not signed
+// by the signer yet, it returns mongo.ErrNoDocuments otherwise.
+func (agreementRepository *agreementRepository) AddSignature(ctx context.Context, agreementId bson.ObjectID, signature models.AgreementSignature) (models.Agreement, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementRepository.AddSignature")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOneAndUpdate", trace.WithAttributes(
+		attribute.String("collection", "agreements"),
+		attribute.String("operation", "find_one_and_update"),
+		attribute.String("_id", agreementId.Hex()),
+	))
+
+	agreementsCollection := agreementRepository.db.Collection("agreements")
+
+	query := bson.M{
+		"_id":                   agreementId,
+		"status":                models.AgreementStatusPendingSignatures,
+		"signatures.signer._id": bson.M{"$ne": signature.Signer.Id},
+	}
+
+	var agreement models.Agreement
+	err := agreementsCollection.FindOneAndUpdate(spanCtx, query,
+		bson.M{"$push": bson.M{"signatures": signature}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&agreement)
+	if err != nil {
+		span.RecordError(err)
+		return models.Agreement{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Agreement %s signed by %s", agreementId.Hex(), signature.Signer.Id.Hex()))
+
+	span.AddEvent("AgreementSignatureAdded")
+	return agreement, nil
+}
+
+// MarkSigned completes an agreement that is waiting for signatures, it reports false when another
+// request completed it first.
+func (agreementRepository *agreementRepository) MarkSigned(ctx context.Context, agreementId bson.ObjectID, at time.Time) (bool, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementRepository.MarkSigned")
+	defer span.End()
+
+	span.AddEvent("mongo.UpdateOne", trace.WithAttributes(
+		attribute.String("collection", "agreements"),
+		attribute.String("operation", "update_one"),
+		attribute.String("_id", agreementId.Hex()),
+	))
+
+	agreementsCollection := agreementRepository.db.Collection("agreements")
+
+	result, err := agreementsCollection.UpdateOne(spanCtx,
+		bson.M{"_id": agreementId, "status": models.AgreementStatusPendingSignatures},
+		bson.M{"$set": bson.M{"status": models.AgreementStatusSigned, "signed_at": at}},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// SupersedePendingAgreements marks the agreements of the rent that are still waiting for signatures
+// superseded so they can no longer be signed, it returns how many were superseded.
+func (agreementRepository *agreementRepository) SupersedePendingAgreements(ctx context.Context, rentId bson.ObjectID, at time.Time) (int64, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementRepository.SupersedePendingAgreements")
+	defer span.End()
+
+	span.AddEvent("mongo.UpdateMany", trace.WithAttributes(
+		attribute.String("collection", "agreements"),
+		attribute.String("operation", "update_many"),
+		attribute.String("rent_id", rentId.Hex()),
+	))
+
+	agreementsCollection := agreementRepository.db.Collection("agreements")
+
+	result, err := agreementsCollection.UpdateMany(spanCtx,
+		bson.M{"rent_id": rentId, "status": models.AgreementStatusPendingSignatures},
+		bson.M{"$set": bson.M{"status": models.AgreementStatusSuperseded, "superseded_at": at}},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sample-web/utils"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CounterRepository hands out increasing sequence numbers, like the receipt numbers of a landlord.
+type CounterRepository interface {
+	NextValue(ctx context.Context, name string) (int64, error)
+}
+
+type counterRepository struct {
+	db *mongo.Database
+}
+
+func NewCounterRepository(db *mongo.Database) CounterRepository {
+	return &counterRepository{
+		db: db,
+	}
+}
+
+type counter struct {
+	Name  string `bson:"_id"`
+	Value int64  `bson:"value"`
+}
+
+// NextValue increments the named counter and returns its new value, the first value is one.
+func (counterRepository *counterRepository) NextValue(ctx context.Context, name string) (int64, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "CounterRepository.NextValue")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOneAndUpdate", trace.WithAttributes(
+		attribute.String("collection", "counters"),
+		attribute.String("operation", "find_one_and_update"),
+		attribute.String("_id", name),
+	))
+
+	countersCollection := counterRepository.db.Collection("counters")
+
+	var next counter
+	err := countersCollection.FindOneAndUpdate(spanCtx,
+		bson.M{"_id": name},
+		bson.M{"$inc": bson.M{"value": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&next)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Counter %s incremented to %d", name, next.Value))
+
+	return next.Value, nil
+}
@@ -0,0 +1,230 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sample-web/models"
+	"sample-web/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type DashboardRepository interface {
+	GetLandLordRecordStats(ctx context.Context, landLordId string, from time.Time, to time.Time, pendingLimit int) (models.LandLordRecordStats, error)
+	GetTenantRecordStats(ctx context.Context, tenantId string) (models.TenantRecordStats, error)
+}
+
+type dashboardRepository struct {
+	db *mongo.Database
+}
+
+func NewDashboardRepository(db *mongo.Database) DashboardRepository {
+	return &dashboardRepository{
+		db: db,
+	}
+}
+
+// GetLandLordRecordStats aggregates the rent records of a landlord in a single pass. The leading
+// $match uses the landlord_id index, the facets then split the records into the income per day
+// between from and to, the oldest pendingLimit records waiting for approval along with the totals
+// per day of all of them, and the approved amount per rent.
+func (dashboardRepository *dashboardRepository) GetLandLordRecordStats(ctx context.Context, landLordId string, from time.Time, to time.Time, pendingLimit int) (models.LandLordRecordStats, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DashboardRepository.GetLandLordRecordStats")
+	defer span.End()
+
+	span.AddEvent("mongo.Aggregate", trace.WithAttributes(
+		attribute.String("collection", "rent_records"),
+		attribute.String("operation", "aggregate"),
+		attribute.String("landlord_id", landLordId),
+	))
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return models.LandLordRecordStats{}, err
+	}
+
+	rentRecordCollection := dashboardRepository.db.Collection("rent_records")
+
+	// income is counted on the day it was paid, records without a payment date were paid when submitted
+	paidAt := bson.M{"$ifNull": bson.A{"$paid_at", "$submitted_at"}}
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{
+			"landlord._id": landLordObjectId,
+			"status": bson.M{"$in": bson.A{
+				models.RentRecordStatusApproved,
+				models.RentRecordStatusPending,
+			}},
+		}},
+		bson.M{"$facet": bson.M{
+			"daily_income": bson.A{
+				bson.M{"$match": bson.M{
+					"status": models.RentRecordStatusApproved,
+					"$expr": bson.M{"$and": bson.A{
+						bson.M{"$gte": bson.A{paidAt, from}},
+						bson.M{"$lt": bson.A{paidAt, to}},
+					}},
+				}},
+				bson.M{"$group": bson.M{
+					"_id": bson.M{
+						"date":     bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": paidAt}},
+						"currency": "$amount.currency",
+					},
+					"minor":    bson.M{"$sum": settledMinor},
+					"payments": bson.M{"$sum": 1},
+				}},
+				bson.M{"$project": bson.M{
+					"_id":      0,
+					"date":     "$_id.date",
+					"amount":   bson.M{"minor": "$minor", "currency": "$_id.currency"},
+					"payments": 1,
+				}},
+				bson.M{"$sort": bson.D{{Key: "date", Value: 1}, {Key: "amount.currency", Value: 1}}},
+			},
+			"pending_approvals": bson.A{
+				bson.M{"$match": bson.M{"status": models.RentRecordStatusPending}},
+				bson.M{"$sort": bson.D{{Key: "submitted_at", Value: 1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": pendingLimit},
+				bson.M{"$project": bson.M{
+					"rent_id":        1,
+					"tenant":         1,
+					"amount":         1,
+					"payment_method": 1,
+					"reference":      1,
+					"paid_at":        1,
+					"submitted_at":   1,
+				}},
+			},
+			"pending_by_day": bson.A{
+				bson.M{"$match": bson.M{"status": models.RentRecordStatusPending}},
+				bson.M{"$group": bson.M{
+					"_id": bson.M{
+						"date":     bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": paidAt}},
+						"currency": "$amount.currency",
+					},
+					"minor":    bson.M{"$sum": "$amount.minor"},
+					"payments": bson.M{"$sum": 1},
+				}},
+				bson.M{"$project": bson.M{
+					"_id":      0,
+					"date":     "$_id.date",
+					"amount":   bson.M{"minor": "$minor", "currency": "$_id.currency"},
+					"payments": 1,
+				}},
+			},
+			"approved_by_rent": bson.A{
+				bson.M{"$match": bson.M{"status": models.RentRecordStatusApproved}},
+				bson.M{"$group": bson.M{
+					"_id":      "$rent_id",
+					"approved": bson.M{"$sum": settledMinor},
+				}},
+			},
+		}},
+	}
+
+	cursor, err := rentRecordCollection.Aggregate(spanCtx, pipeline)
+	if err != nil {
+		span.RecordError(err)
+		return models.LandLordRecordStats{}, err
+	}
+	defer cursor.Close(spanCtx)
+
+	var stats models.LandLordRecordStats
+
+	// $facet always returns a single document
+	if cursor.Next(spanCtx) {
+		if err := cursor.Decode(&stats); err != nil {
+			span.RecordError(err)
+			return models.LandLordRecordStats{}, err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		span.RecordError(err)
+		return models.LandLordRecordStats{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Aggregated %d days of income and %d days of pending approvals", len(stats.DailyIncome), len(stats.PendingByDay)))
+
+	return stats, nil
+}
+
+// GetTenantRecordStats aggregates the rent records a tenant submitted across all their rents, the
+// facets split them into the totals per rent and the payment history with the latest record first.
+func (dashboardRepository *dashboardRepository) GetTenantRecordStats(ctx context.Context, tenantId string) (models.TenantRecordStats, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DashboardRepository.GetTenantRecordStats")
+	defer span.End()
+
+	span.AddEvent("mongo.Aggregate", trace.WithAttributes(
+		attribute.String("collection", "rent_records"),
+		attribute.String("operation", "aggregate"),
+		attribute.String("tenant_id", tenantId),
+	))
+
+	tenantObjectId, err := bson.ObjectIDFromHex(tenantId)
+	if err != nil {
+		span.RecordError(err)
+		return models.TenantRecordStats{}, err
+	}
+
+	rentRecordCollection := dashboardRepository.db.Collection("rent_records")
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"tenant._id": tenantObjectId}},
+		bson.M{"$facet": bson.M{
+			"totals_by_rent": bson.A{
+				bson.M{"$group": bson.M{
+					"_id": "$rent_id",
+					"approved": bson.M{"$sum": bson.M{"$cond": bson.A{
+						bson.M{"$eq": bson.A{"$status", models.RentRecordStatusApproved}}, settledMinor, 0,
+					}}},
+					"pending": bson.M{"$sum": bson.M{"$cond": bson.A{
+						bson.M{"$eq": bson.A{"$status", models.RentRecordStatusPending}}, "$amount.minor", 0,
+					}}},
+					// $$REMOVE leaves the records that do not pay an installment out of the set
+					"covered_due_dates": bson.M{"$addToSet": bson.M{"$cond": bson.A{
+						bson.M{"$in": bson.A{"$status", bson.A{models.RentRecordStatusApproved, models.RentRecordStatusPending}}}, "$due_date", "$$REMOVE",
+					}}},
+				}},
+			},
+			"history": bson.A{
+				bson.M{"$sort": bson.M{"submitted_at": -1}},
+			},
+		}},
+	}
+
+	cursor, err := rentRecordCollection.Aggregate(spanCtx, pipeline)
+	if err != nil {
+		span.RecordError(err)
+		return models.TenantRecordStats{}, err
+	}
+	defer cursor.Close(spanCtx)
+
+	var stats models.TenantRecordStats
+
+	// $facet always returns a single document
+	if cursor.Next(spanCtx) {
+		if err := cursor.Decode(&stats); err != nil {
+			span.RecordError(err)
+			return models.TenantRecordStats{}, err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		span.RecordError(err)
+		return models.TenantRecordStats{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Aggregated %d rents and %d records for tenant", len(stats.TotalsByRent), len(stats.History)))
+
+	return stats, nil
+}
@@ -0,0 +1,255 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sample-web/models"
+	"sample-web/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type DisputeRepository interface {
+	CreateDispute(ctx context.Context, dispute models.Dispute) (models.Dispute, error)
+	FindDisputeById(ctx context.Context, userId string, disputeId string) (models.Dispute, error)
+	GetDisputes(ctx context.Context, userId string, userRole string, status string) ([]models.Dispute, error)
+	GetDisputesByRecord(ctx context.Context, rentRecordId bson.ObjectID) ([]models.Dispute, error)
+	AddMessage(ctx context.Context, disputeId bson.ObjectID, message models.DisputeMessage) (models.Dispute, error)
+	MarkUnderReview(ctx context.Context, disputeId bson.ObjectID, at time.Time) (models.Dispute, error)
+	Resolve(ctx context.Context, disputeId bson.ObjectID, outcome models.DisputeOutcome) (models.Dispute, error)
+}
+
+type disputeRepository struct {
+	db *mongo.Database
+}
+
+func NewDisputeRepository(db *mongo.Database) DisputeRepository {
+	return &disputeRepository{
+		db: db,
+	}
+}
+
+func (disputeRepository *disputeRepository) CreateDispute(ctx context.Context, dispute models.Dispute) (models.Dispute, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DisputeRepository.CreateDispute")
+	defer span.End()
+
+	span.AddEvent("mongo.InsertOne", trace.WithAttributes(
+		attribute.String("collection", "disputes"),
+		attribute.String("operation", "insert_one"),
+		attribute.String("rent_record_id", dispute.RentRecordId.Hex()),
+	))
+
+	disputesCollection := disputeRepository.db.Collection("disputes")
+	result, err := disputesCollection.InsertOne(spanCtx, dispute)
+	if err != nil {
+		span.RecordError(err)
+		span.AddEvent("DisputeCreationFailed")
+		return models.Dispute{}, err
+	}
+
+	span.AddEvent("DisputeCreated")
+
+	dispute.Id = result.InsertedID.(bson.ObjectID)
+
+	log.Info(spanCtx, fmt.Sprintf("Dispute created with ID: %s", dispute.Id.Hex()))
+
+	return dispute, nil
+}
+
+// FindDisputeById finds a dispute the user is the landlord or the tenant of.
+func (disputeRepository *disputeRepository) FindDisputeById(ctx context.Context, userId string, disputeId string) (models.Dispute, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DisputeRepository.FindDisputeById")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOne", trace.WithAttributes(
+		attribute.String("collection", "disputes"),
+		attribute.String("operation", "find_one"),
+		attribute.String("_id", disputeId),
+	))
+
+	disputeObjectId, err := bson.ObjectIDFromHex(disputeId)
+	if err != nil {
+		span.RecordError(err)
+		return models.Dispute{}, err
+	}
+
+	userObjectId, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		span.RecordError(err)
+		return models.Dispute{}, err
+	}
+
+	disputesCollection := disputeRepository.db.Collection("disputes")
+
+	query := bson.M{
+		"_id": disputeObjectId,
+		"$or": bson.A{
+			bson.M{"landlord._id": userObjectId},
+			bson.M{"tenant._id": userObjectId},
+		},
+	}
+
+	var dispute models.Dispute
+	if err := disputesCollection.FindOne(spanCtx, query).Decode(&dispute); err != nil {
+		span.RecordError(err)
+		return models.Dispute{}, err
+	}
+
+	span.AddEvent("DisputeFound")
+	return dispute, nil
+}
+
+// GetDisputes returns the disputes of the user as a landlord or as a tenant, newest first.
+func (disputeRepository *disputeRepository) GetDisputes(ctx context.Context, userId string, userRole string, status string) ([]models.Dispute, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DisputeRepository.GetDisputes")
+	defer span.End()
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "disputes"),
+		attribute.String("operation", "find"),
+		attribute.String("user_id", userId),
+		attribute.String("user_role", userRole),
+	))
+
+	userObjectId, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	query := bson.M{"tenant._id": userObjectId}
+	if userRole == string(models.LandLord) {
+		query = bson.M{"landlord._id": userObjectId}
+	}
+	if status != "" {
+		query["status"] = status
+	}
+
+	disputesCollection := disputeRepository.db.Collection("disputes")
+
+	cursor, err := disputesCollection.Find(spanCtx, query, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer cursor.Close(spanCtx)
+
+	disputes := []models.Dispute{}
+	if err := cursor.All(spanCtx, &disputes); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Found %d disputes", len(disputes)))
+
+	span.AddEvent("DisputesFound")
+	return disputes, nil
+}
+
+// GetDisputesByRecord returns the disputes of a rent record, newest first.
+func (disputeRepository *disputeRepository) GetDisputesByRecord(ctx context.Context, rentRecordId bson.ObjectID) ([]models.Dispute, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DisputeRepository.GetDisputesByRecord")
+	defer span.End()
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "disputes"),
+		attribute.String("operation", "find"),
+		attribute.String("rent_record_id", rentRecordId.Hex()),
+	))
+
+	disputesCollection := disputeRepository.db.Collection("disputes")
+
+	cursor, err := disputesCollection.Find(spanCtx, bson.M{"rent_record_id": rentRecordId}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer cursor.Close(spanCtx)
+
+	disputes := []models.Dispute{}
+	if err := cursor.All(spanCtx, &disputes); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Found %d disputes", len(disputes)))
+
+	span.AddEvent("DisputesFound")
+	return disputes, nil
+}
+
+// AddMessage adds the message to the thread of a dispute that is not resolved, it returns
+// mongo.ErrNoDocuments otherwise.
+func (disputeRepository *disputeRepository) AddMessage(ctx context.Context, disputeId bson.ObjectID, message models.DisputeMessage) (models.Dispute, error) {
+	return disputeRepository.update(ctx, "DisputeRepository.AddMessage", bson.M{
+		"_id":    disputeId,
+		"status": bson.M{"$ne": models.DisputeStatusResolved},
+	}, bson.M{
+		"$push": bson.M{"messages": message},
+		"$set":  bson.M{"updated_at": message.CreatedAt},
+	})
+}
+
+// MarkUnderReview moves an open dispute under review, it returns mongo.ErrNoDocuments when the
+// dispute is not open.
+func (disputeRepository *disputeRepository) MarkUnderReview(ctx context.Context, disputeId bson.ObjectID, at time.Time) (models.Dispute, error) {
+	return disputeRepository.update(ctx, "DisputeRepository.MarkUnderReview", bson.M{
+		"_id":    disputeId,
+		"status": models.DisputeStatusOpen,
+	}, bson.M{
+		"$set": bson.M{"status": models.DisputeStatusUnderReview, "updated_at": at},
+	})
+}
+
+// Resolve stores the outcome of a dispute that is not resolved, it returns mongo.ErrNoDocuments
+// otherwise.
+func (disputeRepository *disputeRepository) Resolve(ctx context.Context, disputeId bson.ObjectID, outcome models.DisputeOutcome) (models.Dispute, error) {
+	return disputeRepository.update(ctx, "DisputeRepository.Resolve", bson.M{
+		"_id":    disputeId,
+		"status": bson.M{"$ne": models.DisputeStatusResolved},
+	}, bson.M{
+		"$set": bson.M{"status": models.DisputeStatusResolved, "outcome": outcome, "updated_at": outcome.ResolvedAt},
+	})
+}
+
+func (disputeRepository *disputeRepository) update(ctx context.Context, spanName string, query bson.M, update bson.M) (models.Dispute, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, spanName)
+	defer span.End()
+
+	span.AddEvent("mongo.FindOneAndUpdate", trace.WithAttributes(
+		attribute.String("collection", "disputes"),
+		attribute.String("operation", "find_one_and_update"),
+		attribute.String("_id", query["_id"].(bson.ObjectID).Hex()),
+	))
+
+	disputesCollection := disputeRepository.db.Collection("disputes")
+
+	var dispute models.Dispute
+	err := disputesCollection.FindOneAndUpdate(spanCtx, query, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&dispute)
+	if err != nil {
+		span.RecordError(err)
+		return models.Dispute{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Dispute %s updated, status is %s", dispute.Id.Hex(), dispute.Status))
+
+	span.AddEvent("DisputeUpdated")
+	return dispute, nil
+}
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sample-web/models"
+	"sample-web/utils"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ExchangeRateRepository interface {
+	CreateRate(ctx context.Context, rate models.ExchangeRate) (models.ExchangeRate, error)
+	FindRateById(ctx context.Context, rateId string) (models.ExchangeRate, error)
+	GetRates(ctx context.Context, currency string) ([]models.ExchangeRate, error)
+}
+
+type exchangeRateRepository struct {
+	db *mongo.Database
+}
+
+func NewExchangeRateRepository(db *mongo.Database) ExchangeRateRepository {
+	return &exchangeRateRepository{
+		db: db,
+	}
+}
+
+func (exchangeRateRepository *exchangeRateRepository) CreateRate(ctx context.Context, rate models.ExchangeRate) (models.ExchangeRate, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "ExchangeRateRepository.CreateRate")
+	defer span.End()
+
+	span.AddEvent("mongo.InsertOne", trace.WithAttributes(
+		attribute.String("collection", "exchange_rates"),
+		attribute.String("operation", "insert_one"),
+		attribute.String("base_currency", rate.BaseCurrency),
+		attribute.String("quote_currency", rate.QuoteCurrency),
+	))
+
+	ratesCollection := exchangeRateRepository.db.Collection("exchange_rates")
+	result, err := ratesCollection.InsertOne(spanCtx, rate)
+	if err != nil {
+		span.RecordError(err)
+		span.AddEvent("ExchangeRateCreationFailed")
+		return models.ExchangeRate{}, err
+	}
+
+	span.AddEvent("ExchangeRateCreated")
+
+	id := result.InsertedID.(bson.ObjectID).Hex()
+
+	log.Info(spanCtx, fmt.Sprintf("Exchange rate created with ID: %s", id))
+
+	return exchangeRateRepository.FindRateById(spanCtx, id)
+}
+
+func (exchangeRateRepository *exchangeRateRepository) FindRateById(ctx context.Context, rateId string) (models.ExchangeRate, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "ExchangeRateRepository.FindRateById")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOne", trace.WithAttributes(
+		attribute.String("collection", "exchange_rates"),
+		attribute.String("operation", "find_one"),
+		attribute.String("_id", rateId),
+	))
+
+	rateObjectId, err := bson.ObjectIDFromHex(rateId)
+	if err != nil {
+		span.RecordError(err)
+		return models.ExchangeRate{}, err
+	}
+
+	ratesCollection := exchangeRateRepository.db.Collection("exchange_rates")
+
+	var rate models.ExchangeRate
+	err = ratesCollection.FindOne(spanCtx, bson.M{"_id": rateObjectId}).Decode(&rate)
+	if err != nil {
+		span.RecordError(err)
+		return models.ExchangeRate{}, err
+	}
+
+	span.AddEvent("ExchangeRateFound")
+
+	return rate, nil
+}
+
+// GetRates returns the rates converting from or to the currency, or every rate when the currency is
+// empty, oldest first.
+func (exchangeRateRepository *exchangeRateRepository) GetRates(ctx context.Context, currency string) ([]models.ExchangeRate, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "ExchangeRateRepository.GetRates")
+	defer span.End()
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "exchange_rates"),
+		attribute.String("operation", "find"),
+		attribute.String("currency", currency),
+	))
+
+	query := bson.M{}
+	if currency != "" {
+		query["$or"] = bson.A{
+			bson.M{"base_currency": currency},
+			bson.M{"quote_currency": currency},
+		}
+	}
+
+	ratesCollection := exchangeRateRepository.db.Collection("exchange_rates")
+
+	cursor, err := ratesCollection.Find(spanCtx, query, options.Find().SetSort(bson.M{"effective_from": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer cursor.Close(spanCtx)
+
+	var rates []models.ExchangeRate
+	if err := cursor.All(spanCtx, &rates); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Found %d exchange rates", len(rates)))
+
+	span.AddEvent("ExchangeRatesFound")
+	return rates, nil
+}
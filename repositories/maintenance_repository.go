@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sample-web/models"
+	"sample-web/utils"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type MaintenanceRepository interface {
+	CreateMaintenanceRequest(ctx context.Context, request models.MaintenanceRequest) (models.MaintenanceRequest, error)
+	FindMaintenanceRequestById(ctx context.Context, rentId bson.ObjectID, requestId string) (models.MaintenanceRequest, error)
+	GetMaintenanceRequests(ctx context.Context, userId string, userRole string, status string) ([]models.MaintenanceRequest, error)
+	GetRentMaintenanceRequests(ctx context.Context, rentId bson.ObjectID, status string) ([]models.MaintenanceRequest, error)
+	UpdateStatus(ctx context.Context, requestId bson.ObjectID, previousStatus models.MaintenanceStatus, change models.MaintenanceStatusChange) (models.MaintenanceRequest, error)
+	AddComment(ctx context.Context, requestId bson.ObjectID, comment models.MaintenanceComment) (models.MaintenanceRequest, error)
+	AddPhoto(ctx context.Context, requestId bson.ObjectID, photo models.Attachment, maxPhotos int) (models.MaintenanceRequest, error)
+}
+
+type maintenanceRepository struct {
+	db *mongo.Database
+}
+
+func NewMaintenanceRepository(db *mongo.Database) MaintenanceRepository {
+	return &maintenanceRepository{
+		db: db,
+	}
+}
+
+func (maintenanceRepository *maintenanceRepository) CreateMaintenanceRequest(ctx context.Context, request models.MaintenanceRequest) (models.MaintenanceRequest, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "MaintenanceRepository.CreateMaintenanceRequest")
+	defer span.End()
+
+	span.AddEvent("mongo.InsertOne", trace.WithAttributes(
+		attribute.String("collection", "maintenance_requests"),
+		attribute.String("operation", "insert_one"),
+		attribute.String("rent_id", request.RentId.Hex()),
+	))
+
+	maintenanceCollection := maintenanceRepository.db.Collection("maintenance_requests")
+	result, err := maintenanceCollection.InsertOne(spanCtx, request)
+	if err != nil {
+		span.RecordError(err)
+		span.AddEvent("MaintenanceRequestCreationFailed")
+		return models.MaintenanceRequest{}, err
+	}
+
+	span.AddEvent("MaintenanceRequestCreated")
+
+	request.Id = result.InsertedID.(bson.ObjectID)
+
+	log.Info(spanCtx, fmt.Sprintf("Maintenance request created with ID: %s", request.Id.Hex()))
+
+	return request, nil
+}
+
+// FindMaintenanceRequestById finds a request of the rent, the caller checks the user can access the rent.
+func (maintenanceRepository *maintenanceRepository) FindMaintenanceRequestById(ctx context.Context, rentId bson.ObjectID, requestId string) (models.MaintenanceRequest, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "MaintenanceRepository.FindMaintenanceRequestById")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOne", trace.WithAttributes(
+		attribute.String("collection", "maintenance_requests"),
+		attribute.String("operation", "find_one"),
+		attribute.String("_id", requestId),
+	))
+
+	requestObjectId, err := bson.ObjectIDFromHex(requestId)
+	if err != nil {
+		span.RecordError(err)
+		return models.MaintenanceRequest{}, mongo.ErrNoDocuments
+	}
+
+	maintenanceCollection := maintenanceRepository.db.Collection("maintenance_requests")
+
+	var request models.MaintenanceRequest
+	if err := maintenanceCollection.FindOne(spanCtx, bson.M{"_id": requestObjectId, "rent_id": rentId}).Decode(&request); err != nil {
+		span.RecordError(err)
+		log.Error(spanCtx, fmt.Sprintf("Error finding maintenance request %s: %v", requestId, err))
+		return models.MaintenanceRequest{}, err
+	}
+
+	span.AddEvent("MaintenanceRequestFound")
+	return request, nil
+}
+
+// GetMaintenanceRequests returns the requests of the user as a landlord or as a tenant, newest first.
+func (maintenanceRepository *maintenanceRepository) GetMaintenanceRequests(ctx context.Context, userId string, userRole string, status string) ([]models.MaintenanceRequest, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "MaintenanceRepository.GetMaintenanceRequests")
+	defer span.End()
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "maintenance_requests"),
+		attribute.String("operation", "find"),
+		attribute.String("user_id", userId),
+		attribute.String("user_role", userRole),
+	))
+
+	userObjectId, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	query := bson.M{"tenant._id": userObjectId}
+	if userRole == string(models.LandLord) {
+		query = bson.M{"landlord._id": userObjectId}
+	}
+
+	return maintenanceRepository.find(spanCtx, query, status)
+}
+
+// GetRentMaintenanceRequests returns the requests of every co-tenant of the rent, newest first.
+func (maintenanceRepository *maintenanceRepository) GetRentMaintenanceRequests(ctx context.Context, rentId bson.ObjectID, status string) ([]models.MaintenanceRequest, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "MaintenanceRepository.GetRentMaintenanceRequests")
+	defer span.End()
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "maintenance_requests"),
+		attribute.String("operation", "find"),
+		attribute.String("rent_id", rentId.Hex()),
+	))
+
+	return maintenanceRepository.find(spanCtx, bson.M{"rent_id": rentId}, status)
+}
+
+// UpdateStatus moves a request that is still in previousStatus, it returns mongo.ErrNoDocuments when
+// the request was moved in the meantime.
+func (maintenanceRepository *maintenanceRepository) UpdateStatus(ctx context.Context, requestId bson.ObjectID, previousStatus models.MaintenanceStatus, change models.MaintenanceStatusChange) (models.MaintenanceRequest, error) {
+	return maintenanceRepository.update(ctx, "MaintenanceRepository.UpdateStatus", bson.M{
+		"_id":    requestId,
+		"status": previousStatus,
+	}, bson.M{
+		"$set":  bson.M{"status": change.Status, "updated_at": change.ChangedAt},
+		"$push": bson.M{"status_history": change},
+	})
+}
+
+// AddComment adds the comment to a request that is not resolved, it returns mongo.ErrNoDocuments
+// otherwise.
+func (maintenanceRepository *maintenanceRepository) AddComment(ctx context.Context, requestId bson.ObjectID, comment models.MaintenanceComment) (models.MaintenanceRequest, error) {
+	return maintenanceRepository.update(ctx, "MaintenanceRepository.AddComment", bson.M{
+		"_id":    requestId,
+		"status": bson.M{"$ne": models.MaintenanceStatusResolved},
+	}, bson.M{
+		"$push": bson.M{"comments": comment},
+		"$set":  bson.M{"updated_at": comment.CreatedAt},
+	})
+}
+
+// AddPhoto adds the photo to a request that is not resolved and has fewer than maxPhotos photos, it
+// returns mongo.ErrNoDocuments otherwise.
+func (maintenanceRepository *maintenanceRepository) AddPhoto(ctx context.Context, requestId bson.ObjectID, photo models.Attachment, maxPhotos int) (models.MaintenanceRequest, error) {
+	// the request matches only while the last allowed position is free
+	return maintenanceRepository.update(ctx, "MaintenanceRepository.AddPhoto", bson.M{
+		"_id":                                 requestId,
+		"status":                              bson.M{"$ne": models.MaintenanceStatusResolved},
+		fmt.Sprintf("photos.%d", maxPhotos-1): bson.M{"$exists": false},
+	}, bson.M{
+		"$push": bson.M{"photos": photo},
+		"$set":  bson.M{"updated_at": photo.UploadedAt},
+	})
+}
+
+func (maintenanceRepository *maintenanceRepository) find(ctx context.Context, query bson.M, status string) ([]models.MaintenanceRequest, error) {
+
+	log := utils.GetLogger()
+
+	if status != "" {
+		query["status"] = status
+	}
+
+	maintenanceCollection := maintenanceRepository.db.Collection("maintenance_requests")
+
+	cursor, err := maintenanceCollection.Find(ctx, query, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	requests := []models.MaintenanceRequest{}
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, err
+	}
+
+	log.Info(ctx, fmt.Sprintf("Found %d maintenance requests", len(requests)))
+
+	return requests, nil
+}
+
+func (maintenanceRepository *maintenanceRepository) update(ctx context.Context, spanName string, query bson.M, update bson.M) (models.MaintenanceRequest, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, spanName)
+	defer span.End()
+
+	span.AddEvent("mongo.FindOneAndUpdate", trace.WithAttributes(
+		attribute.String("collection", "maintenance_requests"),
+		attribute.String("operation", "find_one_and_update"),
+		attribute.String("_id", query["_id"].(bson.ObjectID).Hex()),
+	))
+
+	maintenanceCollection := maintenanceRepository.db.Collection("maintenance_requests")
+
+	var request models.MaintenanceRequest
+	err := maintenanceCollection.FindOneAndUpdate(spanCtx, query, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&request)
+	if err != nil {
+		span.RecordError(err)
+		return models.MaintenanceRequest{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Maintenance request %s updated, status is %s", request.Id.Hex(), request.Status))
+
+	span.AddEvent("MaintenanceRequestUpdated")
+	return request, nil
+}
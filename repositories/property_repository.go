@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sample-web/models"
+	"sample-web/utils"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PropertyRepository interface {
+	CreateProperty(ctx context.Context, property models.Property) (models.Property, error)
+	FindPropertyById(ctx context.Context, landLordId string, propertyId string) (models.Property, error)
+	GetAllProperties(ctx context.Context, landLordId string) ([]models.Property, error)
+	UpdateProperty(ctx context.Context, landLordId string, property models.Property) (models.Property, error)
+	DeleteProperty(ctx context.Context, landLordId string, propertyId string) error
+}
+
+type propertyRepository struct {
+	db *mongo.Database
+}
+
+func NewPropertyRepository(db *mongo.Database) PropertyRepository {
+	return &propertyRepository{
+		db: db,
+	}
+}
+
+func (propertyRepository *propertyRepository) CreateProperty(ctx context.Context, property models.Property) (models.Property, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "PropertyRepository.CreateProperty")
+	defer span.End()
+
+	span.AddEvent("mongo.InsertOne", trace.WithAttributes(
+		attribute.String("collection", "properties"),
+		attribute.String("operation", "insert_one"),
+	))
+
+	propertiesCollection := propertyRepository.db.Collection("properties")
+	result, err := propertiesCollection.InsertOne(spanCtx, property)
+	if err != nil {
+		span.RecordError(err)
+		span.AddEvent("PropertyCreationFailed")
+		return models.Property{}, err
+	}
+
+	span.AddEvent("PropertyCreated")
+
+	id := result.InsertedID.(bson.ObjectID).Hex()
+
+	log.Info(spanCtx, fmt.Sprintf("Property created with ID: %s", id))
+
+	return propertyRepository.FindPropertyById(spanCtx, property.LandLord.Id.Hex(), id)
+}
+
+func (propertyRepository *propertyRepository) FindPropertyById(ctx context.Context, landLordId string, propertyId string) (models.Property, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "PropertyRepository.FindPropertyById")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOne", trace.WithAttributes(
+		attribute.String("collection", "properties"),
+		attribute.String("operation", "find_one"),
+		attribute.String("_id", propertyId),
+	))
+
+	propertyObjectId, err := bson.ObjectIDFromHex(propertyId)
+	if err != nil {
+		span.RecordError(err)
+		return models.Property{}, err
+	}
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return models.Property{}, err
+	}
+
+	propertiesCollection := propertyRepository.db.Collection("properties")
+
+	var property models.Property
+	err = propertiesCollection.FindOne(spanCtx, bson.M{"_id": propertyObjectId, "landlord._id": landLordObjectId}).Decode(&property)
+	if err != nil {
+		span.RecordError(err)
+		return models.Property{}, err
+	}
+
+	span.AddEvent("PropertyFound")
+
+	return property, nil
+}
+
+func (propertyRepository *propertyRepository) GetAllProperties(ctx context.Context, landLordId string) ([]models.Property, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "PropertyRepository.GetAllProperties")
+	defer span.End()
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "properties"),
+		attribute.String("operation", "find"),
+		attribute.String("landlord_id", landLordId),
+	))
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	propertiesCollection := propertyRepository.db.Collection("properties")
+
+	cursor, err := propertiesCollection.Find(spanCtx, bson.M{"landlord._id": landLordObjectId})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer cursor.Close(spanCtx)
+
+	var properties []models.Property
+	if err := cursor.All(spanCtx, &properties); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Found %d properties", len(properties)))
+
+	span.AddEvent("PropertiesFound")
+	return properties, nil
+}
+
+func (propertyRepository *propertyRepository) UpdateProperty(ctx context.Context, landLordId string, property models.Property) (models.Property, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "PropertyRepository.UpdateProperty")
+	defer span.End()
+
+	span.AddEvent("mongo.UpdateOne", trace.WithAttributes(
+		attribute.String("collection", "properties"),
+		attribute.String("operation", "update_one"),
+		attribute.String("_id", property.Id.Hex()),
+	))
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return models.Property{}, err
+	}
+
+	propertiesCollection := propertyRepository.db.Collection("properties")
+
+	query := bson.M{
+		"_id":          property.Id,
+		"landlord._id": landLordObjectId,
+	}
+
+	_, err = propertiesCollection.UpdateOne(spanCtx, query, bson.M{"$set": property})
+	if err != nil {
+		span.RecordError(err)
+		return models.Property{}, err
+	}
+	return propertyRepository.FindPropertyById(spanCtx, landLordId, property.Id.Hex())
+}
+
+func (propertyRepository *propertyRepository) DeleteProperty(ctx context.Context, landLordId string, propertyId string) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "PropertyRepository.DeleteProperty")
+	defer span.End()
+
+	span.AddEvent("mongo.DeleteOne", trace.WithAttributes(
+		attribute.String("collection", "properties"),
+		attribute.String("operation", "delete_one"),
+		attribute.String("_id", propertyId),
+	))
+
+	propertyObjectId, err := bson.ObjectIDFromHex(propertyId)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	propertiesCollection := propertyRepository.db.Collection("properties")
+
+	result, err := propertiesCollection.DeleteOne(spanCtx, bson.M{"_id": propertyObjectId, "landlord._id": landLordObjectId})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Property deleted with ID: %s", propertyId))
+
+	return nil
+}
@@ -0,0 +1,288 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sample-web/models"
+	"sample-web/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type RentAmendmentRepository interface {
+	CreateAmendment(ctx context.Context, amendment models.RentAmendment) (models.RentAmendment, error)
+	FindAmendmentById(ctx context.Context, rentId string, amendmentId string) (models.RentAmendment, error)
+	FindPendingAmendment(ctx context.Context, rentId string) (models.RentAmendment, error)
+	GetAmendmentsByRent(ctx context.Context, rentId string) ([]models.RentAmendment, error)
+	CountAmendments(ctx context.Context, rentId string) (int64, error)
+	AddAcknowledgement(ctx context.Context, amendmentId string, acknowledgement models.RentAcknowledgement) (models.RentAmendment, error)
+	MarkApplied(ctx context.Context, amendmentId string, appliedAt time.Time) error
+}
+
+type rentAmendmentRepository struct {
+	db *mongo.Database
+}
+
+func NewRentAmendmentRepository(db *mongo.Database) RentAmendmentRepository {
+	return &rentAmendmentRepository{
+		db: db,
+	}
+}
+
+func (rentAmendmentRepository *rentAmendmentRepository) CreateAmendment(ctx context.Context, amendment models.RentAmendment) (models.RentAmendment, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentAmendmentRepository.CreateAmendment")
+	defer span.End()
+
+	span.AddEvent("mongo.InsertOne", trace.WithAttributes(
+		attribute.String("collection", "rent_amendments"),
+		attribute.String("operation", "insert_one"),
+		attribute.String("rent_id", amendment.RentId.Hex()),
+	))
+
+	amendmentsCollection := rentAmendmentRepository.db.Collection("rent_amendments")
+	result, err := amendmentsCollection.InsertOne(spanCtx, amendment)
+	if err != nil {
+		span.RecordError(err)
+		span.AddEvent("AmendmentCreationFailed")
+		return models.RentAmendment{}, err
+	}
+
+	span.AddEvent("AmendmentCreated")
+
+	id := result.InsertedID.(bson.ObjectID).Hex()
+
+	log.Info(spanCtx, fmt.Sprintf("Rent amendment created with ID: %s", id))
+
+	return rentAmendmentRepository.FindAmendmentById(spanCtx, amendment.RentId.Hex(), id)
+}
+
+func (rentAmendmentRepository *rentAmendmentRepository) FindAmendmentById(ctx context.Context, rentId string, amendmentId string) (models.RentAmendment, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentAmendmentRepository.FindAmendmentById")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOne", trace.WithAttributes(
+		attribute.String("collection", "rent_amendments"),
+		attribute.String("operation", "find_one"),
+		attribute.String("_id", amendmentId),
+	))
+
+	amendmentObjectId, err := bson.ObjectIDFromHex(amendmentId)
+	if err != nil {
+		span.RecordError(err)
+		return models.RentAmendment{}, err
+	}
+
+	rentObjectId, err := bson.ObjectIDFromHex(rentId)
+	if err != nil {
+		span.RecordError(err)
+		return models.RentAmendment{}, err
+	}
+
+	amendmentsCollection := rentAmendmentRepository.db.Collection("rent_amendments")
+
+	var amendment models.RentAmendment
+	err = amendmentsCollection.FindOne(spanCtx, bson.M{"_id": amendmentObjectId, "rent_id": rentObjectId}).Decode(&amendment)
+	if err != nil {
+		span.RecordError(err)
+		return models.RentAmendment{}, err
+	}
+
+	span.AddEvent("AmendmentFound")
+
+	return amendment, nil
+}
+
+func (rentAmendmentRepository *rentAmendmentRepository) FindPendingAmendment(ctx context.Context, rentId string) (models.RentAmendment, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentAmendmentRepository.FindPendingAmendment")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOne", trace.WithAttributes(
+		attribute.String("collection", "rent_amendments"),
+		attribute.String("operation", "find_one"),
+		attribute.String("rent_id", rentId),
+	))
+
+	rentObjectId, err := bson.ObjectIDFromHex(rentId)
+	if err != nil {
+		span.RecordError(err)
+		return models.RentAmendment{}, err
+	}
+
+	amendmentsCollection := rentAmendmentRepository.db.Collection("rent_amendments")
+
+	var amendment models.RentAmendment
+	err = amendmentsCollection.FindOne(spanCtx, bson.M{
+		"rent_id": rentObjectId,
+		"status":  models.RentAmendmentStatusPending,
+	}).Decode(&amendment)
+	if err != nil {
+		span.RecordError(err)
+		return models.RentAmendment{}, err
+	}
+
+	span.AddEvent("AmendmentFound")
+
+	return amendment, nil
+}
+
+func (rentAmendmentRepository *rentAmendmentRepository) GetAmendmentsByRent(ctx context.Context, rentId string) ([]models.RentAmendment, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentAmendmentRepository.GetAmendmentsByRent")
+	defer span.End()
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "rent_amendments"),
+		attribute.String("operation", "find"),
+		attribute.String("rent_id", rentId),
+	))
+
+	rentObjectId, err := bson.ObjectIDFromHex(rentId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	amendmentsCollection := rentAmendmentRepository.db.Collection("rent_amendments")
+
+	cursor, err := amendmentsCollection.Find(spanCtx, bson.M{"rent_id": rentObjectId}, options.Find().SetSort(bson.M{"version": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer cursor.Close(spanCtx)
+
+	var amendments []models.RentAmendment
+	if err := cursor.All(spanCtx, &amendments); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Found %d rent amendments", len(amendments)))
+
+	span.AddEvent("AmendmentsFound")
+	return amendments, nil
+}
+
+func (rentAmendmentRepository *rentAmendmentRepository) CountAmendments(ctx context.Context, rentId string) (int64, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentAmendmentRepository.CountAmendments")
+	defer span.End()
+
+	span.AddEvent("mongo.CountDocuments", trace.WithAttributes(
+		attribute.String("collection", "rent_amendments"),
+		attribute.String("operation", "count_documents"),
+		attribute.String("rent_id", rentId),
+	))
+
+	rentObjectId, err := bson.ObjectIDFromHex(rentId)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	amendmentsCollection := rentAmendmentRepository.db.Collection("rent_amendments")
+
+	count, err := amendmentsCollection.CountDocuments(spanCtx, bson.M{"rent_id": rentObjectId})
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// AddAcknowledgement records the acknowledgement of a co-tenant on a pending amendment, it returns
+// mongo.ErrNoDocuments when the amendment is not pending or the co-tenant acknowledged it already.
+func (rentAmendmentRepository *rentAmendmentRepository) AddAcknowledgement(ctx context.Context, amendmentId string, acknowledgement models.RentAcknowledgement) (models.RentAmendment, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentAmendmentRepository.AddAcknowledgement")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOneAndUpdate", trace.WithAttributes(
+		attribute.String("collection", "rent_amendments"),
+		attribute.String("operation", "find_one_and_update"),
+		attribute.String("_id", amendmentId),
+	))
+
+	amendmentObjectId, err := bson.ObjectIDFromHex(amendmentId)
+	if err != nil {
+		span.RecordError(err)
+		return models.RentAmendment{}, err
+	}
+
+	amendmentsCollection := rentAmendmentRepository.db.Collection("rent_amendments")
+
+	query := bson.M{
+		"_id":                         amendmentObjectId,
+		"status":                      models.RentAmendmentStatusPending,
+		"acknowledgements.tenant._id": bson.M{"$ne": acknowledgement.Tenant.Id},
+	}
+
+	var amendment models.RentAmendment
+	err = amendmentsCollection.FindOneAndUpdate(spanCtx, query,
+		bson.M{"$push": bson.M{"acknowledgements": acknowledgement}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&amendment)
+	if err != nil {
+		span.RecordError(err)
+		return models.RentAmendment{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent amendment %s acknowledged by %s", amendmentId, acknowledgement.Tenant.Id.Hex()))
+
+	return amendment, nil
+}
+
+// MarkApplied moves a pending amendment to applied, it returns mongo.ErrNoDocuments when the
+// amendment was applied already.
+func (rentAmendmentRepository *rentAmendmentRepository) MarkApplied(ctx context.Context, amendmentId string, appliedAt time.Time) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentAmendmentRepository.MarkApplied")
+	defer span.End()
+
+	span.AddEvent("mongo.UpdateOne", trace.WithAttributes(
+		attribute.String("collection", "rent_amendments"),
+		attribute.String("operation", "update_one"),
+		attribute.String("_id", amendmentId),
+	))
+
+	amendmentObjectId, err := bson.ObjectIDFromHex(amendmentId)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	amendmentsCollection := rentAmendmentRepository.db.Collection("rent_amendments")
+
+	result, err := amendmentsCollection.UpdateOne(spanCtx,
+		bson.M{"_id": amendmentObjectId, "status": models.RentAmendmentStatusPending},
+		bson.M{"$set": bson.M{"status": models.RentAmendmentStatusApplied, "applied_at": appliedAt}},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent amendment applied with ID: %s", amendmentId))
+
+	return nil
+}
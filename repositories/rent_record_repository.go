@@ -5,16 +5,34 @@ import (
 	"fmt"
 	"sample-web/models"
 	"sample-web/utils"
+	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type RentRecordRepository interface {
 	CreateRentRecord(ctx context.Context, rentRecord models.RentRecord) (models.RentRecord, error)
 	GetRentRecordById(ctx context.Context, rentRecordId string) (models.RentRecord, error)
-	GetAllRentRecords(ctx context.Context, userId string, userRole string, rentId string,) ([]models.RentRecord, error)
+	GetAllRentRecords(ctx context.Context, userId string, userRole string, rentId string, paymentMethod string) ([]models.RentRecord, error)
+	FindRecordByReference(ctx context.Context, rentId string, reference string) (models.RentRecord, error)
+	AddAttachment(ctx context.Context, rentRecordId string, attachment models.Attachment, maxAttachments int) (models.RentRecord, error)
+	SetReceipt(ctx context.Context, rentRecordId string, receipt models.Receipt) (models.RentRecord, error)
+	MarkResubmitted(ctx context.Context, rentRecordId string, resubmissionId bson.ObjectID) error
+	EditPendingRentRecord(ctx context.Context, tenantId string, rentRecordId string, rentRecord models.RentRecord, edit models.RentRecordEdit) (models.RentRecord, error)
+	WithdrawRentRecord(ctx context.Context, tenantId string, rentRecordId string, change models.RecordStatusChange) (models.RentRecord, error)
+	SetConfirmation(ctx context.Context, tenantId string, rentRecordId string, confirmation models.RecordConfirmation) (models.RentRecord, error)
+	OpenDispute(ctx context.Context, rentRecordId bson.ObjectID, dispute models.RecordDispute, at time.Time) error
+	SetDisputeStatus(ctx context.Context, rentRecordId bson.ObjectID, dispute models.RecordDispute, at time.Time) error
+	ReverseReview(ctx context.Context, rentRecordId bson.ObjectID, rentRecord models.RentRecord, previousStatus models.RentRecordStatus, change models.RecordStatusChange, dispute models.RecordDispute) (models.RentRecord, error)
+	ReverseApproval(ctx context.Context, rentRecordId bson.ObjectID, reversal models.RecordReversal, change models.RecordStatusChange) error
+	FindReversalEntry(ctx context.Context, rentRecordId bson.ObjectID) (models.RentRecord, error)
+	DeleteReversalEntry(ctx context.Context, entryId bson.ObjectID) error
 	UpdateRentRecord(ctx context.Context, userId string, rentRecordId string,rentRecord models.RentRecord) (models.RentRecord, error)
+	GetTenantTotals(ctx context.Context, rentId string) ([]models.TenantRecordTotals, error)
+	GetCoveredDueDates(ctx context.Context, rentId string, tenantId string) ([]time.Time, error)
+	SummariseRentRecords(ctx context.Context, rentId string, tenantId string) (models.RentRecordSummary, error)
 }
 
 type rentRecordRepository struct {
@@ -77,7 +95,7 @@ func (r *rentRecordRepository) GetRentRecordById(ctx context.Context, rentRecord
 	return rentRecord, nil
 }
 
-func (r *rentRecordRepository) GetAllRentRecords(ctx context.Context, userId string,userRole string,rentId string) ([]models.RentRecord, error) {
+func (r *rentRecordRepository) GetAllRentRecords(ctx context.Context, userId string,userRole string,rentId string, paymentMethod string) ([]models.RentRecord, error) {
 
 	log := utils.GetLogger()
 	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.GetAllRentRecords")
@@ -111,6 +129,10 @@ func (r *rentRecordRepository) GetAllRentRecords(ctx context.Context, userId str
 		return nil, fmt.Errorf("invalid user role: %s", userRole)
 	}
 
+	if paymentMethod != "" {
+		query["payment_method"] = paymentMethod
+	}
+
 
 	log.Info(spanCtx, "Querying rent records from the database")
 
@@ -161,15 +183,692 @@ func (r *rentRecordRepository) UpdateRentRecord(ctx context.Context, userId stri
 
 	rentId := rentRecord.RentId
 
-	query := bson.M{"_id": rentRecordObjectId, "landlord._id": userObjectId, "rent_id": rentId}
+	// the landlord reviews pending records only, the tenant may have withdrawn it in the meantime
+	query := bson.M{"_id": rentRecordObjectId, "landlord._id": userObjectId, "rent_id": rentId, "status": models.RentRecordStatusPending}
 
-	_, err = rentRecordCollection.UpdateOne(spanCtx, query, bson.M{"$set": rentRecord})
+	result, err := rentRecordCollection.UpdateOne(spanCtx, query, bson.M{"$set": rentRecord})
 	if err != nil {
 		log.Error(spanCtx, "Error updating rent record in the database")
 		return models.RentRecord{}, err
 	}
+	if result.MatchedCount == 0 {
+		log.Error(spanCtx, "Rent record is no longer pending")
+		return models.RentRecord{}, mongo.ErrNoDocuments
+	}
 
 	log.Info(spanCtx, "Rent record updated successfully")
 
 	return r.GetRentRecordById(spanCtx, rentRecordId)
 }
+
+// FindRecordByReference finds the pending or approved record of the rent that uses the reference.
+func (r *rentRecordRepository) FindRecordByReference(ctx context.Context, rentId string, reference string) (models.RentRecord, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.FindRecordByReference")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	rentObjectId, err := bson.ObjectIDFromHex(rentId)
+	if err != nil {
+		log.Error(spanCtx, "Error converting rent ID to ObjectID")
+		return models.RentRecord{}, err
+	}
+
+	query := bson.M{
+		"rent_id":   rentObjectId,
+		"reference": reference,
+		"status":    bson.M{"$in": bson.A{models.RentRecordStatusPending, models.RentRecordStatusApproved}},
+	}
+
+	var rentRecord models.RentRecord
+	if err := rentRecordCollection.FindOne(spanCtx, query).Decode(&rentRecord); err != nil {
+		return models.RentRecord{}, err
+	}
+
+	return rentRecord, nil
+}
+
+// AddAttachment adds the attachment to the record unless it has maxAttachments already, in which
+// case mongo.ErrNoDocuments is returned.
+func (r *rentRecordRepository) AddAttachment(ctx context.Context, rentRecordId string, attachment models.Attachment, maxAttachments int) (models.RentRecord, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.AddAttachment")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	rentRecordObjectId, err := bson.ObjectIDFromHex(rentRecordId)
+	if err != nil {
+		log.Error(spanCtx, "Error converting rent record ID to ObjectID")
+		return models.RentRecord{}, err
+	}
+
+	// the record matches only while the last allowed position is free
+	query := bson.M{
+		"_id": rentRecordObjectId,
+		fmt.Sprintf("attachments.%d", maxAttachments-1): bson.M{"$exists": false},
+	}
+
+	var rentRecord models.RentRecord
+	err = rentRecordCollection.FindOneAndUpdate(spanCtx, query,
+		bson.M{"$push": bson.M{"attachments": attachment}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&rentRecord)
+	if err != nil {
+		log.Error(spanCtx, "Error adding attachment to rent record")
+		return models.RentRecord{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Attachment %s added to rent record %s", attachment.Id.Hex(), rentRecordId))
+
+	return rentRecord, nil
+}
+
+// SetReceipt stores the receipt of a record that does not have one yet, it returns
+// mongo.ErrNoDocuments when a receipt was already stored.
+func (r *rentRecordRepository) SetReceipt(ctx context.Context, rentRecordId string, receipt models.Receipt) (models.RentRecord, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.SetReceipt")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	rentRecordObjectId, err := bson.ObjectIDFromHex(rentRecordId)
+	if err != nil {
+		log.Error(spanCtx, "Error converting rent record ID to ObjectID")
+		return models.RentRecord{}, err
+	}
+
+	query := bson.M{
+		"_id":     rentRecordObjectId,
+		"receipt": bson.M{"$exists": false},
+	}
+
+	var rentRecord models.RentRecord
+	err = rentRecordCollection.FindOneAndUpdate(spanCtx, query,
+		bson.M{"$set": bson.M{"receipt": receipt}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&rentRecord)
+	if err != nil {
+		log.Error(spanCtx, "Error setting receipt of rent record")
+		return models.RentRecord{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Receipt %s stored for rent record %s", receipt.Number, rentRecordId))
+
+	return rentRecord, nil
+}
+
+// MarkResubmitted links a rejected record to its resubmission, it returns mongo.ErrNoDocuments when
+// the record is not rejected, was already resubmitted or has an open dispute.
+func (r *rentRecordRepository) MarkResubmitted(ctx context.Context, rentRecordId string, resubmissionId bson.ObjectID) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.MarkResubmitted")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	rentRecordObjectId, err := bson.ObjectIDFromHex(rentRecordId)
+	if err != nil {
+		log.Error(spanCtx, "Error converting rent record ID to ObjectID")
+		return err
+	}
+
+	query := bson.M{
+		"_id":            rentRecordObjectId,
+		"status":         models.RentRecordStatusRejected,
+		"resubmitted_by": bson.M{"$exists": false},
+		// a dispute could approve the record after its resubmission is approved
+		"$or": bson.A{
+			bson.M{"dispute": bson.M{"$exists": false}},
+			bson.M{"dispute.status": models.DisputeStatusResolved},
+		},
+	}
+
+	result, err := rentRecordCollection.UpdateOne(spanCtx, query, bson.M{"$set": bson.M{
+		"resubmitted_by": resubmissionId,
+		"updated_at":     time.Now(),
+	}})
+	if err != nil {
+		log.Error(spanCtx, "Error marking rent record resubmitted")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent record %s resubmitted as %s", rentRecordId, resubmissionId.Hex()))
+
+	return nil
+}
+
+// EditPendingRentRecord replaces the payment details of a pending record submitted by the tenant and
+// adds the edit to its history, it returns mongo.ErrNoDocuments when the record is no longer pending.
+func (r *rentRecordRepository) EditPendingRentRecord(ctx context.Context, tenantId string, rentRecordId string, rentRecord models.RentRecord, edit models.RentRecordEdit) (models.RentRecord, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.EditPendingRentRecord")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	query, err := pendingTenantRecordQuery(tenantId, rentRecordId)
+	if err != nil {
+		log.Error(spanCtx, "Error converting rent record or tenant ID to ObjectID")
+		return models.RentRecord{}, err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"amount":         rentRecord.Amount,
+			"payment_method": rentRecord.PaymentMethod,
+			"reference":      rentRecord.Reference,
+			"paid_at":        rentRecord.PaidAt,
+			"updated_at":     edit.EditedAt,
+		},
+		"$push": bson.M{"edits": edit},
+	}
+
+	result, err := rentRecordCollection.UpdateOne(spanCtx, query, update)
+	if err != nil {
+		log.Error(spanCtx, "Error editing rent record in the database")
+		return models.RentRecord{}, err
+	}
+	if result.MatchedCount == 0 {
+		return models.RentRecord{}, mongo.ErrNoDocuments
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent record %s edited by tenant %s", rentRecordId, tenantId))
+
+	return r.GetRentRecordById(spanCtx, rentRecordId)
+}
+
+// WithdrawRentRecord marks a pending record submitted by the tenant as withdrawn, it returns
+// mongo.ErrNoDocuments when the record is no longer pending.
+func (r *rentRecordRepository) WithdrawRentRecord(ctx context.Context, tenantId string, rentRecordId string, change models.RecordStatusChange) (models.RentRecord, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.WithdrawRentRecord")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	query, err := pendingTenantRecordQuery(tenantId, rentRecordId)
+	if err != nil {
+		log.Error(spanCtx, "Error converting rent record or tenant ID to ObjectID")
+		return models.RentRecord{}, err
+	}
+
+	result, err := rentRecordCollection.UpdateOne(spanCtx, query, bson.M{
+		"$set": bson.M{
+			"status":       models.RentRecordStatusWithdrawn,
+			"withdrawn_at": change.ChangedAt,
+			"updated_at":   change.ChangedAt,
+		},
+		"$push": bson.M{"status_history": change},
+	})
+	if err != nil {
+		log.Error(spanCtx, "Error withdrawing rent record in the database")
+		return models.RentRecord{}, err
+	}
+	if result.MatchedCount == 0 {
+		return models.RentRecord{}, mongo.ErrNoDocuments
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent record %s withdrawn by tenant %s", rentRecordId, tenantId))
+
+	return r.GetRentRecordById(spanCtx, rentRecordId)
+}
+
+// SetConfirmation stores the answer of the tenant to a record the landlord recorded for them, it
+// returns mongo.ErrNoDocuments when the record was answered already or the window has passed.
+func (r *rentRecordRepository) SetConfirmation(ctx context.Context, tenantId string, rentRecordId string, confirmation models.RecordConfirmation) (models.RentRecord, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.SetConfirmation")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	rentRecordObjectId, err := bson.ObjectIDFromHex(rentRecordId)
+	if err != nil {
+		log.Error(spanCtx, "Error converting rent record ID to ObjectID")
+		return models.RentRecord{}, err
+	}
+
+	tenantObjectId, err := bson.ObjectIDFromHex(tenantId)
+	if err != nil {
+		log.Error(spanCtx, "Error converting tenant ID to ObjectID")
+		return models.RentRecord{}, err
+	}
+
+	query := bson.M{
+		"_id":          rentRecordObjectId,
+		"tenant._id":   tenantObjectId,
+		"recorded_by":  models.LandLord,
+		"confirmation": bson.M{"$exists": false},
+		"confirm_by":   bson.M{"$gt": confirmation.RespondedAt},
+	}
+
+	result, err := rentRecordCollection.UpdateOne(spanCtx, query, bson.M{"$set": bson.M{
+		"confirmation": confirmation,
+		"updated_at":   confirmation.RespondedAt,
+	}})
+	if err != nil {
+		log.Error(spanCtx, "Error setting rent record confirmation in the database")
+		return models.RentRecord{}, err
+	}
+	if result.MatchedCount == 0 {
+		return models.RentRecord{}, mongo.ErrNoDocuments
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent record %s %s by tenant %s", rentRecordId, confirmation.Status, tenantId))
+
+	return r.GetRentRecordById(spanCtx, rentRecordId)
+}
+
+// OpenDispute flags a reviewed record with a new dispute, it returns mongo.ErrNoDocuments when the
+// record is not approved or rejected, was resubmitted or its latest dispute is not resolved.
+func (r *rentRecordRepository) OpenDispute(ctx context.Context, rentRecordId bson.ObjectID, dispute models.RecordDispute, at time.Time) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.OpenDispute")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	query := bson.M{
+		"_id":    rentRecordId,
+		"status":         bson.M{"$in": bson.A{models.RentRecordStatusApproved, models.RentRecordStatusRejected}},
+		"resubmitted_by": bson.M{"$exists": false},
+		"$or": bson.A{
+			bson.M{"dispute": bson.M{"$exists": false}},
+			bson.M{"dispute.status": models.DisputeStatusResolved},
+		},
+	}
+
+	result, err := rentRecordCollection.UpdateOne(spanCtx, query, bson.M{"$set": bson.M{
+		"dispute":    dispute,
+		"updated_at": at,
+	}})
+	if err != nil {
+		log.Error(spanCtx, "Error opening dispute of rent record")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent record %s disputed with %s", rentRecordId.Hex(), dispute.Id.Hex()))
+
+	return nil
+}
+
+// SetDisputeStatus updates the status of the dispute the record is flagged with, it returns
+// mongo.ErrNoDocuments when the record is flagged with another dispute or the dispute already has
+// the status, so concurrent resolutions flag the record once.
+func (r *rentRecordRepository) SetDisputeStatus(ctx context.Context, rentRecordId bson.ObjectID, dispute models.RecordDispute, at time.Time) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.SetDisputeStatus")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	query := bson.M{
+		"_id":            rentRecordId,
+		"dispute._id":    dispute.Id,
+		"dispute.status": bson.M{"$ne": dispute.Status},
+	}
+
+	result, err := rentRecordCollection.UpdateOne(spanCtx, query, bson.M{"$set": bson.M{
+		"dispute.status": dispute.Status,
+		"updated_at":     at,
+	}})
+	if err != nil {
+		log.Error(spanCtx, "Error updating dispute status of rent record")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Dispute %s of rent record %s is %s", dispute.Id.Hex(), rentRecordId.Hex(), dispute.Status))
+
+	return nil
+}
+
+// ReverseReview replaces the review of a record that still has previousStatus with the status,
+// approval date and rejection of rentRecord and moves its dispute to the status of dispute in the same
+// update. It returns mongo.ErrNoDocuments when the status changed, the dispute already has the status
+// or a rejected record to approve was resubmitted.
+func (r *rentRecordRepository) ReverseReview(ctx context.Context, rentRecordId bson.ObjectID, rentRecord models.RentRecord, previousStatus models.RentRecordStatus, change models.RecordStatusChange, dispute models.RecordDispute) (models.RentRecord, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.ReverseReview")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":         rentRecord.Status,
+			"approved_at":    rentRecord.ApprovedAt,
+			"updated_at":     rentRecord.UpdatedAt,
+			"dispute.status": dispute.Status,
+		},
+		"$push": bson.M{"status_history": change},
+	}
+	if rentRecord.Rejection != nil {
+		update["$set"].(bson.M)["rejection"] = rentRecord.Rejection
+	} else {
+		update["$unset"] = bson.M{"rejection": ""}
+	}
+
+	query := bson.M{
+		"_id":            rentRecordId,
+		"status":         previousStatus,
+		"dispute._id":    dispute.Id,
+		"dispute.status": bson.M{"$ne": dispute.Status},
+	}
+	if rentRecord.Status == models.RentRecordStatusApproved {
+		// the resubmission already pays the installment
+		query["resubmitted_by"] = bson.M{"$exists": false}
+	}
+
+	result, err := rentRecordCollection.UpdateOne(spanCtx, query, update)
+	if err != nil {
+		log.Error(spanCtx, "Error reversing review of rent record")
+		return models.RentRecord{}, err
+	}
+	if result.MatchedCount == 0 {
+		return models.RentRecord{}, mongo.ErrNoDocuments
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent record %s is %s instead of %s", rentRecordId.Hex(), rentRecord.Status, previousStatus))
+
+	return r.GetRentRecordById(spanCtx, rentRecordId.Hex())
+}
+
+// ReverseApproval marks an approved record as reversed, it returns mongo.ErrNoDocuments when the
+// record is no longer approved.
+func (r *rentRecordRepository) ReverseApproval(ctx context.Context, rentRecordId bson.ObjectID, reversal models.RecordReversal, change models.RecordStatusChange) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.ReverseApproval")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	query := bson.M{"_id": rentRecordId, "status": models.RentRecordStatusApproved}
+
+	result, err := rentRecordCollection.UpdateOne(spanCtx, query, bson.M{
+		"$set": bson.M{
+			"status":     models.RentRecordStatusReversed,
+			"reversal":   reversal,
+			"updated_at": reversal.ReversedAt,
+		},
+		"$push": bson.M{"status_history": change},
+	})
+	if err != nil {
+		log.Error(spanCtx, "Error reversing rent record in the database")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent record %s reversed, compensated by %s", rentRecordId.Hex(), reversal.EntryId.Hex()))
+
+	return nil
+}
+
+// FindReversalEntry returns the compensating entry of a record, the unique reverses index allows a
+// single one.
+func (r *rentRecordRepository) FindReversalEntry(ctx context.Context, rentRecordId bson.ObjectID) (models.RentRecord, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.FindReversalEntry")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	var entry models.RentRecord
+	if err := rentRecordCollection.FindOne(spanCtx, bson.M{"reverses": rentRecordId}).Decode(&entry); err != nil {
+		log.Error(spanCtx, "Error fetching compensating entry from the database")
+		return models.RentRecord{}, err
+	}
+
+	return entry, nil
+}
+
+// DeleteReversalEntry deletes a compensating entry whose record could not be reversed, other records
+// are never deleted.
+func (r *rentRecordRepository) DeleteReversalEntry(ctx context.Context, entryId bson.ObjectID) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.DeleteReversalEntry")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	if _, err := rentRecordCollection.DeleteOne(spanCtx, bson.M{"_id": entryId, "reverses": bson.M{"$exists": true}}); err != nil {
+		log.Error(spanCtx, "Error deleting compensating entry from the database")
+		return err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Compensating entry %s deleted", entryId.Hex()))
+
+	return nil
+}
+
+func pendingTenantRecordQuery(tenantId string, rentRecordId string) (bson.M, error) {
+	rentRecordObjectId, err := bson.ObjectIDFromHex(rentRecordId)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantObjectId, err := bson.ObjectIDFromHex(tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	return bson.M{
+		"_id":        rentRecordObjectId,
+		"tenant._id": tenantObjectId,
+		"status":     models.RentRecordStatusPending,
+	}, nil
+}
+
+// settledMinor is the amount a record counts for, the approved amount when the landlord adjusted it.
+var settledMinor = bson.M{"$ifNull": bson.A{"$approved_amount.minor", "$amount.minor"}}
+
+// GetTenantTotals sums the approved and pending record amounts of each co-tenant of a rent.
+func (r *rentRecordRepository) GetTenantTotals(ctx context.Context, rentId string) ([]models.TenantRecordTotals, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.GetTenantTotals")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	rentObjectId, err := bson.ObjectIDFromHex(rentId)
+	if err != nil {
+		log.Error(spanCtx, "Error converting rent ID to ObjectID")
+		return nil, err
+	}
+
+	sumByStatus := func(status models.RentRecordStatus) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", status}}, settledMinor, 0}}}
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"rent_id": rentObjectId}},
+		bson.M{"$group": bson.M{
+			"_id":      "$tenant._id",
+			"approved": sumByStatus(models.RentRecordStatusApproved),
+			"pending":  sumByStatus(models.RentRecordStatusPending),
+			"reversed": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$ne": bson.A{bson.M{"$type": "$reverses"}, "missing"}}, "$amount.minor", 0,
+			}}},
+		}},
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Aggregating rent record totals for rent ID: %s", rentId))
+
+	cursor, err := rentRecordCollection.Aggregate(spanCtx, pipeline)
+	if err != nil {
+		log.Error(spanCtx, "Error aggregating rent record totals")
+		return nil, err
+	}
+
+	defer cursor.Close(spanCtx)
+
+	var totals []models.TenantRecordTotals
+
+	if err := cursor.All(spanCtx, &totals); err != nil {
+		log.Error(spanCtx, "Error decoding rent record totals")
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// GetCoveredDueDates returns the due dates of the installments a co-tenant has a pending or approved
+// record for.
+func (r *rentRecordRepository) GetCoveredDueDates(ctx context.Context, rentId string, tenantId string) ([]time.Time, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.GetCoveredDueDates")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	rentObjectId, err := bson.ObjectIDFromHex(rentId)
+	if err != nil {
+		log.Error(spanCtx, "Error converting rent ID to ObjectID")
+		return nil, err
+	}
+
+	tenantObjectId, err := bson.ObjectIDFromHex(tenantId)
+	if err != nil {
+		log.Error(spanCtx, "Error converting tenant ID to ObjectID")
+		return nil, err
+	}
+
+	query := bson.M{
+		"rent_id":    rentObjectId,
+		"tenant._id": tenantObjectId,
+		"status":     bson.M{"$in": bson.A{models.RentRecordStatusPending, models.RentRecordStatusApproved}},
+		"due_date":   bson.M{"$type": "date"},
+	}
+
+	var dueDates []time.Time
+	if err := rentRecordCollection.Distinct(spanCtx, "due_date", query).Decode(&dueDates); err != nil {
+		log.Error(spanCtx, "Error finding the due dates of rent records")
+		return nil, err
+	}
+
+	return dueDates, nil
+}
+
+// SummariseRentRecords aggregates the records of a rent in a single pipeline. When tenantId is not
+// empty only the records of that co-tenant are included.
+func (r *rentRecordRepository) SummariseRentRecords(ctx context.Context, rentId string, tenantId string) (models.RentRecordSummary, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordRepository.SummariseRentRecords")
+	defer span.End()
+
+	rentRecordCollection := r.db.Collection("rent_records")
+
+	rentObjectId, err := bson.ObjectIDFromHex(rentId)
+	if err != nil {
+		log.Error(spanCtx, "Error converting rent ID to ObjectID")
+		return models.RentRecordSummary{}, err
+	}
+
+	match := bson.M{"rent_id": rentObjectId}
+
+	if tenantId != "" {
+		tenantObjectId, err := bson.ObjectIDFromHex(tenantId)
+		if err != nil {
+			log.Error(spanCtx, "Error converting tenant ID to ObjectID")
+			return models.RentRecordSummary{}, err
+		}
+		match["tenant._id"] = tenantObjectId
+	}
+
+	isStatus := func(status models.RentRecordStatus) bson.M {
+		return bson.M{"$eq": bson.A{"$status", status}}
+	}
+	sumByStatus := func(status models.RentRecordStatus) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{isStatus(status), settledMinor, 0}}}
+	}
+
+	// a payment is on time when it is paid before the end of its due day, records without a due
+	// date are counted as on time and records without a payment date were paid when submitted. Older
+	// records stored the zero time for a missing due date, which sorts before the epoch like null does.
+	paidAt := bson.M{"$ifNull": bson.A{"$paid_at", "$submitted_at"}}
+	hasDueDate := bson.M{"$gt": bson.A{"$due_date", time.Unix(0, 0).UTC()}}
+	dueBy := bson.M{"$dateAdd": bson.M{
+		"startDate": bson.M{"$cond": bson.A{hasDueDate, "$due_date", paidAt}},
+		"unit":      "day",
+		"amount":    1,
+	}}
+	isOnTime := bson.M{"$lt": bson.A{paidAt, dueBy}}
+
+	pipeline := bson.A{
+		bson.M{"$match": match},
+		bson.M{"$group": bson.M{
+			"_id":      nil,
+			"approved": sumByStatus(models.RentRecordStatusApproved),
+			"pending":  sumByStatus(models.RentRecordStatusPending),
+			"rejected": sumByStatus(models.RentRecordStatusRejected),
+			"disputed": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$in": bson.A{"$dispute.status", bson.A{models.DisputeStatusOpen, models.DisputeStatusUnderReview}}}, 1, 0,
+			}}},
+			"last_payment_date": bson.M{"$max": bson.M{"$cond": bson.A{
+				isStatus(models.RentRecordStatusApproved), paidAt, nil,
+			}}},
+			"on_time_payments": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{isStatus(models.RentRecordStatusApproved), isOnTime}}, 1, 0,
+			}}},
+			"late_payments": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{isStatus(models.RentRecordStatusApproved), bson.M{"$not": bson.A{isOnTime}}}}, 1, 0,
+			}}},
+		}},
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Aggregating rent record summary for rent ID: %s", rentId))
+
+	cursor, err := rentRecordCollection.Aggregate(spanCtx, pipeline)
+	if err != nil {
+		log.Error(spanCtx, "Error aggregating rent record summary")
+		return models.RentRecordSummary{}, err
+	}
+
+	defer cursor.Close(spanCtx)
+
+	var summary models.RentRecordSummary
+
+	// no document is returned when the rent has no records yet
+	if cursor.Next(spanCtx) {
+		if err := cursor.Decode(&summary); err != nil {
+			log.Error(spanCtx, "Error decoding rent record summary")
+			return models.RentRecordSummary{}, err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		log.Error(spanCtx, "Error reading rent record summary")
+		return models.RentRecordSummary{}, err
+	}
+
+	return summary, nil
+}
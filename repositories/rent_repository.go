@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"sample-web/models"
 	"sample-web/utils"
+	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -17,6 +19,15 @@ type RentRepository interface {
 	FindRentById(ctx context.Context, userId string, rentId string) (models.Rent, error)
 	GetAllRents(ctx context.Context, userId string, userRole models.UserRole) ([]models.Rent, error)
 	UpdateRent(ctx context.Context, userId string, rent models.Rent) (models.Rent, error)
+	AttachInvitedTenant(ctx context.Context, phoneNumber string, tenant models.PersonRef) (int64, error)
+	GetRentsByUnitIds(ctx context.Context, landLordId string, unitIds []bson.ObjectID) ([]models.Rent, error)
+	CloseTerminatedRents(ctx context.Context, at time.Time) (int64, error)
+	FindRentsDueForTransition(ctx context.Context, at time.Time) ([]models.Rent, error)
+	UpdateRentStatus(ctx context.Context, rentId bson.ObjectID, from models.RentStatus, to models.RentStatus, at time.Time) (bool, error)
+	SetTermination(ctx context.Context, rentId bson.ObjectID, termination models.RentTermination, at time.Time) error
+	ApplyTerms(ctx context.Context, rentId bson.ObjectID, terms models.RentTerms, fields []string, at time.Time) error
+	SetAgreementSigned(ctx context.Context, rentId bson.ObjectID, agreementId bson.ObjectID, at time.Time) error
+	SupersedeAgreement(ctx context.Context, rentId bson.ObjectID, at time.Time) error
 }
 
 type rentRepository struct {
@@ -88,7 +99,7 @@ func (rentRepository *rentRepository) FindRentById(ctx context.Context, userId s
 		"_id": objectID,
 		"$or": []bson.M{
 			{"landlord._id": userObjectId},
-			{"tenant._id": userObjectId},
+			{"tenants._id": userObjectId},
 		},
 	}
 
@@ -131,7 +142,7 @@ func (rentRepository *rentRepository) GetAllRents(ctx context.Context, userId st
 	if userRole == models.LandLord {
 		query = bson.M{"landlord._id": userObjectId}
 	} else {
-		query = bson.M{"tenant._id": userObjectId}
+		query = bson.M{"tenants._id": userObjectId}
 	}
 
 	log.Info(spanCtx, query)
@@ -191,3 +202,342 @@ func (rentRepository *rentRepository) UpdateRent(ctx context.Context, userId str
 	}
 	return rentRepository.FindRentById(ctx, userId, rent.Id.Hex())
 }
+
+// AttachInvitedTenant sets the user on every co-tenant entry that is waiting for the given phone number.
+func (rentRepository *rentRepository) AttachInvitedTenant(ctx context.Context, phoneNumber string, tenant models.PersonRef) (int64, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRepository.AttachInvitedTenant")
+	defer span.End()
+
+	rentsCollection := rentRepository.db.Collection("rents")
+
+	span.AddEvent("mongo.UpdateMany", trace.WithAttributes(
+		attribute.String("collection", "rents"),
+		attribute.String("operation", "update_many"),
+		attribute.String("tenants.phone_number", phoneNumber),
+	))
+
+	invitedTenant := bson.M{"phone_number": phoneNumber, "invited": true}
+
+	query := bson.M{"tenants": bson.M{"$elemMatch": invitedTenant}}
+	update := bson.M{
+		"$set": bson.M{
+			"tenants.$[tenant]._id":     tenant.Id,
+			"tenants.$[tenant].name":    tenant.Name,
+			"tenants.$[tenant].invited": false,
+			"updated_at":                time.Now(),
+		},
+	}
+	opts := options.UpdateMany().SetArrayFilters([]interface{}{
+		bson.M{"tenant.phone_number": phoneNumber, "tenant.invited": true},
+	})
+
+	result, err := rentsCollection.UpdateMany(spanCtx, query, update, opts)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Attached tenant %s to %d invited rents", tenant.Id.Hex(), result.ModifiedCount))
+
+	return result.ModifiedCount, nil
+}
+
+func (rentRepository *rentRepository) GetRentsByUnitIds(ctx context.Context, landLordId string, unitIds []bson.ObjectID) ([]models.Rent, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRepository.GetRentsByUnitIds")
+	defer span.End()
+
+	rentsCollection := rentRepository.db.Collection("rents")
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "rents"),
+		attribute.String("operation", "find"),
+		attribute.Int("unit_count", len(unitIds)),
+	))
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	query := bson.M{
+		"landlord._id": landLordObjectId,
+		"unit._id":     bson.M{"$in": unitIds},
+	}
+
+	cursor, err := rentsCollection.Find(spanCtx, query)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer cursor.Close(spanCtx)
+
+	var rents []models.Rent
+	if err := cursor.All(spanCtx, &rents); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Found %d rents for %d units", len(rents), len(unitIds)))
+
+	return rents, nil
+}
+
+// CloseTerminatedRents closes the rents under notice whose move-out date is on or before the given time.
+func (rentRepository *rentRepository) CloseTerminatedRents(ctx context.Context, at time.Time) (int64, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRepository.CloseTerminatedRents")
+	defer span.End()
+
+	rentsCollection := rentRepository.db.Collection("rents")
+
+	span.AddEvent("mongo.UpdateMany", trace.WithAttributes(
+		attribute.String("collection", "rents"),
+		attribute.String("operation", "update_many"),
+	))
+
+	query := bson.M{
+		"status":                    models.RentStatusNoticeGiven,
+		"termination.move_out_date": bson.M{"$lte": at},
+	}
+
+	result, err := rentsCollection.UpdateMany(spanCtx, query, bson.M{"$set": bson.M{
+		"status":     models.RentStatusClosed,
+		"updated_at": at,
+	}})
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Closed %d rents at the end of their notice", result.ModifiedCount))
+
+	return result.ModifiedCount, nil
+}
+
+// FindRentsDueForTransition returns the rents whose lifecycle status no longer matches their dates.
+func (rentRepository *rentRepository) FindRentsDueForTransition(ctx context.Context, at time.Time) ([]models.Rent, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRepository.FindRentsDueForTransition")
+	defer span.End()
+
+	rentsCollection := rentRepository.db.Collection("rents")
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "rents"),
+		attribute.String("operation", "find"),
+	))
+
+	query := bson.M{
+		"$or": []bson.M{
+			{"status": models.RentStatusUpcoming, "start_date": bson.M{"$lte": at}},
+			{"status": models.RentStatusActive, "start_date": bson.M{"$gt": at}},
+			{"status": models.RentStatusActive, "end_date": bson.M{"$lte": at}},
+			{"status": models.RentStatusExpired, "end_date": bson.M{"$gt": at}},
+		},
+	}
+
+	cursor, err := rentsCollection.Find(spanCtx, query)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer cursor.Close(spanCtx)
+
+	var rents []models.Rent
+	if err := cursor.All(spanCtx, &rents); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Found %d rents due for a status transition", len(rents)))
+
+	return rents, nil
+}
+
+// UpdateRentStatus moves the rent to the new status only if it still has the expected one, it
+// reports whether the rent was updated.
+func (rentRepository *rentRepository) UpdateRentStatus(ctx context.Context, rentId bson.ObjectID, from models.RentStatus, to models.RentStatus, at time.Time) (bool, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRepository.UpdateRentStatus")
+	defer span.End()
+
+	rentsCollection := rentRepository.db.Collection("rents")
+
+	span.AddEvent("mongo.UpdateOne", trace.WithAttributes(
+		attribute.String("collection", "rents"),
+		attribute.String("operation", "update_one"),
+		attribute.String("_id", rentId.Hex()),
+	))
+
+	result, err := rentsCollection.UpdateOne(spanCtx,
+		bson.M{"_id": rentId, "status": from},
+		bson.M{"$set": bson.M{"status": to, "updated_at": at}},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// SetTermination records the notice given for an upcoming or active rent and moves its end date to
+// the move-out date. It returns mongo.ErrNoDocuments when the rent is closed or notice was already
+// given, so concurrent notices are recorded once.
+func (rentRepository *rentRepository) SetTermination(ctx context.Context, rentId bson.ObjectID, termination models.RentTermination, at time.Time) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRepository.SetTermination")
+	defer span.End()
+
+	rentsCollection := rentRepository.db.Collection("rents")
+
+	span.AddEvent("mongo.UpdateOne", trace.WithAttributes(
+		attribute.String("collection", "rents"),
+		attribute.String("operation", "update_one"),
+		attribute.String("_id", rentId.Hex()),
+	))
+
+	result, err := rentsCollection.UpdateOne(spanCtx,
+		bson.M{
+			"_id":    rentId,
+			"status": bson.M{"$in": bson.A{models.RentStatusUpcoming, models.RentStatusActive}},
+		},
+		bson.M{"$set": bson.M{
+			"termination": termination,
+			"status":      models.RentStatusNoticeGiven,
+			// installments stop at the move-out date as they are computed up to the end date
+			"end_date":   termination.MoveOutDate,
+			"updated_at": at,
+		}},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// ApplyTerms sets the given fields of the terms on the rent, the other fields are left as they are.
+// It returns mongo.ErrNoDocuments when the rent is closed or notice was given.
+func (rentRepository *rentRepository) ApplyTerms(ctx context.Context, rentId bson.ObjectID, terms models.RentTerms, fields []string, at time.Time) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRepository.ApplyTerms")
+	defer span.End()
+
+	rentsCollection := rentRepository.db.Collection("rents")
+
+	span.AddEvent("mongo.UpdateOne", trace.WithAttributes(
+		attribute.String("collection", "rents"),
+		attribute.String("operation", "update_one"),
+		attribute.String("_id", rentId.Hex()),
+	))
+
+	set := bson.M{"updated_at": at}
+	for _, field := range fields {
+		switch field {
+		case "title":
+			set["title"] = terms.Title
+		case "amount":
+			set["amount"] = terms.Amount
+		case "schedule":
+			set["schedule"] = terms.Schedule
+			set["interval"] = terms.Interval
+			set["anchor_day"] = terms.AnchorDay
+		case "end_date":
+			set["end_date"] = terms.EndDate
+		}
+	}
+
+	result, err := rentsCollection.UpdateOne(spanCtx,
+		bson.M{
+			"_id":    rentId,
+			"status": bson.M{"$nin": bson.A{models.RentStatusClosed, models.RentStatusNoticeGiven}},
+		},
+		bson.M{"$set": set},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// SetAgreementSigned marks the rent signed with the agreement both parties signed.
+func (rentRepository *rentRepository) SetAgreementSigned(ctx context.Context, rentId bson.ObjectID, agreementId bson.ObjectID, at time.Time) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRepository.SetAgreementSigned")
+	defer span.End()
+
+	rentsCollection := rentRepository.db.Collection("rents")
+
+	span.AddEvent("mongo.UpdateOne", trace.WithAttributes(
+		attribute.String("collection", "rents"),
+		attribute.String("operation", "update_one"),
+		attribute.String("_id", rentId.Hex()),
+	))
+
+	_, err := rentsCollection.UpdateOne(spanCtx,
+		bson.M{"_id": rentId},
+		bson.M{"$set": bson.M{
+			"agreement_status":    models.AgreementStatusSigned,
+			"signed_agreement_id": agreementId,
+			"updated_at":          at,
+		}},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// SupersedeAgreement marks the signed agreement of the rent superseded, SignedAgreementId is kept as
+// the agreement that was signed last.
+func (rentRepository *rentRepository) SupersedeAgreement(ctx context.Context, rentId bson.ObjectID, at time.Time) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRepository.SupersedeAgreement")
+	defer span.End()
+
+	rentsCollection := rentRepository.db.Collection("rents")
+
+	span.AddEvent("mongo.UpdateOne", trace.WithAttributes(
+		attribute.String("collection", "rents"),
+		attribute.String("operation", "update_one"),
+		attribute.String("_id", rentId.Hex()),
+	))
+
+	_, err := rentsCollection.UpdateOne(spanCtx,
+		bson.M{"_id": rentId, "agreement_status": models.AgreementStatusSigned},
+		bson.M{"$set": bson.M{
+			"agreement_status": models.AgreementStatusSuperseded,
+			"updated_at":       at,
+		}},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sample-web/models"
+	"sample-web/utils"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type UnitRepository interface {
+	CreateUnit(ctx context.Context, unit models.Unit) (models.Unit, error)
+	FindUnitById(ctx context.Context, landLordId string, unitId string) (models.Unit, error)
+	GetUnitsByProperty(ctx context.Context, landLordId string, propertyId string) ([]models.Unit, error)
+	GetUnitsByLandLord(ctx context.Context, landLordId string) ([]models.Unit, error)
+	UpdateUnit(ctx context.Context, landLordId string, unit models.Unit) (models.Unit, error)
+	DeleteUnit(ctx context.Context, landLordId string, unitId string) error
+}
+
+type unitRepository struct {
+	db *mongo.Database
+}
+
+func NewUnitRepository(db *mongo.Database) UnitRepository {
+	return &unitRepository{
+		db: db,
+	}
+}
+
+func (unitRepository *unitRepository) CreateUnit(ctx context.Context, unit models.Unit) (models.Unit, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "UnitRepository.CreateUnit")
+	defer span.End()
+
+	span.AddEvent("mongo.InsertOne", trace.WithAttributes(
+		attribute.String("collection", "units"),
+		attribute.String("operation", "insert_one"),
+		attribute.String("property_id", unit.PropertyId.Hex()),
+	))
+
+	unitsCollection := unitRepository.db.Collection("units")
+	result, err := unitsCollection.InsertOne(spanCtx, unit)
+	if err != nil {
+		span.RecordError(err)
+		span.AddEvent("UnitCreationFailed")
+		return models.Unit{}, err
+	}
+
+	span.AddEvent("UnitCreated")
+
+	id := result.InsertedID.(bson.ObjectID).Hex()
+
+	log.Info(spanCtx, fmt.Sprintf("Unit created with ID: %s", id))
+
+	return unitRepository.FindUnitById(spanCtx, unit.LandLord.Id.Hex(), id)
+}
+
+func (unitRepository *unitRepository) FindUnitById(ctx context.Context, landLordId string, unitId string) (models.Unit, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "UnitRepository.FindUnitById")
+	defer span.End()
+
+	span.AddEvent("mongo.FindOne", trace.WithAttributes(
+		attribute.String("collection", "units"),
+		attribute.String("operation", "find_one"),
+		attribute.String("_id", unitId),
+	))
+
+	unitObjectId, err := bson.ObjectIDFromHex(unitId)
+	if err != nil {
+		span.RecordError(err)
+		return models.Unit{}, err
+	}
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return models.Unit{}, err
+	}
+
+	unitsCollection := unitRepository.db.Collection("units")
+
+	var unit models.Unit
+	err = unitsCollection.FindOne(spanCtx, bson.M{"_id": unitObjectId, "landlord._id": landLordObjectId}).Decode(&unit)
+	if err != nil {
+		span.RecordError(err)
+		return models.Unit{}, err
+	}
+
+	span.AddEvent("UnitFound")
+
+	return unit, nil
+}
+
+func (unitRepository *unitRepository) GetUnitsByProperty(ctx context.Context, landLordId string, propertyId string) ([]models.Unit, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "UnitRepository.GetUnitsByProperty")
+	defer span.End()
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "units"),
+		attribute.String("operation", "find"),
+		attribute.String("property_id", propertyId),
+	))
+
+	propertyObjectId, err := bson.ObjectIDFromHex(propertyId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	unitsCollection := unitRepository.db.Collection("units")
+
+	cursor, err := unitsCollection.Find(spanCtx, bson.M{"property_id": propertyObjectId, "landlord._id": landLordObjectId})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer cursor.Close(spanCtx)
+
+	var units []models.Unit
+	if err := cursor.All(spanCtx, &units); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Found %d units", len(units)))
+
+	span.AddEvent("UnitsFound")
+	return units, nil
+}
+
+func (unitRepository *unitRepository) GetUnitsByLandLord(ctx context.Context, landLordId string) ([]models.Unit, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "UnitRepository.GetUnitsByLandLord")
+	defer span.End()
+
+	span.AddEvent("mongo.Find", trace.WithAttributes(
+		attribute.String("collection", "units"),
+		attribute.String("operation", "find"),
+		attribute.String("landlord_id", landLordId),
+	))
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	unitsCollection := unitRepository.db.Collection("units")
+
+	cursor, err := unitsCollection.Find(spanCtx, bson.M{"landlord._id": landLordObjectId})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	defer cursor.Close(spanCtx)
+
+	var units []models.Unit
+	if err := cursor.All(spanCtx, &units); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Found %d units", len(units)))
+
+	span.AddEvent("UnitsFound")
+	return units, nil
+}
+
+func (unitRepository *unitRepository) UpdateUnit(ctx context.Context, landLordId string, unit models.Unit) (models.Unit, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "UnitRepository.UpdateUnit")
+	defer span.End()
+
+	span.AddEvent("mongo.UpdateOne", trace.WithAttributes(
+		attribute.String("collection", "units"),
+		attribute.String("operation", "update_one"),
+		attribute.String("_id", unit.Id.Hex()),
+	))
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return models.Unit{}, err
+	}
+
+	unitsCollection := unitRepository.db.Collection("units")
+
+	query := bson.M{
+		"_id":          unit.Id,
+		"landlord._id": landLordObjectId,
+	}
+
+	_, err = unitsCollection.UpdateOne(spanCtx, query, bson.M{"$set": unit})
+	if err != nil {
+		span.RecordError(err)
+		return models.Unit{}, err
+	}
+	return unitRepository.FindUnitById(spanCtx, landLordId, unit.Id.Hex())
+}
+
+func (unitRepository *unitRepository) DeleteUnit(ctx context.Context, landLordId string, unitId string) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "UnitRepository.DeleteUnit")
+	defer span.End()
+
+	span.AddEvent("mongo.DeleteOne", trace.WithAttributes(
+		attribute.String("collection", "units"),
+		attribute.String("operation", "delete_one"),
+		attribute.String("_id", unitId),
+	))
+
+	unitObjectId, err := bson.ObjectIDFromHex(unitId)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	landLordObjectId, err := bson.ObjectIDFromHex(landLordId)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	unitsCollection := unitRepository.db.Collection("units")
+
+	result, err := unitsCollection.DeleteOne(spanCtx, bson.M{"_id": unitObjectId, "landlord._id": landLordObjectId})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Unit deleted with ID: %s", unitId))
+
+	return nil
+}
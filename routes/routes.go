@@ -16,6 +16,14 @@ func SetupRouter(
 	authController controllers.AuthController,
 	rentController controllers.RentController,
 	rentRecordController controllers.RentRecordController,
+	propertyController controllers.PropertyController,
+	unitController controllers.UnitController,
+	dashboardController controllers.DashboardController,
+	exchangeRateController controllers.ExchangeRateController,
+	fileController controllers.FileController,
+	agreementController controllers.AgreementController,
+	disputeController controllers.DisputeController,
+	maintenanceController controllers.MaintenanceController,
 	jwtService services.JWTService,
 ) *gin.Engine {
 	router := gin.Default()
@@ -27,6 +35,8 @@ func SetupRouter(
 	api := router.Group("/api/v1")
 	{
 		api.GET("/health", healthController.GetHealth)
+		api.GET("/files/*key", fileController.GetFile)
+		api.GET("/agreements/verify/:hash", agreementController.VerifyAgreement)
 		
 		authRoutes := api.Group("/auth")
 		{
@@ -46,6 +56,7 @@ func SetupRouter(
 
 			landLordCheckMiddleWare := middlewares.RoleCheckMiddleware(string(models.LandLord))
 			tenantCheckMiddleWare := middlewares.RoleCheckMiddleware(string(models.Tenant))
+			adminCheckMiddleWare := middlewares.RoleCheckMiddleware(string(models.Admin))
 
 			rentRoutes := protectedRoutes.Group("/rents")
 			{
@@ -54,14 +65,94 @@ func SetupRouter(
 				rentRoutes.PUT("/:rent_id", landLordCheckMiddleWare, rentController.UpdateRent)
 				rentRoutes.GET("", rentController.GetAllRents)
 				rentRoutes.GET("/:rent_id", rentController.GetRentById)
+				rentRoutes.GET("/:rent_id/ledger", rentRecordController.GetRentLedger)
+				rentRoutes.GET("/:rent_id/summary", rentController.SummariseRent)
+				rentRoutes.GET("/:rent_id/amendments", rentController.GetRentAmendments)
+				rentRoutes.POST("/:rent_id/amendments/:amendment_id/acknowledge", tenantCheckMiddleWare, rentController.AcknowledgeAmendment)
+				rentRoutes.POST("/:rent_id/termination", rentController.TerminateRent)
+				rentRoutes.POST("/:rent_id/agreements", landLordCheckMiddleWare, agreementController.GenerateAgreement)
+				rentRoutes.GET("/:rent_id/agreements", agreementController.GetAgreements)
+				rentRoutes.GET("/:rent_id/agreements/:agreement_id/document", agreementController.DownloadAgreement)
+				rentRoutes.POST("/:rent_id/agreements/:agreement_id/signatures/otp", agreementController.RequestSignatureOTP)
+				rentRoutes.POST("/:rent_id/agreements/:agreement_id/signatures", agreementController.SignAgreement)
 			}
 			rentRecordRoutes := protectedRoutes.Group("/rents/:rent_id/records")
 			{
 				rentRecordRoutes.POST("", tenantCheckMiddleWare, rentRecordController.CreateRentRecord)
+				rentRecordRoutes.POST("/offline", landLordCheckMiddleWare, rentRecordController.CreateLandLordRentRecord)
 				rentRecordRoutes.GET("", rentRecordController.GetAllRentRecords)
 				rentRecordRoutes.GET("/:record_id", rentRecordController.GetRentRecordById)
+				rentRecordRoutes.PUT("/:record_id", tenantCheckMiddleWare, rentRecordController.EditRentRecord)
+				rentRecordRoutes.DELETE("/:record_id", tenantCheckMiddleWare, rentRecordController.WithdrawRentRecord)
 				rentRecordRoutes.POST("/:record_id/approve", landLordCheckMiddleWare, rentRecordController.ApproveRentRecord)
 				rentRecordRoutes.POST("/:record_id/reject", landLordCheckMiddleWare, rentRecordController.RejectRentRecord)
+				rentRecordRoutes.POST("/:record_id/reverse", landLordCheckMiddleWare, rentRecordController.ReverseRentRecord)
+				rentRecordRoutes.POST("/:record_id/resubmit", tenantCheckMiddleWare, rentRecordController.ResubmitRentRecord)
+				rentRecordRoutes.POST("/:record_id/confirm", tenantCheckMiddleWare, rentRecordController.ConfirmRentRecord)
+				rentRecordRoutes.POST("/:record_id/dispute", tenantCheckMiddleWare, rentRecordController.DisputeRentRecord)
+				rentRecordRoutes.POST("/:record_id/attachments", rentRecordController.UploadAttachment)
+				rentRecordRoutes.GET("/:record_id/attachments/:attachment_id", rentRecordController.GetAttachment)
+				rentRecordRoutes.GET("/:record_id/receipt", rentRecordController.GetReceipt)
+				rentRecordRoutes.POST("/:record_id/disputes", tenantCheckMiddleWare, disputeController.OpenDispute)
+				rentRecordRoutes.GET("/:record_id/disputes", disputeController.GetRecordDisputes)
+			}
+			disputeRoutes := protectedRoutes.Group("/disputes")
+			{
+				disputeRoutes.GET("", disputeController.GetDisputes)
+				disputeRoutes.GET("/:dispute_id", disputeController.GetDisputeById)
+				disputeRoutes.POST("/:dispute_id/messages", disputeController.AddDisputeMessage)
+				disputeRoutes.POST("/:dispute_id/review", landLordCheckMiddleWare, disputeController.ReviewDispute)
+				disputeRoutes.POST("/:dispute_id/resolve", landLordCheckMiddleWare, disputeController.ResolveDispute)
+			}
+			rentMaintenanceRoutes := protectedRoutes.Group("/rents/:rent_id/maintenance-requests")
+			{
+				rentMaintenanceRoutes.POST("", tenantCheckMiddleWare, maintenanceController.CreateMaintenanceRequest)
+				rentMaintenanceRoutes.GET("", maintenanceController.GetRentMaintenanceRequests)
+				rentMaintenanceRoutes.GET("/:request_id", maintenanceController.GetMaintenanceRequestById)
+				rentMaintenanceRoutes.POST("/:request_id/status", landLordCheckMiddleWare, maintenanceController.UpdateMaintenanceStatus)
+				rentMaintenanceRoutes.POST("/:request_id/comments", maintenanceController.AddMaintenanceComment)
+				rentMaintenanceRoutes.POST("/:request_id/photos", tenantCheckMiddleWare, maintenanceController.UploadMaintenancePhoto)
+				rentMaintenanceRoutes.GET("/:request_id/photos/:photo_id", maintenanceController.GetMaintenancePhoto)
+			}
+			maintenanceRoutes := protectedRoutes.Group("/maintenance-requests")
+			{
+				maintenanceRoutes.GET("", maintenanceController.GetMaintenanceRequests)
+			}
+			batchRentRecordRoutes := protectedRoutes.Group("/rent-records", landLordCheckMiddleWare)
+			{
+				batchRentRecordRoutes.POST("/batch", rentRecordController.BatchReviewRentRecords)
+			}
+			propertyRoutes := protectedRoutes.Group("/properties", landLordCheckMiddleWare)
+			{
+				propertyRoutes.POST("", propertyController.CreateProperty)
+				propertyRoutes.GET("", propertyController.GetAllProperties)
+				propertyRoutes.GET("/:property_id", propertyController.GetPropertyById)
+				propertyRoutes.PUT("/:property_id", propertyController.UpdateProperty)
+				propertyRoutes.DELETE("/:property_id", propertyController.DeleteProperty)
+				propertyRoutes.GET("/:property_id/occupancy", propertyController.GetPropertyOccupancy)
+			}
+			unitRoutes := protectedRoutes.Group("/properties/:property_id/units", landLordCheckMiddleWare)
+			{
+				unitRoutes.POST("", unitController.CreateUnit)
+				unitRoutes.GET("", unitController.GetUnitsByProperty)
+				unitRoutes.GET("/:unit_id", unitController.GetUnitById)
+				unitRoutes.PUT("/:unit_id", unitController.UpdateUnit)
+				unitRoutes.DELETE("/:unit_id", unitController.DeleteUnit)
+			}
+			dashboardRoutes := protectedRoutes.Group("/dashboard")
+			{
+				dashboardRoutes.GET("/landlord", dashboardController.GetLandLordDashboard)
+				dashboardRoutes.GET("/tenant", dashboardController.GetTenantDashboard)
+			}
+			agreementTemplateRoutes := protectedRoutes.Group("/agreement-templates", landLordCheckMiddleWare)
+			{
+				agreementTemplateRoutes.POST("", agreementController.CreateAgreementTemplate)
+				agreementTemplateRoutes.GET("", agreementController.GetAgreementTemplates)
+			}
+			exchangeRateRoutes := protectedRoutes.Group("/exchange-rates")
+			{
+				exchangeRateRoutes.POST("", adminCheckMiddleWare, exchangeRateController.CreateExchangeRate)
+				exchangeRateRoutes.GET("", exchangeRateController.GetExchangeRates)
 			}
 		}
 	}
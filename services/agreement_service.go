@@ -0,0 +1,547 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/events"
+	"sample-web/mappers"
+	"sample-web/models"
+	"sample-web/repositories"
+	"sample-web/storage"
+	"sample-web/utils"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type AgreementService interface {
+	CreateTemplate(ctx context.Context, landLordId string, templateRequest dto.AgreementTemplateRequest) (models.AgreementTemplate, error)
+	GetTemplates(ctx context.Context, landLordId string) (dto.AgreementTemplateResponse, error)
+	GenerateAgreement(ctx context.Context, landLordId string, rentId string, agreementRequest dto.AgreementRequest) (models.Agreement, error)
+	GetAgreements(ctx context.Context, userId string, rentId string) (dto.AgreementResponse, error)
+	GetAgreementDocument(ctx context.Context, userId string, rentId string, agreementId string) (models.Agreement, io.ReadCloser, error)
+	HandleRentCreated(ctx context.Context, event events.Event)
+	HandleRentTermsChanged(ctx context.Context, event events.Event)
+	RequestSignatureOTP(ctx context.Context, userId string, rentId string, agreementId string) error
+	SignAgreement(ctx context.Context, userId string, rentId string, agreementId string, code string, ipAddress string) (models.Agreement, error)
+	VerifyAgreement(ctx context.Context, hash string) (dto.AgreementVerificationResponse, error)
+}
+
+type agreementService struct {
+	agreementRepo repositories.AgreementRepository
+	rentRepo      repositories.RentRepository
+	userRepo      repositories.UserRepository
+	blobStorage   storage.BlobStorage
+	otpService    SignatureOTPService
+}
+
+func NewAgreementService(agreementRepo repositories.AgreementRepository, rentRepo repositories.RentRepository, userRepo repositories.UserRepository, blobStorage storage.BlobStorage, otpService SignatureOTPService) AgreementService {
+	return &agreementService{
+		agreementRepo: agreementRepo,
+		rentRepo:      rentRepo,
+		userRepo:      userRepo,
+		blobStorage:   blobStorage,
+		otpService:    otpService,
+	}
+}
+
+// CreateTemplate saves a new version of the agreement template of the landlord, the template has to
+// render the sample rent so a broken template is never used for a real one.
+func (a *agreementService) CreateTemplate(ctx context.Context, landLordId string, templateRequest dto.AgreementTemplateRequest) (models.AgreementTemplate, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementService.CreateTemplate")
+	defer span.End()
+
+	landLord, err := a.userRepo.FindUserById(spanCtx, landLordId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find landlord with %s", err.Error()))
+		return models.AgreementTemplate{}, err
+	}
+
+	tmpl, err := parseAgreementTemplate(templateRequest.Body)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to parse agreement template with %s", err.Error()))
+		return models.AgreementTemplate{}, fmt.Errorf("invalid template: %w", err)
+	}
+	if _, err := renderAgreement(tmpl, sampleAgreementData()); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to render agreement template with %s", err.Error()))
+		return models.AgreementTemplate{}, fmt.Errorf("invalid template: %w", err)
+	}
+
+	version := 1
+	latest, err := a.agreementRepo.FindLatestTemplate(spanCtx, landLordId)
+	if err == nil {
+		version = latest.Version + 1
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find latest agreement template with %s", err.Error()))
+		return models.AgreementTemplate{}, err
+	}
+
+	// a concurrent save of the same version fails on the unique index
+	agreementTemplate, err := a.agreementRepo.CreateTemplate(spanCtx, models.AgreementTemplate{
+		LandLord: models.PersonRef{
+			Id:   landLord.Id,
+			Name: landLord.Name,
+		},
+		Version:   version,
+		Body:      templateRequest.Body,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to create agreement template with %s", err.Error()))
+		return models.AgreementTemplate{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Agreement template version %d saved for landlord %s", version, landLordId))
+
+	return agreementTemplate, nil
+}
+
+func (a *agreementService) GetTemplates(ctx context.Context, landLordId string) (dto.AgreementTemplateResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementService.GetTemplates")
+	defer span.End()
+
+	templates, err := a.agreementRepo.GetTemplates(spanCtx, landLordId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get agreement templates with %s", err.Error()))
+		return dto.AgreementTemplateResponse{}, err
+	}
+
+	if templates == nil {
+		templates = []models.AgreementTemplate{}
+	}
+
+	return dto.AgreementTemplateResponse{
+		Templates: templates,
+	}, nil
+}
+
+// GenerateAgreement renders an agreement of the rent, earlier agreements are kept so the ones that
+// were shared stay downloadable. A signed agreement is only replaced once the terms changed.
+func (a *agreementService) GenerateAgreement(ctx context.Context, landLordId string, rentId string, agreementRequest dto.AgreementRequest) (models.Agreement, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementService.GenerateAgreement")
+	defer span.End()
+
+	rent, err := a.rentRepo.FindRentById(spanCtx, landLordId, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent with %s", err.Error()))
+		return models.Agreement{}, err
+	}
+
+	if rent.LandLord.Id.Hex() != landLordId {
+		log.Error(spanCtx, fmt.Sprintf("User %s is not the landlord of rent %s", landLordId, rentId))
+		return models.Agreement{}, errors.New("only the landlord can generate the agreement")
+	}
+
+	if rent.Status == models.RentStatusClosed {
+		log.Error(spanCtx, fmt.Sprintf("Rent %s is closed", rentId))
+		return models.Agreement{}, errors.New("cannot generate an agreement for a closed rent")
+	}
+
+	if rent.AgreementStatus == models.AgreementStatusSigned {
+		log.Error(spanCtx, fmt.Sprintf("Rent %s already has a signed agreement", rentId))
+		return models.Agreement{}, errors.New("the agreement of the rent is already signed and its terms are unchanged")
+	}
+
+	return a.generateAgreement(spanCtx, rent, agreementRequest.TemplateVersion)
+}
+
+func (a *agreementService) GetAgreements(ctx context.Context, userId string, rentId string) (dto.AgreementResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementService.GetAgreements")
+	defer span.End()
+
+	// finding the rent makes sure the user is a party of it
+	if _, err := a.rentRepo.FindRentById(spanCtx, userId, rentId); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent with %s", err.Error()))
+		return dto.AgreementResponse{}, err
+	}
+
+	agreements, err := a.agreementRepo.GetAgreementsByRent(spanCtx, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get agreements with %s", err.Error()))
+		return dto.AgreementResponse{}, err
+	}
+
+	if agreements == nil {
+		agreements = []models.Agreement{}
+	}
+
+	return dto.AgreementResponse{
+		Agreements: agreements,
+	}, nil
+}
+
+func (a *agreementService) GetAgreementDocument(ctx context.Context, userId string, rentId string, agreementId string) (models.Agreement, io.ReadCloser, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementService.GetAgreementDocument")
+	defer span.End()
+
+	if _, err := a.rentRepo.FindRentById(spanCtx, userId, rentId); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent with %s", err.Error()))
+		return models.Agreement{}, nil, err
+	}
+
+	agreement, err := a.agreementRepo.FindAgreementById(spanCtx, rentId, agreementId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find agreement with %s", err.Error()))
+		return models.Agreement{}, nil, err
+	}
+
+	body, err := a.blobStorage.Get(spanCtx, agreement.StorageKey)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get agreement document with %s", err.Error()))
+		return models.Agreement{}, nil, err
+	}
+
+	return agreement, body, nil
+}
+
+// HandleRentCreated drafts the agreement of a new rent with the latest template of its landlord, the
+// landlord can generate it again when this fails.
+func (a *agreementService) HandleRentCreated(ctx context.Context, event events.Event) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementService.HandleRentCreated")
+	defer span.End()
+
+	data, ok := event.Data.(events.RentCreatedData)
+	if !ok {
+		log.Error(spanCtx, fmt.Sprintf("Unexpected payload %T for %s", event.Data, event.Name))
+		return
+	}
+
+	rent, err := a.rentRepo.FindRentById(spanCtx, data.LandLordId.Hex(), data.RentId.Hex())
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent %s with %s", data.RentId.Hex(), err.Error()))
+		return
+	}
+
+	if _, err := a.generateAgreement(spanCtx, rent, 0); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to generate agreement of rent %s with %s", data.RentId.Hex(), err.Error()))
+	}
+}
+
+// HandleRentTermsChanged supersedes the agreements of the rent once what the tenants pay or the end
+// date changed, pending ones can no longer be signed and the landlord can generate a new one.
+func (a *agreementService) HandleRentTermsChanged(ctx context.Context, event events.Event) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementService.HandleRentTermsChanged")
+	defer span.End()
+
+	data, ok := event.Data.(events.RentTermsChangedData)
+	if !ok {
+		log.Error(spanCtx, fmt.Sprintf("Unexpected payload %T for %s", event.Data, event.Name))
+		return
+	}
+
+	if !supersedesAgreement(data.Fields) {
+		return
+	}
+
+	superseded, err := a.agreementRepo.SupersedePendingAgreements(spanCtx, data.RentId, event.OccurredAt)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to supersede pending agreements of rent %s with %s", data.RentId.Hex(), err.Error()))
+		return
+	}
+
+	if err := a.rentRepo.SupersedeAgreement(spanCtx, data.RentId, event.OccurredAt); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to supersede signed agreement of rent %s with %s", data.RentId.Hex(), err.Error()))
+		return
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Agreements of rent %s superseded after %v changed, %d pending ones", data.RentId.Hex(), data.Fields, superseded))
+}
+
+// generateAgreement renders the agreement with the given template version, or the latest one when
+// it is zero. Landlords without a template get the built in one.
+func (a *agreementService) generateAgreement(ctx context.Context, rent models.Rent, templateVersion int) (models.Agreement, error) {
+
+	log := utils.GetLogger()
+
+	landLordId := rent.LandLord.Id.Hex()
+
+	var agreementTemplate models.AgreementTemplate
+	var err error
+	if templateVersion > 0 {
+		agreementTemplate, err = a.agreementRepo.FindTemplate(ctx, landLordId, templateVersion)
+	} else {
+		agreementTemplate, err = a.agreementRepo.FindLatestTemplate(ctx, landLordId)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			agreementTemplate, err = models.AgreementTemplate{Body: defaultAgreementTemplate}, nil
+		}
+	}
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to find agreement template with %s", err.Error()))
+		return models.Agreement{}, err
+	}
+
+	tmpl, err := parseAgreementTemplate(agreementTemplate.Body)
+	if err != nil {
+		return models.Agreement{}, err
+	}
+
+	landLord, err := a.userRepo.FindUserById(ctx, landLordId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to find landlord with %s", err.Error()))
+		return models.Agreement{}, err
+	}
+
+	now := time.Now()
+	content, err := renderAgreement(tmpl, newAgreementData(rent, landLord.PhoneNumber, now))
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to render agreement with %s", err.Error()))
+		return models.Agreement{}, err
+	}
+
+	hash := sha256.Sum256(content)
+	agreement := models.Agreement{
+		Id:              bson.NewObjectID(),
+		RentId:          rent.Id,
+		LandLord:        rent.LandLord,
+		TemplateVersion: agreementTemplate.Version,
+		Hash:            hex.EncodeToString(hash[:]),
+		Status:          models.AgreementStatusPendingSignatures,
+		CreatedAt:       now,
+	}
+	agreement.StorageKey = fmt.Sprintf("agreements/%s/%s.pdf", rent.Id.Hex(), agreement.Id.Hex())
+
+	if err := a.blobStorage.Put(ctx, agreement.StorageKey, "application/pdf", bytes.NewReader(content)); err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to store agreement with %s", err.Error()))
+		return models.Agreement{}, err
+	}
+
+	createdAgreement, err := a.agreementRepo.CreateAgreement(ctx, agreement)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to create agreement with %s", err.Error()))
+		return models.Agreement{}, err
+	}
+
+	log.Info(ctx, fmt.Sprintf("Agreement %s generated for rent %s with template version %d", createdAgreement.Id.Hex(), rent.Id.Hex(), agreementTemplate.Version))
+
+	return createdAgreement, nil
+}
+
+// RequestSignatureOTP sends the OTP that confirms the signature of the user to their phone number,
+// the OTP only confirms the document of this agreement.
+func (a *agreementService) RequestSignatureOTP(ctx context.Context, userId string, rentId string, agreementId string) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementService.RequestSignatureOTP")
+	defer span.End()
+
+	_, agreement, signer, _, err := a.findSignableAgreement(spanCtx, userId, rentId, agreementId)
+	if err != nil {
+		return err
+	}
+
+	if err := a.otpService.SendOTP(spanCtx, signer.PhoneNumber, agreement.Hash); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to send signature OTP with %s", err.Error()))
+		return err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Signature OTP sent to user %s for agreement %s", userId, agreementId))
+
+	return nil
+}
+
+// SignAgreement records the signature of the user once the OTP is verified, the agreement and the
+// rent are marked signed with the last signature.
+func (a *agreementService) SignAgreement(ctx context.Context, userId string, rentId string, agreementId string, code string, ipAddress string) (models.Agreement, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementService.SignAgreement")
+	defer span.End()
+
+	rent, agreement, signer, role, err := a.findSignableAgreement(spanCtx, userId, rentId, agreementId)
+	if err != nil {
+		return models.Agreement{}, err
+	}
+
+	verified, err := a.otpService.VerifyOTP(spanCtx, signer.PhoneNumber, agreement.Hash, code)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to verify signature OTP with %s", err.Error()))
+		var blockedErr *PhoneNumberBlockedError
+		if errors.As(err, &blockedErr) {
+			return models.Agreement{}, err
+		}
+		return models.Agreement{}, customerr.InvalidOTPError{Reason: err.Error()}
+	}
+	if !verified {
+		return models.Agreement{}, customerr.InvalidOTPError{Reason: "code does not match"}
+	}
+
+	// the signature covers the document as it is stored, not just the hash recorded at generation
+	documentHash, err := a.documentHash(spanCtx, agreement)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to hash agreement document with %s", err.Error()))
+		return models.Agreement{}, err
+	}
+	if documentHash != agreement.Hash {
+		log.Error(spanCtx, fmt.Sprintf("Stored document of agreement %s does not match its hash", agreementId))
+		return models.Agreement{}, errors.New("agreement document does not match its hash")
+	}
+
+	now := time.Now()
+	signedAgreement, err := a.agreementRepo.AddSignature(spanCtx, agreement.Id, models.AgreementSignature{
+		Signer: models.PersonRef{
+			Id:   signer.Id,
+			Name: signer.Name,
+		},
+		Role:         role,
+		PhoneNumber:  signer.PhoneNumber,
+		DocumentHash: documentHash,
+		IPAddress:    ipAddress,
+		SignedAt:     now,
+	})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Agreement{}, customerr.AgreementAlreadySignedError{AgreementId: agreementId}
+	}
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to add signature with %s", err.Error()))
+		return models.Agreement{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Agreement %s signed by %s %s", agreementId, role, userId))
+
+	if !signedByAllParties(rent, signedAgreement) {
+		return signedAgreement, nil
+	}
+
+	completed, err := a.agreementRepo.MarkSigned(spanCtx, agreement.Id, now)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to mark agreement signed with %s", err.Error()))
+		return models.Agreement{}, err
+	}
+	if completed {
+		if err := a.rentRepo.SetAgreementSigned(spanCtx, rent.Id, agreement.Id, now); err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to mark rent signed with %s", err.Error()))
+			return models.Agreement{}, err
+		}
+		log.Info(spanCtx, fmt.Sprintf("Rent %s signed with agreement %s", rentId, agreementId))
+	}
+
+	return a.agreementRepo.FindAgreementById(spanCtx, rentId, agreementId)
+}
+
+// VerifyAgreement looks up the agreement a PDF belongs to by the hash of the file.
+func (a *agreementService) VerifyAgreement(ctx context.Context, hash string) (dto.AgreementVerificationResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "AgreementService.VerifyAgreement")
+	defer span.End()
+
+	agreement, err := a.agreementRepo.FindAgreementByHash(spanCtx, strings.ToLower(hash))
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find agreement by hash with %s", err.Error()))
+		return dto.AgreementVerificationResponse{}, err
+	}
+
+	return mappers.ToAgreementVerificationResponse(agreement), nil
+}
+
+// findSignableAgreement returns the agreement the user can sign along with the rent, the user and
+// the role they sign in. Only the latest agreement of a rent can be signed.
+func (a *agreementService) findSignableAgreement(ctx context.Context, userId string, rentId string, agreementId string) (models.Rent, models.Agreement, models.User, models.UserRole, error) {
+
+	log := utils.GetLogger()
+
+	rent, err := a.rentRepo.FindRentById(ctx, userId, rentId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to find rent with %s", err.Error()))
+		return models.Rent{}, models.Agreement{}, models.User{}, "", err
+	}
+
+	agreements, err := a.agreementRepo.GetAgreementsByRent(ctx, rentId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to get agreements with %s", err.Error()))
+		return models.Rent{}, models.Agreement{}, models.User{}, "", err
+	}
+	if len(agreements) == 0 || agreements[0].Id.Hex() != agreementId {
+		log.Error(ctx, fmt.Sprintf("Agreement %s is not the latest agreement of rent %s", agreementId, rentId))
+		return models.Rent{}, models.Agreement{}, models.User{}, "", mongo.ErrNoDocuments
+	}
+	agreement := agreements[0]
+
+	if agreement.Status != models.AgreementStatusPendingSignatures {
+		return models.Rent{}, models.Agreement{}, models.User{}, "", errors.New("agreement is not waiting for signatures")
+	}
+
+	signer, err := a.userRepo.FindUserById(ctx, userId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to find user with %s", err.Error()))
+		return models.Rent{}, models.Agreement{}, models.User{}, "", err
+	}
+
+	role := models.Tenant
+	if rent.LandLord.Id == signer.Id {
+		role = models.LandLord
+	} else if _, ok := rent.FindTenant(signer.Id); !ok {
+		return models.Rent{}, models.Agreement{}, models.User{}, "", errors.New("only the landlord and co-tenants can sign the agreement")
+	}
+
+	if agreement.IsSignedBy(signer.Id) {
+		return models.Rent{}, models.Agreement{}, models.User{}, "", customerr.AgreementAlreadySignedError{AgreementId: agreementId}
+	}
+
+	return rent, agreement, signer, role, nil
+}
+
+func (a *agreementService) documentHash(ctx context.Context, agreement models.Agreement) (string, error) {
+	body, err := a.blobStorage.Get(ctx, agreement.StorageKey)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// supersedesAgreement reports whether the amended fields change what the tenants agreed to pay or
+// until when.
+func supersedesAgreement(fields []string) bool {
+	if affectsMoney(fields) {
+		return true
+	}
+	for _, field := range fields {
+		if field == "end_date" {
+			return true
+		}
+	}
+	return false
+}
+
+// signedByAllParties reports whether the landlord and every co-tenant signed, co-tenants that have
+// not registered yet cannot sign so they hold the agreement back.
+func signedByAllParties(rent models.Rent, agreement models.Agreement) bool {
+	if !agreement.IsSignedBy(rent.LandLord.Id) {
+		return false
+	}
+	for _, tenant := range rent.Tenants {
+		if tenant.Invited || !agreement.IsSignedBy(tenant.Id) {
+			return false
+		}
+	}
+	return true
+}
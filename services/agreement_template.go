@@ -0,0 +1,184 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"sample-web/models"
+	"sample-web/pdf"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// agreementData is what agreement templates are executed with.
+type agreementData struct {
+	Rent         models.Rent
+	LandLord     agreementParty
+	Tenants      []agreementParty
+	Schedule     string
+	DueDates     []time.Time
+	Installments int
+	Date         time.Time
+}
+
+type agreementParty struct {
+	Name        string
+	PhoneNumber string
+	Share       string
+	Amount      models.Money
+}
+
+var agreementFuncs = template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.Format("02 Jan 2006")
+	},
+	"money": func(m models.Money) string {
+		return m.String() + " " + m.Currency
+	},
+	"inc": func(i int) int {
+		return i + 1
+	},
+}
+
+// defaultAgreementTemplate is used for landlords that have not saved a template of their own.
+const defaultAgreementTemplate = `# Rental Agreement
+
+This rental agreement is made on {{date .Date}} between {{.LandLord.Name}} ({{.LandLord.PhoneNumber}}), the landlord, and {{range $i, $tenant := .Tenants}}{{if $i}}, {{end}}{{$tenant.Name}} ({{$tenant.PhoneNumber}}){{end}}, the tenants, for {{.Rent.Title}}{{if .Rent.Unit}}, unit {{.Rent.Unit.UnitNumber}}{{end}}.
+
+# Term
+
+The tenancy starts on {{date .Rent.StartDate}} and ends on {{date .Rent.EndDate}}.
+
+# Rent
+
+The rent is {{money .Rent.Amount}}, payable {{.Schedule}} in {{.Installments}} installments, the first of which is due on {{if .DueDates}}{{date (index .DueDates 0)}}{{else}}{{date .Rent.StartDate}}{{end}}.
+{{range .Tenants}}
+{{.Name}} pays {{.Share}} of the rent, which is {{money .Amount}} per installment.
+{{end}}
+# Signatures
+
+Landlord: {{.LandLord.Name}}
+{{range .Tenants}}
+Tenant: {{.Name}}
+{{end}}`
+
+func parseAgreementTemplate(body string) (*template.Template, error) {
+	return template.New("agreement").Funcs(agreementFuncs).Option("missingkey=error").Parse(body)
+}
+
+// newAgreementData collects the terms of the rent for its agreement, the landlord phone number is
+// not part of the rent so it is passed separately. Names and titles a PDF cannot show are replaced
+// by the phone number of the party and the ID of the rent, the template itself is checked when saved.
+func newAgreementData(rent models.Rent, landLordPhoneNumber string, at time.Time) agreementData {
+	dueDates := installmentDueDates(rent)
+
+	rent.Title = printable(rent.Title, "rent "+rent.Id.Hex())
+	if rent.Unit != nil {
+		unit := *rent.Unit
+		unit.UnitNumber = printable(unit.UnitNumber, "-")
+		rent.Unit = &unit
+	}
+
+	data := agreementData{
+		Rent: rent,
+		LandLord: agreementParty{
+			Name:        printable(rent.LandLord.Name, landLordPhoneNumber),
+			PhoneNumber: landLordPhoneNumber,
+		},
+		Schedule:     scheduleDescription(rent),
+		DueDates:     dueDates,
+		Installments: len(dueDates),
+		Date:         at,
+	}
+	for _, tenant := range rent.Tenants {
+		data.Tenants = append(data.Tenants, agreementParty{
+			Name:        printable(tenant.Name, tenant.PhoneNumber),
+			PhoneNumber: tenant.PhoneNumber,
+			Share:       shareDescription(tenant.Share),
+			Amount:      rent.ShareAmount(tenant),
+		})
+	}
+	return data
+}
+
+// sampleAgreementData is used to check a template renders before it is saved.
+func sampleAgreementData() agreementData {
+	start := time.Now().AddDate(0, 1, 0)
+	rent := models.Rent{
+		LandLord: models.PersonRef{Id: bson.NewObjectID(), Name: "Landlord"},
+		Tenants: []models.RentTenant{{
+			Id:          bson.NewObjectID(),
+			Name:        "Tenant",
+			PhoneNumber: "+910000000000",
+			Share:       models.TenantShare{Type: models.ShareTypePercentage, Percentage: 10000},
+		}},
+		Unit:      &models.UnitRef{UnitNumber: "1A"},
+		Title:     "Sample rent",
+		Amount:    models.NewMoney(1000000, "INR"),
+		Schedule:  models.RentScheduleMonthly,
+		Status:    models.RentStatusUpcoming,
+		StartDate: start,
+		EndDate:   start.AddDate(1, 0, 0),
+	}
+	return newAgreementData(rent, "+910000000001", time.Now())
+}
+
+func shareDescription(share models.TenantShare) string {
+	if share.Type == models.ShareTypePercentage {
+		return share.String() + "%"
+	}
+	if share.Amount == nil {
+		return ""
+	}
+	return "a fixed amount of " + share.Amount.String() + " " + share.Amount.Currency
+}
+
+func scheduleDescription(rent models.Rent) string {
+	switch rent.Schedule {
+	case models.RentScheduleHalfYearly:
+		return "half-yearly"
+	case models.RentScheduleCustom:
+		if rent.Interval == nil {
+			return "as agreed"
+		}
+		return fmt.Sprintf("every %d %s", rent.Interval.Every, rent.Interval.Unit)
+	default:
+		return string(rent.Schedule)
+	}
+}
+
+// renderAgreement executes the template and lays the text out as a PDF, lines starting with "# "
+// become headings.
+func renderAgreement(tmpl *template.Template, data agreementData) ([]byte, error) {
+	var text bytes.Buffer
+	if err := tmpl.Execute(&text, data); err != nil {
+		return nil, err
+	}
+
+	document := pdf.NewDocument(data.Rent.Title + " rental agreement")
+
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			document.Paragraph(strings.Join(paragraph, "\n"))
+			document.Space(6)
+			paragraph = nil
+		}
+	}
+	for _, line := range strings.Split(text.String(), "\n") {
+		switch {
+		case strings.HasPrefix(line, "# "):
+			flush()
+			document.Heading(strings.TrimPrefix(line, "# "))
+		case strings.TrimSpace(line) == "":
+			flush()
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+
+	return document.Bytes()
+}
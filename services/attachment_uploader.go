@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sample-web/configs"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/models"
+	"sample-web/storage"
+	"sample-web/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// attachmentUploader stores the files users upload in the blob storage and signs the URLs they are
+// downloaded with, it is shared by the services that take attachments.
+type attachmentUploader struct {
+	blobStorage   storage.BlobStorage
+	storageConfig configs.StorageConfig
+}
+
+func newAttachmentUploader(blobStorage storage.BlobStorage, storageConfig configs.StorageConfig) attachmentUploader {
+	return attachmentUploader{
+		blobStorage:   blobStorage,
+		storageConfig: storageConfig,
+	}
+}
+
+// upload stores the file under keyPrefix when its size is within the limit and its content type is
+// one of extensions, then calls link to point the owning document at it. The file is deleted again
+// when link fails and the error of link is returned as is.
+func (u attachmentUploader) upload(ctx context.Context, keyPrefix string, fileName string, body io.Reader, extensions map[string]string, uploadedBy models.PersonRef, link func(models.Attachment) error) (models.Attachment, error) {
+
+	log := utils.GetLogger()
+
+	// read one byte more than allowed to tell a file of exactly the maximum size from a larger one
+	maxBytes := u.storageConfig.MaxAttachmentSizeInBytes
+	content, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Error reading attachment: %v", err))
+		return models.Attachment{}, err
+	}
+	if int64(len(content)) > maxBytes {
+		return models.Attachment{}, customerr.AttachmentTooLargeError{MaxBytes: maxBytes}
+	}
+	if len(content) == 0 {
+		return models.Attachment{}, errors.New("attachment is empty")
+	}
+
+	// the content type is sniffed from the file, the one sent by the client cannot be trusted
+	contentType := http.DetectContentType(content)
+	extension, ok := extensions[contentType]
+	if !ok {
+		return models.Attachment{}, customerr.UnsupportedContentTypeError{ContentType: contentType}
+	}
+
+	attachment := models.Attachment{
+		Id:          bson.NewObjectID(),
+		FileName:    path.Base(fileName),
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		UploadedBy:  uploadedBy,
+		UploadedAt:  time.Now(),
+	}
+	attachment.StorageKey = fmt.Sprintf("%s/%s%s", keyPrefix, attachment.Id.Hex(), extension)
+
+	if err := u.blobStorage.Put(ctx, attachment.StorageKey, contentType, bytes.NewReader(content)); err != nil {
+		log.Error(ctx, fmt.Sprintf("Error storing attachment: %v", err))
+		return models.Attachment{}, err
+	}
+
+	if err := link(attachment); err != nil {
+		// the file is useless without a document pointing at it
+		if deleteErr := u.blobStorage.Delete(ctx, attachment.StorageKey); deleteErr != nil {
+			log.Error(ctx, fmt.Sprintf("Error deleting orphaned attachment %s: %v", attachment.StorageKey, deleteErr))
+		}
+		return models.Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+// toAttachmentResponse returns the attachment with a signed URL to download it.
+func (u attachmentUploader) toAttachmentResponse(ctx context.Context, attachment models.Attachment) (dto.AttachmentResponse, error) {
+	expiresIn := time.Duration(u.storageConfig.SignedURLExpirationInSeconds) * time.Second
+
+	url, err := u.blobStorage.SignedURL(ctx, attachment.StorageKey, expiresIn)
+	if err != nil {
+		return dto.AttachmentResponse{}, err
+	}
+
+	return dto.AttachmentResponse{
+		Attachment: attachment,
+		URL:        url,
+		ExpiresAt:  time.Now().Add(expiresIn).Format(time.RFC3339),
+	}, nil
+}
@@ -21,12 +21,14 @@ type AuthService interface {
 
 type authService struct {
 	userRepo   repositories.UserRepository
+	rentRepo   repositories.RentRepository
 	jwtService JWTService
 }
 
-func NewAuthService(userRepo repositories.UserRepository, jwtSrv JWTService) AuthService {
+func NewAuthService(userRepo repositories.UserRepository, rentRepo repositories.RentRepository, jwtSrv JWTService) AuthService {
 	return &authService{
 		userRepo:   userRepo,
+		rentRepo:   rentRepo,
 		jwtService: jwtSrv,
 	}
 }
@@ -119,10 +121,13 @@ func (a *authService) Register(ctx context.Context, registerRequest dto.Register
 
 	now := time.Now()
 
+	currentRole := mappers.ToUserRole(registerRequest.CurrentRole)
+
 	user := models.User{
 		Name:        registerRequest.Name,
 		PhoneNumber: registerRequest.PhoneNumber,
-		CurrentRole: mappers.ToUserRole(registerRequest.CurrentRole),
+		Roles:       []models.UserRole{currentRole},
+		CurrentRole: currentRole,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -138,6 +143,29 @@ func (a *authService) Register(ctx context.Context, registerRequest dto.Register
 
 	log.Info(spanCtx, "User created successfully")
 
+	log.Info(spanCtx, "Attaching user to rents they were invited to")
+
+	attached, err := a.rentRepo.AttachInvitedTenant(spanCtx, user.PhoneNumber, models.PersonRef{
+		Id:   user.Id,
+		Name: user.Name,
+	})
+
+	if err != nil {
+		// the user is registered already, the invite can be attached on a later retry
+		log.Error(spanCtx, fmt.Sprintf("Failed to attach invited rents with %s", err.Error()))
+	} else {
+		log.Info(spanCtx, fmt.Sprintf("Attached user to %d invited rents", attached))
+	}
+
+	if attached > 0 && !user.HasRole(models.Tenant) {
+		// a landlord who was invited to a rent is a tenant as well
+		user.AddRole(models.Tenant)
+		if user, err = a.userRepo.UpdateUser(spanCtx, user); err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to add tenant role with %s", err.Error()))
+			return dto.UserResponse{}, err
+		}
+	}
+
 	log.Info(spanCtx, "Mapping user to response")
 
 	userResponse := mappers.ToUserResponse(user)
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"math/big"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/models"
+	"time"
+)
+
+// CurrencyConverter converts amounts to a report currency with the exchange rate in effect on the
+// date of each amount.
+type CurrencyConverter struct {
+	reportCurrency string
+	// rates holds the rates from or to the report currency by the other currency, oldest first
+	rates map[string][]models.ExchangeRate
+}
+
+func newCurrencyConverter(reportCurrency string, rates []models.ExchangeRate) *CurrencyConverter {
+	converter := &CurrencyConverter{
+		reportCurrency: reportCurrency,
+		rates:          make(map[string][]models.ExchangeRate),
+	}
+	for _, rate := range rates {
+		switch reportCurrency {
+		case rate.QuoteCurrency:
+			converter.rates[rate.BaseCurrency] = append(converter.rates[rate.BaseCurrency], rate)
+		case rate.BaseCurrency:
+			converter.rates[rate.QuoteCurrency] = append(converter.rates[rate.QuoteCurrency], rate)
+		}
+	}
+	return converter
+}
+
+func (c *CurrencyConverter) ReportCurrency() string {
+	return c.reportCurrency
+}
+
+// Convert converts the amount with the rate in effect at the given time. A rate stored in the
+// direction of the report currency wins over the inverse of the opposite one on the same day.
+func (c *CurrencyConverter) Convert(amount models.Money, at time.Time) (dto.ConvertedAmount, error) {
+	if amount.Currency == c.reportCurrency {
+		return dto.ConvertedAmount{Amount: amount, Rates: []dto.AppliedRate{}}, nil
+	}
+
+	var inEffect *models.ExchangeRate
+	for i, rate := range c.rates[amount.Currency] {
+		if rate.EffectiveFrom.After(at) {
+			break
+		}
+		if inEffect == nil || rate.EffectiveFrom.After(inEffect.EffectiveFrom) ||
+			(rate.EffectiveFrom.Equal(inEffect.EffectiveFrom) && rate.QuoteCurrency == c.reportCurrency) {
+			inEffect = &c.rates[amount.Currency][i]
+		}
+	}
+	if inEffect == nil {
+		return dto.ConvertedAmount{}, customerr.MissingExchangeRateError{
+			From: amount.Currency,
+			To:   c.reportCurrency,
+			At:   at.Format("2006-01-02"),
+		}
+	}
+
+	rate, ok := new(big.Rat).SetString(inEffect.Rate.String())
+	if !ok || rate.Sign() <= 0 {
+		return dto.ConvertedAmount{}, errors.New("invalid exchange rate " + inEffect.Rate.String())
+	}
+
+	inverted := inEffect.QuoteCurrency != c.reportCurrency
+	if inverted {
+		rate.Inv(rate)
+	}
+
+	return dto.ConvertedAmount{
+		Amount: amount.Convert(rate, c.reportCurrency),
+		Rates: []dto.AppliedRate{{
+			RateId:        inEffect.Id.Hex(),
+			BaseCurrency:  inEffect.BaseCurrency,
+			QuoteCurrency: inEffect.QuoteCurrency,
+			Rate:          inEffect.Rate.String(),
+			EffectiveFrom: inEffect.EffectiveFrom.Format("2006-01-02"),
+			Inverted:      inverted,
+		}},
+	}, nil
+}
+
+// Zero returns a zero amount in the report currency to sum converted amounts into.
+func (c *CurrencyConverter) Zero() dto.ConvertedAmount {
+	return dto.ConvertedAmount{
+		Amount: models.NewMoney(0, c.reportCurrency),
+		Rates:  []dto.AppliedRate{},
+	}
+}
+
+// addConverted adds a converted amount to a total, every rate used for the total is listed once.
+func addConverted(total dto.ConvertedAmount, converted dto.ConvertedAmount) dto.ConvertedAmount {
+	total.Amount = total.Amount.Add(converted.Amount)
+	for _, rate := range converted.Rates {
+		seen := false
+		for _, totalRate := range total.Rates {
+			if totalRate.RateId == rate.RateId {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			total.Rates = append(total.Rates, rate)
+		}
+	}
+	return total
+}
@@ -0,0 +1,471 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/models"
+	"sample-web/repositories"
+	"sample-web/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type DashboardService interface {
+	GetLandLordDashboard(ctx context.Context, landLordId string, from time.Time, to time.Time, reportCurrency string) (dto.LandLordDashboardResponse, error)
+	GetTenantDashboard(ctx context.Context, tenantId string, reportCurrency string) (dto.TenantDashboardResponse, error)
+}
+
+type dashboardService struct {
+	dashboardRepo       repositories.DashboardRepository
+	rentRepo            repositories.RentRepository
+	unitRepo            repositories.UnitRepository
+	userRepo            repositories.UserRepository
+	exchangeRateService ExchangeRateService
+}
+
+func NewDashboardService(dashboardRepo repositories.DashboardRepository, rentRepo repositories.RentRepository, unitRepo repositories.UnitRepository, userRepo repositories.UserRepository, exchangeRateService ExchangeRateService) DashboardService {
+	return &dashboardService{
+		dashboardRepo:       dashboardRepo,
+		rentRepo:            rentRepo,
+		unitRepo:            unitRepo,
+		userRepo:            userRepo,
+		exchangeRateService: exchangeRateService,
+	}
+}
+
+// maxPendingApprovalEntries caps the pending records listed on the landlord dashboard, the count
+// and totals still cover all of them.
+const maxPendingApprovalEntries = 50
+
+// GetLandLordDashboard builds the landlord dashboard, amounts are totalled per currency and also
+// converted to the report currency when one is given.
+func (d *dashboardService) GetLandLordDashboard(ctx context.Context, landLordId string, from time.Time, to time.Time, reportCurrency string) (dto.LandLordDashboardResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DashboardService.GetLandLordDashboard")
+	defer span.End()
+
+	if err := d.checkRole(spanCtx, landLordId, models.LandLord); err != nil {
+		return dto.LandLordDashboardResponse{}, err
+	}
+
+	if !from.Before(to) {
+		return dto.LandLordDashboardResponse{}, errors.New("from date must be before to date")
+	}
+
+	stats, err := d.dashboardRepo.GetLandLordRecordStats(spanCtx, landLordId, from, to, maxPendingApprovalEntries)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to aggregate rent records with %s", err.Error()))
+		return dto.LandLordDashboardResponse{}, err
+	}
+
+	rents, err := d.rentRepo.GetAllRents(spanCtx, landLordId, models.LandLord)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get rents with %s", err.Error()))
+		return dto.LandLordDashboardResponse{}, err
+	}
+
+	units, err := d.unitRepo.GetUnitsByLandLord(spanCtx, landLordId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get units with %s", err.Error()))
+		return dto.LandLordDashboardResponse{}, err
+	}
+
+	now := time.Now()
+
+	response := dto.LandLordDashboardResponse{
+		From:          from.Format("2006-01-02"),
+		To:            to.AddDate(0, 0, -1).Format("2006-01-02"),
+		IncomeByMonth: []dto.MonthlyIncomeEntry{},
+		TotalIncome:   []models.Money{},
+		PendingApprovals: dto.PendingApprovals{
+			Amount:  []models.Money{},
+			Records: make([]dto.PendingApprovalEntry, 0, len(stats.PendingApprovals)),
+		},
+		TotalArrears: []models.Money{},
+		Occupancy:    landLordOccupancy(units, rents, now),
+		ExpiringRents: dto.ExpiringRents{
+			Within30Days: []dto.ExpiringRentEntry{},
+			Within60Days: []dto.ExpiringRentEntry{},
+			Within90Days: []dto.ExpiringRentEntry{},
+		},
+	}
+
+	var converter *CurrencyConverter
+	if reportCurrency != "" {
+		converter, err = d.exchangeRateService.NewConverter(spanCtx, reportCurrency)
+		if err != nil {
+			return dto.LandLordDashboardResponse{}, err
+		}
+		response.Report = &dto.LandLordDashboardReport{
+			Currency:         reportCurrency,
+			IncomeByMonth:    []dto.ConvertedMonthlyIncome{},
+			TotalIncome:      converter.Zero(),
+			PendingApprovals: converter.Zero(),
+			TotalArrears:     converter.Zero(),
+		}
+	}
+
+	// income is aggregated per day so each day can be converted with the rate in effect on it
+	for _, income := range stats.DailyIncome {
+		month := income.Date[:len("2006-01")]
+		response.IncomeByMonth = addMonthlyIncome(response.IncomeByMonth, month, income)
+		response.TotalIncome = models.AddByCurrency(response.TotalIncome, income.Amount)
+
+		if converter == nil {
+			continue
+		}
+
+		paidOn, err := time.Parse("2006-01-02", income.Date)
+		if err != nil {
+			return dto.LandLordDashboardResponse{}, err
+		}
+		converted, err := converter.Convert(income.Amount, paidOn)
+		if err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to convert income with %s", err.Error()))
+			return dto.LandLordDashboardResponse{}, err
+		}
+
+		report := response.Report
+		if last := len(report.IncomeByMonth) - 1; last < 0 || report.IncomeByMonth[last].Month != month {
+			report.IncomeByMonth = append(report.IncomeByMonth, dto.ConvertedMonthlyIncome{
+				Month:  month,
+				Amount: converter.Zero(),
+			})
+		}
+		last := &report.IncomeByMonth[len(report.IncomeByMonth)-1]
+		last.Amount = addConverted(last.Amount, converted)
+		last.Payments += income.Payments
+		report.TotalIncome = addConverted(report.TotalIncome, converted)
+	}
+
+	rentTitles := make(map[bson.ObjectID]string, len(rents))
+	for _, rent := range rents {
+		rentTitles[rent.Id] = rent.Title
+	}
+
+	for _, rentRecord := range stats.PendingApprovals {
+		entry := dto.PendingApprovalEntry{
+			RecordId:      rentRecord.Id.Hex(),
+			RentId:        rentRecord.RentId.Hex(),
+			RentTitle:     rentTitles[rentRecord.RentId],
+			TenantId:      rentRecord.Tenant.Id.Hex(),
+			TenantName:    rentRecord.Tenant.Name,
+			Amount:        rentRecord.Amount,
+			PaymentMethod: string(rentRecord.PaymentMethod),
+			Reference:     rentRecord.Reference,
+			PaidAt:        rentRecord.PaymentDate().Format("2006-01-02"),
+			SubmittedAt:   rentRecord.SubmittedAt.Format(time.RFC3339),
+		}
+		if converter != nil {
+			converted, err := converter.Convert(rentRecord.Amount, rentRecord.PaymentDate())
+			if err != nil {
+				log.Error(spanCtx, fmt.Sprintf("Failed to convert pending record with %s", err.Error()))
+				return dto.LandLordDashboardResponse{}, err
+			}
+			entry.Converted = &converted
+		}
+		response.PendingApprovals.Records = append(response.PendingApprovals.Records, entry)
+	}
+
+	for _, pending := range stats.PendingByDay {
+		response.PendingApprovals.Count += pending.Payments
+		response.PendingApprovals.Amount = models.AddByCurrency(response.PendingApprovals.Amount, pending.Amount)
+
+		if converter == nil {
+			continue
+		}
+
+		paidOn, err := time.Parse("2006-01-02", pending.Date)
+		if err != nil {
+			return dto.LandLordDashboardResponse{}, err
+		}
+		converted, err := converter.Convert(pending.Amount, paidOn)
+		if err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to convert pending records with %s", err.Error()))
+			return dto.LandLordDashboardResponse{}, err
+		}
+		response.Report.PendingApprovals = addConverted(response.Report.PendingApprovals, converted)
+	}
+
+	approvedByRent := make(map[bson.ObjectID]int64, len(stats.ApprovedByRent))
+	for _, total := range stats.ApprovedByRent {
+		approvedByRent[total.RentId] = total.Approved
+	}
+
+	for _, rent := range rents {
+		if !isRentOpen(rent) {
+			continue
+		}
+
+		// arrears are the installments due so far that are not covered by approved records
+		due := rent.Amount.Times(installmentsDueBy(rent, now))
+		approved := models.NewMoney(approvedByRent[rent.Id], rent.Amount.Currency)
+		if arrears := due.Sub(approved); arrears.IsPositive() {
+			response.TotalArrears = models.AddByCurrency(response.TotalArrears, arrears)
+			if converter != nil {
+				converted, err := converter.Convert(arrears, now)
+				if err != nil {
+					log.Error(spanCtx, fmt.Sprintf("Failed to convert arrears with %s", err.Error()))
+					return dto.LandLordDashboardResponse{}, err
+				}
+				response.Report.TotalArrears = addConverted(response.Report.TotalArrears, converted)
+			}
+		}
+
+		if rent.EndDate.Before(now) {
+			continue
+		}
+
+		daysLeft := int(math.Ceil(rent.EndDate.Sub(now).Hours() / 24))
+		entry := dto.ExpiringRentEntry{
+			RentId:   rent.Id.Hex(),
+			Title:    rent.Title,
+			EndDate:  rent.EndDate.Format("2006-01-02"),
+			DaysLeft: daysLeft,
+		}
+
+		switch {
+		case daysLeft <= 30:
+			response.ExpiringRents.Within30Days = append(response.ExpiringRents.Within30Days, entry)
+		case daysLeft <= 60:
+			response.ExpiringRents.Within60Days = append(response.ExpiringRents.Within60Days, entry)
+		case daysLeft <= 90:
+			response.ExpiringRents.Within90Days = append(response.ExpiringRents.Within90Days, entry)
+		}
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Dashboard built for landlord %s with %d rents and %d units", landLordId, len(rents), len(units)))
+
+	return response, nil
+}
+
+// GetTenantDashboard builds the tenant dashboard, amounts are also converted to the report currency
+// when one is given.
+func (d *dashboardService) GetTenantDashboard(ctx context.Context, tenantId string, reportCurrency string) (dto.TenantDashboardResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DashboardService.GetTenantDashboard")
+	defer span.End()
+
+	if err := d.checkRole(spanCtx, tenantId, models.Tenant); err != nil {
+		return dto.TenantDashboardResponse{}, err
+	}
+
+	tenantObjectId, err := bson.ObjectIDFromHex(tenantId)
+	if err != nil {
+		return dto.TenantDashboardResponse{}, errors.New("invalid tenant id")
+	}
+
+	stats, err := d.dashboardRepo.GetTenantRecordStats(spanCtx, tenantId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to aggregate rent records with %s", err.Error()))
+		return dto.TenantDashboardResponse{}, err
+	}
+
+	rents, err := d.rentRepo.GetAllRents(spanCtx, tenantId, models.Tenant)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get rents with %s", err.Error()))
+		return dto.TenantDashboardResponse{}, err
+	}
+
+	totalsByRent := make(map[bson.ObjectID]models.TenantRentTotals, len(stats.TotalsByRent))
+	for _, totals := range stats.TotalsByRent {
+		totalsByRent[totals.RentId] = totals
+	}
+
+	now := time.Now()
+
+	response := dto.TenantDashboardResponse{
+		Rents:            []dto.TenantRentEntry{},
+		TotalOverdue:     []models.Money{},
+		AwaitingApproval: []dto.TenantPaymentEntry{},
+		PaymentHistory:   make([]dto.TenantPaymentEntry, 0, len(stats.History)),
+	}
+
+	var converter *CurrencyConverter
+	if reportCurrency != "" {
+		converter, err = d.exchangeRateService.NewConverter(spanCtx, reportCurrency)
+		if err != nil {
+			return dto.TenantDashboardResponse{}, err
+		}
+		response.Report = &dto.TenantDashboardReport{
+			Currency:     reportCurrency,
+			TotalOverdue: converter.Zero(),
+			TotalPaid:    converter.Zero(),
+		}
+	}
+
+	rentTitles := make(map[bson.ObjectID]string, len(rents))
+	for _, rent := range rents {
+		rentTitles[rent.Id] = rent.Title
+
+		if !isRentOpen(rent) || !rent.EndDate.After(now) {
+			continue
+		}
+
+		tenant, ok := rent.FindTenant(tenantObjectId)
+		if !ok {
+			continue
+		}
+
+		totals := totalsByRent[rent.Id]
+		shareAmount := rent.ShareAmount(tenant)
+		currency := rent.Amount.Currency
+
+		entry := dto.TenantRentEntry{
+			RentId:          rent.Id.Hex(),
+			Title:           rent.Title,
+			LandLordName:    rent.LandLord.Name,
+			Schedule:        string(rent.Schedule),
+			ShareAmount:     shareAmount,
+			NextDueAmount:   models.NewMoney(0, currency),
+			OverdueAmount:   models.NewMoney(0, currency),
+			PendingApproval: models.NewMoney(totals.Pending, currency),
+			EndDate:         rent.EndDate.Format("2006-01-02"),
+		}
+
+		// the next one due is the earliest installment without a pending or approved record
+		if dueDate, ok := nextUncoveredDueDate(rent, totals.CoveredDueDates); ok {
+			entry.NextDueDate = dueDate.Format("2006-01-02")
+			entry.NextDueAmount = shareAmount
+		}
+
+		// records waiting for approval are not overdue, the tenant already paid them
+		due := shareAmount.Times(installmentsDueBy(rent, now))
+		paid := models.NewMoney(totals.Approved+totals.Pending, currency)
+		if overdue := due.Sub(paid); overdue.IsPositive() {
+			entry.OverdueAmount = overdue
+			response.TotalOverdue = models.AddByCurrency(response.TotalOverdue, overdue)
+			if converter != nil {
+				converted, err := converter.Convert(overdue, now)
+				if err != nil {
+					log.Error(spanCtx, fmt.Sprintf("Failed to convert overdue amount with %s", err.Error()))
+					return dto.TenantDashboardResponse{}, err
+				}
+				response.Report.TotalOverdue = addConverted(response.Report.TotalOverdue, converted)
+			}
+		}
+
+		response.Rents = append(response.Rents, entry)
+	}
+
+	for _, rentRecord := range stats.History {
+		payment := dto.TenantPaymentEntry{
+			RecordId:      rentRecord.Id.Hex(),
+			RentId:        rentRecord.RentId.Hex(),
+			RentTitle:     rentTitles[rentRecord.RentId],
+			Amount:        rentRecord.Amount,
+			PaymentMethod: string(rentRecord.PaymentMethod),
+			PaidAt:        rentRecord.PaymentDate().Format("2006-01-02"),
+			Status:        string(rentRecord.Status),
+			SubmittedAt:   rentRecord.SubmittedAt.Format(time.RFC3339),
+		}
+		if !rentRecord.DueDate.IsZero() {
+			payment.DueDate = rentRecord.DueDate.Format("2006-01-02")
+		}
+		if !rentRecord.ApprovedAt.IsZero() {
+			payment.ApprovedAt = rentRecord.ApprovedAt.Format(time.RFC3339)
+		}
+		if converter != nil {
+			converted, err := converter.Convert(rentRecord.Amount, rentRecord.PaymentDate())
+			if err != nil {
+				log.Error(spanCtx, fmt.Sprintf("Failed to convert payment with %s", err.Error()))
+				return dto.TenantDashboardResponse{}, err
+			}
+			payment.Converted = &converted
+			if rentRecord.Status == models.RentRecordStatusApproved {
+				settled := converted
+				if rentRecord.ApprovedAmount != nil {
+					settled, err = converter.Convert(*rentRecord.ApprovedAmount, rentRecord.PaymentDate())
+					if err != nil {
+						log.Error(spanCtx, fmt.Sprintf("Failed to convert approved amount with %s", err.Error()))
+						return dto.TenantDashboardResponse{}, err
+					}
+				}
+				response.Report.TotalPaid = addConverted(response.Report.TotalPaid, settled)
+			}
+		}
+
+		if rentRecord.Status == models.RentRecordStatusPending {
+			response.AwaitingApproval = append(response.AwaitingApproval, payment)
+		}
+		response.PaymentHistory = append(response.PaymentHistory, payment)
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Dashboard built for tenant %s with %d active rents", tenantId, len(response.Rents)))
+
+	return response, nil
+}
+
+// checkRole makes sure the user holds the role, users with both roles can see either dashboard
+// whichever role they are currently using.
+func (d *dashboardService) checkRole(ctx context.Context, userId string, role models.UserRole) error {
+
+	log := utils.GetLogger()
+
+	user, err := d.userRepo.FindUserById(ctx, userId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to find user with %s", err.Error()))
+		return errors.New("user not found")
+	}
+
+	if !user.HasRole(role) {
+		return customerr.RoleNotHeldError{Role: string(role)}
+	}
+
+	return nil
+}
+
+// landLordOccupancy counts the units of a landlord by occupancy, the rate is the share of units that
+// are let right now.
+func landLordOccupancy(units []models.Unit, rents []models.Rent, now time.Time) dto.Occupancy {
+
+	rentsByUnit := make(map[bson.ObjectID][]models.Rent)
+	for _, rent := range rents {
+		if rent.Unit != nil {
+			rentsByUnit[rent.Unit.Id] = append(rentsByUnit[rent.Unit.Id], rent)
+		}
+	}
+
+	occupancy := dto.Occupancy{Units: len(units)}
+	for _, unit := range units {
+		status, _ := unitOccupancy(rentsByUnit[unit.Id], now)
+		switch status {
+		case models.UnitOccupancyLet:
+			occupancy.Let++
+		case models.UnitOccupancyUpcoming:
+			occupancy.Upcoming++
+		default:
+			occupancy.Vacant++
+		}
+	}
+
+	if occupancy.Units > 0 {
+		occupancy.Rate = float64(occupancy.Let) / float64(occupancy.Units)
+	}
+
+	return occupancy
+}
+
+// addMonthlyIncome adds the income of a day to the entry of its month and currency, days come in
+// order so the entry is one of the last ones.
+func addMonthlyIncome(entries []dto.MonthlyIncomeEntry, month string, income models.DailyIncome) []dto.MonthlyIncomeEntry {
+	for i := len(entries) - 1; i >= 0 && entries[i].Month == month; i-- {
+		if entries[i].Amount.Currency == income.Amount.Currency {
+			entries[i].Amount = entries[i].Amount.Add(income.Amount)
+			entries[i].Payments += income.Payments
+			return entries
+		}
+	}
+	return append(entries, dto.MonthlyIncomeEntry{
+		Month:    month,
+		Amount:   income.Amount,
+		Payments: income.Payments,
+	})
+}
@@ -0,0 +1,436 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/models"
+	"sample-web/repositories"
+	"sample-web/utils"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type DisputeService interface {
+	OpenDispute(ctx context.Context, tenantId string, rentId string, rentRecordId string, disputeRequest dto.DisputeRequest) (models.Dispute, error)
+	GetRecordDisputes(ctx context.Context, userId string, rentId string, rentRecordId string) (dto.DisputeResponse, error)
+	GetDisputes(ctx context.Context, userId string, userRole string, status string) (dto.DisputeResponse, error)
+	GetDisputeById(ctx context.Context, userId string, disputeId string) (models.Dispute, error)
+	AddMessage(ctx context.Context, userId string, disputeId string, messageRequest dto.DisputeMessageRequest) (models.Dispute, error)
+	ReviewDispute(ctx context.Context, landLordId string, disputeId string) (models.Dispute, error)
+	ResolveDispute(ctx context.Context, landLordId string, disputeId string, resolveRequest dto.ResolveDisputeRequest) (models.Dispute, error)
+}
+
+type disputeService struct {
+	disputeRepo         repositories.DisputeRepository
+	rentRecordRepo      repositories.RentRecordRepository
+	rentRepo            repositories.RentRepository
+	userRepo            repositories.UserRepository
+	notificationService NotificationService
+}
+
+func NewDisputeService(disputeRepo repositories.DisputeRepository, rentRecordRepo repositories.RentRecordRepository, rentRepo repositories.RentRepository, userRepo repositories.UserRepository, notificationService NotificationService) DisputeService {
+	return &disputeService{
+		disputeRepo:         disputeRepo,
+		rentRecordRepo:      rentRecordRepo,
+		rentRepo:            rentRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+	}
+}
+
+// OpenDispute lets the tenant who paid a record contest its approval or rejection, a record has at
+// most one dispute that is not resolved.
+func (d *disputeService) OpenDispute(ctx context.Context, tenantId string, rentId string, rentRecordId string, disputeRequest dto.DisputeRequest) (models.Dispute, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DisputeService.OpenDispute")
+	defer span.End()
+
+	rent, rentRecord, err := d.findRentRecord(spanCtx, tenantId, rentId, rentRecordId)
+	if err != nil {
+		return models.Dispute{}, err
+	}
+
+	if rentRecord.Tenant.Id.Hex() != tenantId {
+		log.Error(spanCtx, fmt.Sprintf("Rent record %s is not a payment of %s", rentRecordId, tenantId))
+		return models.Dispute{}, errors.New("only the tenant who paid the record can dispute it")
+	}
+
+	if rentRecord.Status != models.RentRecordStatusApproved && rentRecord.Status != models.RentRecordStatusRejected {
+		log.Error(spanCtx, fmt.Sprintf("Rent record %s is %s", rentRecordId, rentRecord.Status))
+		return models.Dispute{}, errors.New("only approved or rejected rent records can be disputed")
+	}
+
+	// approving a resubmitted record would count the payment twice, the resubmission is disputed instead
+	if rentRecord.ResubmittedBy != nil {
+		log.Error(spanCtx, fmt.Sprintf("Rent record %s was resubmitted as %s", rentRecordId, rentRecord.ResubmittedBy.Hex()))
+		return models.Dispute{}, errors.New("rent record was resubmitted, dispute the resubmitted record instead")
+	}
+
+	if rentRecord.IsDisputed() {
+		log.Error(spanCtx, fmt.Sprintf("Rent record %s already has dispute %s", rentRecordId, rentRecord.Dispute.Id.Hex()))
+		return models.Dispute{}, errors.New("rent record already has an open dispute")
+	}
+
+	now := time.Now()
+
+	dispute := models.Dispute{
+		Id:           bson.NewObjectID(),
+		RentId:       rent.Id,
+		RentRecordId: rentRecord.Id,
+		LandLord:     rent.LandLord,
+		Tenant:       rentRecord.Tenant,
+		RecordStatus: rentRecord.Status,
+		Reason:       strings.TrimSpace(disputeRequest.Reason),
+		Status:       models.DisputeStatusOpen,
+		Messages:     []models.DisputeMessage{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	// flagging the record first makes a concurrent second dispute of the same record fail
+	if err := d.rentRecordRepo.OpenDispute(spanCtx, rentRecord.Id, models.RecordDispute{Id: dispute.Id, Status: dispute.Status}, now); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to flag rent record %s as disputed with %s", rentRecordId, err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Dispute{}, errors.New("rent record already has an open dispute")
+		}
+		return models.Dispute{}, err
+	}
+
+	dispute, err = d.disputeRepo.CreateDispute(spanCtx, dispute)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to create dispute with %s", err.Error()))
+		return models.Dispute{}, err
+	}
+
+	message := fmt.Sprintf("%s disputed the %s payment of %s %s for %q: %s",
+		rentRecord.Tenant.Name, rentRecord.Status, rentRecord.Amount.String(), rentRecord.Amount.Currency, rent.Title, dispute.Reason)
+	notifyUser(spanCtx, d.userRepo, d.notificationService, rent.LandLord.Id, message)
+
+	return dispute, nil
+}
+
+// GetRecordDisputes returns the disputes of a rent record, newest first.
+func (d *disputeService) GetRecordDisputes(ctx context.Context, userId string, rentId string, rentRecordId string) (dto.DisputeResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DisputeService.GetRecordDisputes")
+	defer span.End()
+
+	_, rentRecord, err := d.findRentRecord(spanCtx, userId, rentId, rentRecordId)
+	if err != nil {
+		return dto.DisputeResponse{}, err
+	}
+
+	disputes, err := d.disputeRepo.GetDisputesByRecord(spanCtx, rentRecord.Id)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get disputes of rent record %s with %s", rentRecordId, err.Error()))
+		return dto.DisputeResponse{}, err
+	}
+
+	return dto.DisputeResponse{Disputes: disputes}, nil
+}
+
+// GetDisputes returns the disputes of the user in their current role.
+func (d *disputeService) GetDisputes(ctx context.Context, userId string, userRole string, status string) (dto.DisputeResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DisputeService.GetDisputes")
+	defer span.End()
+
+	disputes, err := d.disputeRepo.GetDisputes(spanCtx, userId, userRole, status)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get disputes with %s", err.Error()))
+		return dto.DisputeResponse{}, err
+	}
+
+	return dto.DisputeResponse{Disputes: disputes}, nil
+}
+
+func (d *disputeService) GetDisputeById(ctx context.Context, userId string, disputeId string) (models.Dispute, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DisputeService.GetDisputeById")
+	defer span.End()
+
+	dispute, err := d.disputeRepo.FindDisputeById(spanCtx, userId, disputeId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find dispute %s with %s", disputeId, err.Error()))
+		return models.Dispute{}, err
+	}
+
+	return dispute, nil
+}
+
+// AddMessage adds a message of the landlord or the tenant to the thread of the dispute.
+func (d *disputeService) AddMessage(ctx context.Context, userId string, disputeId string, messageRequest dto.DisputeMessageRequest) (models.Dispute, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DisputeService.AddMessage")
+	defer span.End()
+
+	dispute, err := d.disputeRepo.FindDisputeById(spanCtx, userId, disputeId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find dispute %s with %s", disputeId, err.Error()))
+		return models.Dispute{}, err
+	}
+
+	if dispute.Status == models.DisputeStatusResolved {
+		return models.Dispute{}, errors.New("dispute is resolved")
+	}
+
+	author, role := dispute.Tenant, models.Tenant
+	if dispute.LandLord.Id.Hex() == userId {
+		author, role = dispute.LandLord, models.LandLord
+	}
+
+	dispute, err = d.disputeRepo.AddMessage(spanCtx, dispute.Id, models.DisputeMessage{
+		Id:        bson.NewObjectID(),
+		Author:    author,
+		Role:      role,
+		Body:      strings.TrimSpace(messageRequest.Body),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to add message to dispute %s with %s", disputeId, err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Dispute{}, errors.New("dispute is resolved")
+		}
+		return models.Dispute{}, err
+	}
+
+	return dispute, nil
+}
+
+// ReviewDispute lets the tenant know the landlord is looking into an open dispute.
+func (d *disputeService) ReviewDispute(ctx context.Context, landLordId string, disputeId string) (models.Dispute, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DisputeService.ReviewDispute")
+	defer span.End()
+
+	dispute, err := d.findLandLordDispute(spanCtx, landLordId, disputeId)
+	if err != nil {
+		return models.Dispute{}, err
+	}
+
+	if dispute.Status != models.DisputeStatusOpen {
+		return models.Dispute{}, errors.New("dispute is not open")
+	}
+
+	now := time.Now()
+
+	dispute, err = d.disputeRepo.MarkUnderReview(spanCtx, dispute.Id, now)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to review dispute %s with %s", disputeId, err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Dispute{}, errors.New("dispute is not open")
+		}
+		return models.Dispute{}, err
+	}
+
+	if err := d.rentRecordRepo.SetDisputeStatus(spanCtx, dispute.RentRecordId, models.RecordDispute{Id: dispute.Id, Status: dispute.Status}, now); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to update dispute status of rent record %s with %s", dispute.RentRecordId.Hex(), err.Error()))
+	}
+
+	return dispute, nil
+}
+
+// ResolveDispute closes the dispute with the decision of the landlord, reversing it approves a
+// rejected record or rejects an approved one.
+func (d *disputeService) ResolveDispute(ctx context.Context, landLordId string, disputeId string, resolveRequest dto.ResolveDisputeRequest) (models.Dispute, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "DisputeService.ResolveDispute")
+	defer span.End()
+
+	dispute, err := d.findLandLordDispute(spanCtx, landLordId, disputeId)
+	if err != nil {
+		return models.Dispute{}, err
+	}
+
+	if dispute.Status == models.DisputeStatusResolved {
+		// a record can still be flagged with a dispute whose resolution did not reach it
+		if err := d.rentRecordRepo.SetDisputeStatus(spanCtx, dispute.RentRecordId, models.RecordDispute{Id: dispute.Id, Status: dispute.Status}, time.Now()); err == nil {
+			log.Info(spanCtx, fmt.Sprintf("Synced resolved dispute %s to rent record %s", disputeId, dispute.RentRecordId.Hex()))
+		}
+		return models.Dispute{}, errors.New("dispute is already resolved")
+	}
+
+	rentRecord, err := d.rentRecordRepo.GetRentRecordById(spanCtx, dispute.RentRecordId.Hex())
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent record %s with %s", dispute.RentRecordId.Hex(), err.Error()))
+		return models.Dispute{}, err
+	}
+
+	now := time.Now()
+	note := strings.TrimSpace(resolveRequest.Note)
+	decision := models.DisputeDecision(resolveRequest.Decision)
+
+	reversed := rentRecord
+	if decision == models.DisputeDecisionReverse {
+		reversed, err = reverseReview(rentRecord, note, now)
+		if err != nil {
+			return models.Dispute{}, err
+		}
+		// the resubmission of a rejected record pays the installment already
+		if reversed.Status == models.RentRecordStatusApproved && rentRecord.ResubmittedBy != nil {
+			log.Error(spanCtx, fmt.Sprintf("Rent record %s was resubmitted as %s", rentRecord.Id.Hex(), rentRecord.ResubmittedBy.Hex()))
+			return models.Dispute{}, errors.New("rent record was resubmitted and cannot be approved, keep its review instead")
+		}
+		// the reference of a rejected record may have been used by another record since
+		if reversed.Status == models.RentRecordStatusApproved && rentRecord.Reference != "" {
+			duplicate, err := d.rentRecordRepo.FindRecordByReference(spanCtx, rentRecord.RentId.Hex(), rentRecord.Reference)
+			if err == nil {
+				return models.Dispute{}, customerr.DuplicatePaymentReferenceError{Reference: rentRecord.Reference, RecordId: duplicate.Id.Hex()}
+			}
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				log.Error(spanCtx, fmt.Sprintf("Failed to check reference %s with %s", rentRecord.Reference, err.Error()))
+				return models.Dispute{}, err
+			}
+		}
+	}
+
+	// the record is updated first, its flag only lets one resolution through and a failed reversal
+	// leaves the dispute open
+	resolved := models.RecordDispute{Id: dispute.Id, Status: models.DisputeStatusResolved}
+	if decision == models.DisputeDecisionReverse {
+		_, err = d.rentRecordRepo.ReverseReview(spanCtx, rentRecord.Id, reversed, rentRecord.Status, models.RecordStatusChange{
+			Status:    reversed.Status,
+			Actor:     dispute.LandLord,
+			Role:      models.LandLord,
+			Reason:    note,
+			ChangedAt: now,
+		}, resolved)
+	} else {
+		err = d.rentRecordRepo.SetDisputeStatus(spanCtx, rentRecord.Id, resolved, now)
+	}
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to resolve dispute %s on rent record %s with %s", disputeId, rentRecord.Id.Hex(), err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Dispute{}, errors.New("dispute is already resolved or the rent record changed")
+		}
+		return models.Dispute{}, err
+	}
+
+	resolvedDispute, err := d.disputeRepo.Resolve(spanCtx, dispute.Id, models.DisputeOutcome{
+		Decision:     decision,
+		Note:         note,
+		RecordStatus: reversed.Status,
+		ResolvedAt:   now,
+	})
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to resolve dispute %s with %s", disputeId, err.Error()))
+		d.restoreRecord(spanCtx, dispute, rentRecord, reversed, time.Now())
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// the dispute was resolved without reaching the record, which is flagged as resolved now
+			if err := d.rentRecordRepo.SetDisputeStatus(spanCtx, rentRecord.Id, resolved, time.Now()); err != nil {
+				log.Error(spanCtx, fmt.Sprintf("Failed to update dispute status of rent record %s with %s", rentRecord.Id.Hex(), err.Error()))
+			}
+			return models.Dispute{}, errors.New("dispute is already resolved")
+		}
+		return models.Dispute{}, err
+	}
+	dispute = resolvedDispute
+
+	message := fmt.Sprintf("%s resolved your dispute of the payment of %s %s, the payment is %s: %s",
+		dispute.LandLord.Name, rentRecord.Amount.String(), rentRecord.Amount.Currency, reversed.Status, note)
+	notifyUser(spanCtx, d.userRepo, d.notificationService, dispute.Tenant.Id, message)
+
+	return dispute, nil
+}
+
+// restoreRecord undoes the update of a record whose dispute could not be resolved, the record gets back
+// its review and the dispute its status. A failure is only logged as the resolution already failed.
+func (d *disputeService) restoreRecord(ctx context.Context, dispute models.Dispute, rentRecord models.RentRecord, reversed models.RentRecord, now time.Time) {
+
+	log := utils.GetLogger()
+
+	flag := models.RecordDispute{Id: dispute.Id, Status: dispute.Status}
+
+	var err error
+	if reversed.Status != rentRecord.Status {
+		rentRecord.UpdatedAt = now
+		_, err = d.rentRecordRepo.ReverseReview(ctx, rentRecord.Id, rentRecord, reversed.Status, models.RecordStatusChange{
+			Status:    rentRecord.Status,
+			Actor:     dispute.LandLord,
+			Role:      models.LandLord,
+			Reason:    "dispute resolution failed",
+			ChangedAt: now,
+		}, flag)
+	} else {
+		err = d.rentRecordRepo.SetDisputeStatus(ctx, rentRecord.Id, flag, now)
+	}
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to restore rent record %s of dispute %s with %s", rentRecord.Id.Hex(), dispute.Id.Hex(), err.Error()))
+	}
+}
+
+// reverseReview returns the record with the opposite review, an approved record is rejected with the
+// note as the reason.
+func reverseReview(rentRecord models.RentRecord, note string, now time.Time) (models.RentRecord, error) {
+	switch rentRecord.Status {
+	case models.RentRecordStatusApproved:
+		rentRecord.Status = models.RentRecordStatusRejected
+		rentRecord.ApprovedAt = time.Time{}
+		rentRecord.Rejection = &models.RecordRejection{
+			Code:       models.RejectionReasonOther,
+			Message:    note,
+			RejectedAt: now,
+		}
+	case models.RentRecordStatusRejected:
+		rentRecord.Status = models.RentRecordStatusApproved
+		rentRecord.ApprovedAt = now
+		rentRecord.Rejection = nil
+	default:
+		return models.RentRecord{}, fmt.Errorf("a %s rent record cannot be reversed", rentRecord.Status)
+	}
+	rentRecord.UpdatedAt = now
+	return rentRecord, nil
+}
+
+func (d *disputeService) findLandLordDispute(ctx context.Context, landLordId string, disputeId string) (models.Dispute, error) {
+
+	log := utils.GetLogger()
+
+	dispute, err := d.disputeRepo.FindDisputeById(ctx, landLordId, disputeId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to find dispute %s with %s", disputeId, err.Error()))
+		return models.Dispute{}, err
+	}
+
+	if dispute.LandLord.Id.Hex() != landLordId {
+		return models.Dispute{}, errors.New("only the landlord can review or resolve a dispute")
+	}
+
+	return dispute, nil
+}
+
+func (d *disputeService) findRentRecord(ctx context.Context, userId string, rentId string, rentRecordId string) (models.Rent, models.RentRecord, error) {
+
+	log := utils.GetLogger()
+
+	rent, err := d.rentRepo.FindRentById(ctx, userId, rentId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to find rent %s with %s", rentId, err.Error()))
+		return models.Rent{}, models.RentRecord{}, err
+	}
+
+	rentRecord, err := d.rentRecordRepo.GetRentRecordById(ctx, rentRecordId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to find rent record %s with %s", rentRecordId, err.Error()))
+		return models.Rent{}, models.RentRecord{}, err
+	}
+
+	if rentRecord.RentId != rent.Id {
+		return models.Rent{}, models.RentRecord{}, mongo.ErrNoDocuments
+	}
+
+	return rent, rentRecord, nil
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sample-web/utils"
+)
+
+type dummyNotificationService struct {
+}
+
+func NewDummyNotificationService() NotificationService {
+	return &dummyNotificationService{}
+}
+
+// SendSMS only logs the message, it is meant for local development.
+func (s *dummyNotificationService) SendSMS(ctx context.Context, phoneNumber string, message string) error {
+	log := utils.GetLogger()
+	log.Info(ctx, fmt.Sprintf("SMS to %s: %s", phoneNumber, message))
+	return nil
+}
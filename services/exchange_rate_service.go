@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/models"
+	"sample-web/repositories"
+	"sample-web/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type ExchangeRateService interface {
+	CreateRate(ctx context.Context, adminId string, rateRequest dto.ExchangeRateRequest) (models.ExchangeRate, error)
+	GetRates(ctx context.Context, currency string) (dto.ExchangeRateResponse, error)
+	NewConverter(ctx context.Context, reportCurrency string) (*CurrencyConverter, error)
+}
+
+type exchangeRateService struct {
+	exchangeRateRepo repositories.ExchangeRateRepository
+	userRepo         repositories.UserRepository
+}
+
+func NewExchangeRateService(exchangeRateRepo repositories.ExchangeRateRepository, userRepo repositories.UserRepository) ExchangeRateService {
+	return &exchangeRateService{
+		exchangeRateRepo: exchangeRateRepo,
+		userRepo:         userRepo,
+	}
+}
+
+func (e *exchangeRateService) CreateRate(ctx context.Context, adminId string, rateRequest dto.ExchangeRateRequest) (models.ExchangeRate, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "ExchangeRateService.CreateRate")
+	defer span.End()
+
+	admin, err := e.userRepo.FindUserById(spanCtx, adminId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find admin with %s", err.Error()))
+		return models.ExchangeRate{}, err
+	}
+
+	// the role middleware only checks the role in the token, the stored roles are the source of truth
+	if !admin.HasRole(models.Admin) {
+		return models.ExchangeRate{}, customerr.RoleNotHeldError{Role: string(models.Admin)}
+	}
+
+	value, ok := new(big.Rat).SetString(rateRequest.Rate.String())
+	if !ok || value.Sign() <= 0 {
+		log.Error(spanCtx, fmt.Sprintf("Invalid exchange rate %s", rateRequest.Rate))
+		return models.ExchangeRate{}, errors.New("exchange rate must be a positive number")
+	}
+
+	rate, err := bson.ParseDecimal128(rateRequest.Rate.String())
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to parse exchange rate with %s", err.Error()))
+		return models.ExchangeRate{}, errors.New("exchange rate must be a positive number")
+	}
+
+	effectiveFrom, err := time.Parse("2006-01-02", rateRequest.EffectiveFrom)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to parse effective date with %s", err.Error()))
+		return models.ExchangeRate{}, errors.New("failed to parse effective date")
+	}
+
+	exchangeRate, err := e.exchangeRateRepo.CreateRate(spanCtx, models.ExchangeRate{
+		BaseCurrency:  rateRequest.BaseCurrency,
+		QuoteCurrency: rateRequest.QuoteCurrency,
+		Rate:          rate,
+		EffectiveFrom: effectiveFrom,
+		CreatedBy: models.PersonRef{
+			Id:   admin.Id,
+			Name: admin.Name,
+		},
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to create exchange rate with %s", err.Error()))
+		return models.ExchangeRate{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Exchange rate %s/%s effective from %s created", exchangeRate.BaseCurrency, exchangeRate.QuoteCurrency, rateRequest.EffectiveFrom))
+
+	return exchangeRate, nil
+}
+
+func (e *exchangeRateService) GetRates(ctx context.Context, currency string) (dto.ExchangeRateResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "ExchangeRateService.GetRates")
+	defer span.End()
+
+	rates, err := e.exchangeRateRepo.GetRates(spanCtx, currency)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get exchange rates with %s", err.Error()))
+		return dto.ExchangeRateResponse{}, err
+	}
+
+	if rates == nil {
+		rates = []models.ExchangeRate{}
+	}
+
+	return dto.ExchangeRateResponse{
+		Rates: rates,
+	}, nil
+}
+
+// NewConverter loads the rates from and to the report currency into a converter.
+func (e *exchangeRateService) NewConverter(ctx context.Context, reportCurrency string) (*CurrencyConverter, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "ExchangeRateService.NewConverter")
+	defer span.End()
+
+	rates, err := e.exchangeRateRepo.GetRates(spanCtx, reportCurrency)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get exchange rates with %s", err.Error()))
+		return nil, err
+	}
+
+	return newCurrencyConverter(reportCurrency, rates), nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sample-web/clients"
+	"sample-web/utils"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseLockScript deletes the lock only while it still holds the token of the caller, so a lock
+// that expired and was taken by another replica is left alone.
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+type LockService interface {
+	// TryLock takes the lock if it is free, acquired is false when another holder has it. The lock
+	// expires after ttl if release is never called.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (release func(context.Context), acquired bool, err error)
+}
+
+type redisLockService struct {
+	redisClient *clients.RedisClient
+}
+
+func NewRedisLockService(redisClient *clients.RedisClient) LockService {
+	return &redisLockService{
+		redisClient: redisClient,
+	}
+}
+
+func (s *redisLockService) TryLock(ctx context.Context, key string, ttl time.Duration) (func(context.Context), bool, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "LockService.TryLock")
+	defer span.End()
+
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		span.RecordError(err)
+		return nil, false, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	acquired, err := s.redisClient.Client.SetNX(spanCtx, s.buildLockKey(key), token, ttl).Result()
+	if err != nil {
+		span.RecordError(err)
+		return nil, false, err
+	}
+
+	if !acquired {
+		return nil, false, nil
+	}
+
+	release := func(ctx context.Context) {
+		if err := releaseLockScript.Run(ctx, s.redisClient.Client, []string{s.buildLockKey(key)}, token).Err(); err != nil {
+			log.Error(ctx, fmt.Sprintf("Failed to release lock %s with %s", key, err.Error()))
+		}
+	}
+
+	return release, true, nil
+}
+
+func (s *redisLockService) buildLockKey(key string) string {
+	return fmt.Sprintf("lock:%s", key)
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sample-web/configs"
+	"sample-web/dto"
+	"sample-web/models"
+	"sample-web/repositories"
+	"sample-web/storage"
+	"sample-web/utils"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type MaintenanceService interface {
+	CreateMaintenanceRequest(ctx context.Context, tenantId string, rentId string, maintenanceRequest dto.MaintenanceRequestRequest) (models.MaintenanceRequest, error)
+	GetMaintenanceRequests(ctx context.Context, userId string, userRole string, status string) (dto.MaintenanceResponse, error)
+	GetRentMaintenanceRequests(ctx context.Context, userId string, rentId string, status string) (dto.MaintenanceResponse, error)
+	GetMaintenanceRequestById(ctx context.Context, userId string, rentId string, requestId string) (models.MaintenanceRequest, error)
+	UpdateStatus(ctx context.Context, landLordId string, rentId string, requestId string, statusRequest dto.MaintenanceStatusRequest) (models.MaintenanceRequest, error)
+	AddComment(ctx context.Context, userId string, rentId string, requestId string, commentRequest dto.MaintenanceCommentRequest) (models.MaintenanceRequest, error)
+	AddPhoto(ctx context.Context, tenantId string, rentId string, requestId string, fileName string, body io.Reader) (dto.AttachmentResponse, error)
+	GetPhoto(ctx context.Context, userId string, rentId string, requestId string, photoId string) (dto.AttachmentResponse, error)
+}
+
+type maintenanceService struct {
+	maintenanceRepo     repositories.MaintenanceRepository
+	rentRepo            repositories.RentRepository
+	userRepo            repositories.UserRepository
+	attachments         attachmentUploader
+	notificationService NotificationService
+}
+
+func NewMaintenanceService(maintenanceRepo repositories.MaintenanceRepository, rentRepo repositories.RentRepository, userRepo repositories.UserRepository, blobStorage storage.BlobStorage, storageConfig configs.StorageConfig, notificationService NotificationService) MaintenanceService {
+	return &maintenanceService{
+		maintenanceRepo:     maintenanceRepo,
+		rentRepo:            rentRepo,
+		userRepo:            userRepo,
+		attachments:         newAttachmentUploader(blobStorage, storageConfig),
+		notificationService: notificationService,
+	}
+}
+
+// maxPhotosPerRequest caps the photos a single maintenance request can carry.
+const maxPhotosPerRequest = 5
+
+// photoExtensions lists the content types accepted as photos with the extension their files are
+// stored with.
+var photoExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// CreateMaintenanceRequest lets a co-tenant raise a repair on a rent that is not closed, the
+// landlord is told about it.
+func (m *maintenanceService) CreateMaintenanceRequest(ctx context.Context, tenantId string, rentId string, maintenanceRequest dto.MaintenanceRequestRequest) (models.MaintenanceRequest, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "MaintenanceService.CreateMaintenanceRequest")
+	defer span.End()
+
+	rent, err := m.rentRepo.FindRentById(spanCtx, tenantId, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent %s with %s", rentId, err.Error()))
+		return models.MaintenanceRequest{}, err
+	}
+
+	tenant, ok := m.findTenant(rent, tenantId)
+	if !ok {
+		log.Error(spanCtx, fmt.Sprintf("User %s is not a co-tenant of rent %s", tenantId, rentId))
+		return models.MaintenanceRequest{}, errors.New("only a co-tenant of the rent can raise a maintenance request")
+	}
+
+	if rent.Status == models.RentStatusClosed {
+		return models.MaintenanceRequest{}, errors.New("rent is closed")
+	}
+
+	now := time.Now()
+	tenantRef := models.PersonRef{Id: tenant.Id, Name: tenant.Name}
+
+	request := models.MaintenanceRequest{
+		RentId:      rent.Id,
+		RentTitle:   rent.Title,
+		LandLord:    rent.LandLord,
+		Tenant:      tenantRef,
+		Category:    models.MaintenanceCategory(maintenanceRequest.Category),
+		Priority:    models.MaintenancePriority(maintenanceRequest.Priority),
+		Description: strings.TrimSpace(maintenanceRequest.Description),
+		Photos:      []models.Attachment{},
+		Status:      models.MaintenanceStatusOpen,
+		StatusHistory: []models.MaintenanceStatusChange{{
+			Status:    models.MaintenanceStatusOpen,
+			ChangedBy: tenantRef,
+			ChangedAt: now,
+		}},
+		Comments:  []models.MaintenanceComment{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	request, err = m.maintenanceRepo.CreateMaintenanceRequest(spanCtx, request)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to create maintenance request with %s", err.Error()))
+		return models.MaintenanceRequest{}, err
+	}
+
+	message := fmt.Sprintf("%s raised a %s priority %s request for %q: %s",
+		tenant.Name, request.Priority, strings.ReplaceAll(string(request.Category), "_", " "), rent.Title, request.Description)
+	notifyUser(spanCtx, m.userRepo, m.notificationService, rent.LandLord.Id, message)
+
+	return request, nil
+}
+
+// GetMaintenanceRequests returns the requests of the user in their current role across all rents.
+func (m *maintenanceService) GetMaintenanceRequests(ctx context.Context, userId string, userRole string, status string) (dto.MaintenanceResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "MaintenanceService.GetMaintenanceRequests")
+	defer span.End()
+
+	requests, err := m.maintenanceRepo.GetMaintenanceRequests(spanCtx, userId, userRole, status)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get maintenance requests with %s", err.Error()))
+		return dto.MaintenanceResponse{}, err
+	}
+
+	return dto.MaintenanceResponse{Requests: requests}, nil
+}
+
+// GetRentMaintenanceRequests returns the requests of a rent, co-tenants see each other's requests.
+func (m *maintenanceService) GetRentMaintenanceRequests(ctx context.Context, userId string, rentId string, status string) (dto.MaintenanceResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "MaintenanceService.GetRentMaintenanceRequests")
+	defer span.End()
+
+	rent, err := m.rentRepo.FindRentById(spanCtx, userId, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent %s with %s", rentId, err.Error()))
+		return dto.MaintenanceResponse{}, err
+	}
+
+	requests, err := m.maintenanceRepo.GetRentMaintenanceRequests(spanCtx, rent.Id, status)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get maintenance requests of rent %s with %s", rentId, err.Error()))
+		return dto.MaintenanceResponse{}, err
+	}
+
+	return dto.MaintenanceResponse{Requests: requests}, nil
+}
+
+func (m *maintenanceService) GetMaintenanceRequestById(ctx context.Context, userId string, rentId string, requestId string) (models.MaintenanceRequest, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "MaintenanceService.GetMaintenanceRequestById")
+	defer span.End()
+
+	_, request, err := m.findMaintenanceRequest(spanCtx, userId, rentId, requestId)
+	return request, err
+}
+
+// UpdateStatus lets the landlord move a request forward, the tenant who raised it is told about it.
+func (m *maintenanceService) UpdateStatus(ctx context.Context, landLordId string, rentId string, requestId string, statusRequest dto.MaintenanceStatusRequest) (models.MaintenanceRequest, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "MaintenanceService.UpdateStatus")
+	defer span.End()
+
+	rent, request, err := m.findMaintenanceRequest(spanCtx, landLordId, rentId, requestId)
+	if err != nil {
+		return models.MaintenanceRequest{}, err
+	}
+
+	if rent.LandLord.Id.Hex() != landLordId {
+		log.Error(spanCtx, fmt.Sprintf("User %s is not the landlord of rent %s", landLordId, rentId))
+		return models.MaintenanceRequest{}, errors.New("only the landlord can change the status of a maintenance request")
+	}
+
+	next := models.MaintenanceStatus(statusRequest.Status)
+	if !request.Status.CanMoveTo(next) {
+		log.Error(spanCtx, fmt.Sprintf("Maintenance request %s cannot move from %s to %s", requestId, request.Status, next))
+		return models.MaintenanceRequest{}, fmt.Errorf("a %s maintenance request cannot be moved to %s", request.Status, next)
+	}
+
+	note := strings.TrimSpace(statusRequest.Note)
+
+	request, err = m.maintenanceRepo.UpdateStatus(spanCtx, request.Id, request.Status, models.MaintenanceStatusChange{
+		Status:    next,
+		ChangedBy: rent.LandLord,
+		Note:      note,
+		ChangedAt: time.Now(),
+	})
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to update maintenance request %s with %s", requestId, err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.MaintenanceRequest{}, errors.New("maintenance request was updated in the meantime, try again")
+		}
+		return models.MaintenanceRequest{}, err
+	}
+
+	message := fmt.Sprintf("%s moved your maintenance request for %q to %s",
+		rent.LandLord.Name, rent.Title, strings.ReplaceAll(string(next), "_", " "))
+	if note != "" {
+		message += ": " + note
+	}
+	notifyUser(spanCtx, m.userRepo, m.notificationService, request.Tenant.Id, message)
+
+	return request, nil
+}
+
+// AddComment adds a comment of the landlord or a co-tenant to a request that is not resolved.
+func (m *maintenanceService) AddComment(ctx context.Context, userId string, rentId string, requestId string, commentRequest dto.MaintenanceCommentRequest) (models.MaintenanceRequest, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "MaintenanceService.AddComment")
+	defer span.End()
+
+	rent, request, err := m.findMaintenanceRequest(spanCtx, userId, rentId, requestId)
+	if err != nil {
+		return models.MaintenanceRequest{}, err
+	}
+
+	if request.Status == models.MaintenanceStatusResolved {
+		return models.MaintenanceRequest{}, errors.New("maintenance request is resolved")
+	}
+
+	author, role := rent.LandLord, models.LandLord
+	if rent.LandLord.Id.Hex() != userId {
+		tenant, ok := m.findTenant(rent, userId)
+		if !ok {
+			log.Error(spanCtx, fmt.Sprintf("User %s is not a co-tenant of rent %s", userId, rentId))
+			return models.MaintenanceRequest{}, errors.New("only the landlord or a co-tenant can comment on a maintenance request")
+		}
+		author, role = models.PersonRef{Id: tenant.Id, Name: tenant.Name}, models.Tenant
+	}
+
+	request, err = m.maintenanceRepo.AddComment(spanCtx, request.Id, models.MaintenanceComment{
+		Id:        bson.NewObjectID(),
+		Author:    author,
+		Role:      role,
+		Body:      strings.TrimSpace(commentRequest.Body),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to comment on maintenance request %s with %s", requestId, err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.MaintenanceRequest{}, errors.New("maintenance request is resolved")
+		}
+		return models.MaintenanceRequest{}, err
+	}
+
+	return request, nil
+}
+
+// AddPhoto attaches a photo of the repair to a request that is not resolved, only the tenant who
+// raised the request adds photos.
+func (m *maintenanceService) AddPhoto(ctx context.Context, tenantId string, rentId string, requestId string, fileName string, body io.Reader) (dto.AttachmentResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "MaintenanceService.AddPhoto")
+	defer span.End()
+
+	_, request, err := m.findMaintenanceRequest(spanCtx, tenantId, rentId, requestId)
+	if err != nil {
+		return dto.AttachmentResponse{}, err
+	}
+
+	if request.Tenant.Id.Hex() != tenantId {
+		log.Error(spanCtx, fmt.Sprintf("User %s did not raise maintenance request %s", tenantId, requestId))
+		return dto.AttachmentResponse{}, errors.New("only the tenant who raised the request can add photos")
+	}
+
+	if request.Status == models.MaintenanceStatusResolved {
+		return dto.AttachmentResponse{}, errors.New("maintenance request is resolved")
+	}
+
+	if len(request.Photos) >= maxPhotosPerRequest {
+		return dto.AttachmentResponse{}, fmt.Errorf("a maintenance request can have at most %d photos", maxPhotosPerRequest)
+	}
+
+	keyPrefix := "maintenance-requests/" + request.Id.Hex()
+	photo, err := m.attachments.upload(spanCtx, keyPrefix, fileName, body, photoExtensions, request.Tenant, func(photo models.Attachment) error {
+		_, err := m.maintenanceRepo.AddPhoto(spanCtx, request.Id, photo, maxPhotosPerRequest)
+		return err
+	})
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error adding photo to maintenance request %s: %v", requestId, err))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dto.AttachmentResponse{}, fmt.Errorf("maintenance request is resolved or has %d photos", maxPhotosPerRequest)
+		}
+		return dto.AttachmentResponse{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Photo %s added to maintenance request %s", photo.Id.Hex(), requestId))
+
+	return m.attachments.toAttachmentResponse(spanCtx, photo)
+}
+
+func (m *maintenanceService) GetPhoto(ctx context.Context, userId string, rentId string, requestId string, photoId string) (dto.AttachmentResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "MaintenanceService.GetPhoto")
+	defer span.End()
+
+	_, request, err := m.findMaintenanceRequest(spanCtx, userId, rentId, requestId)
+	if err != nil {
+		return dto.AttachmentResponse{}, err
+	}
+
+	photoObjectId, err := bson.ObjectIDFromHex(photoId)
+	if err != nil {
+		return dto.AttachmentResponse{}, mongo.ErrNoDocuments
+	}
+
+	photo, ok := request.FindPhoto(photoObjectId)
+	if !ok {
+		log.Error(spanCtx, fmt.Sprintf("Photo %s not found on maintenance request %s", photoId, requestId))
+		return dto.AttachmentResponse{}, mongo.ErrNoDocuments
+	}
+
+	return m.attachments.toAttachmentResponse(spanCtx, photo)
+}
+
+// findMaintenanceRequest finds a request of the rent, the rent lookup makes sure the user is the
+// landlord or a co-tenant of the rent.
+func (m *maintenanceService) findMaintenanceRequest(ctx context.Context, userId string, rentId string, requestId string) (models.Rent, models.MaintenanceRequest, error) {
+
+	log := utils.GetLogger()
+
+	rent, err := m.rentRepo.FindRentById(ctx, userId, rentId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to find rent %s with %s", rentId, err.Error()))
+		return models.Rent{}, models.MaintenanceRequest{}, err
+	}
+
+	request, err := m.maintenanceRepo.FindMaintenanceRequestById(ctx, rent.Id, requestId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to find maintenance request %s with %s", requestId, err.Error()))
+		return models.Rent{}, models.MaintenanceRequest{}, err
+	}
+
+	return rent, request, nil
+}
+
+func (m *maintenanceService) findTenant(rent models.Rent, userId string) (models.RentTenant, bool) {
+	userObjectId, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return models.RentTenant{}, false
+	}
+	return rent.FindTenant(userObjectId)
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sample-web/configs"
+	"sample-web/repositories"
+	"sample-web/utils"
+
+	"github.com/twilio/twilio-go"
+	api "github.com/twilio/twilio-go/rest/api/v2010"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type NotificationService interface {
+	SendSMS(ctx context.Context, phoneNumber string, message string) error
+}
+
+type twilioNotificationService struct {
+	client *twilio.RestClient
+	config configs.TwilioConfig
+}
+
+func NewTwilioNotificationService(cfg configs.TwilioConfig) NotificationService {
+	twilioClient := twilio.NewRestClientWithParams(twilio.ClientParams{
+		Username: cfg.AccountSID,
+		Password: cfg.AuthToken,
+	})
+	return &twilioNotificationService{
+		client: twilioClient,
+		config: cfg,
+	}
+}
+
+func (s *twilioNotificationService) SendSMS(ctx context.Context, phoneNumber string, message string) error {
+
+	log := utils.GetLogger()
+
+	spanCtx, span := log.Tracer().Start(ctx, "NotificationService.SendSMS")
+	defer span.End()
+
+	params := &api.CreateMessageParams{}
+	params.SetTo(phoneNumber)
+	params.SetMessagingServiceSid(s.config.MessagingServiceSID)
+	params.SetBody(message)
+
+	resp, err := s.client.Api.CreateMessage(params)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to send SMS to %s with %s", phoneNumber, err.Error()))
+		return err
+	}
+
+	if resp.Sid != nil {
+		log.Info(spanCtx, fmt.Sprintf("SMS sent to %s with sid %s", phoneNumber, *resp.Sid))
+	}
+	return nil
+}
+
+// notifyUser texts the user, a failed SMS is only logged so it never fails the change it is about.
+func notifyUser(ctx context.Context, userRepo repositories.UserRepository, notificationService NotificationService, userId bson.ObjectID, message string) {
+
+	log := utils.GetLogger()
+
+	user, err := userRepo.FindUserById(ctx, userId.Hex())
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to find user %s with %s", userId.Hex(), err.Error()))
+		return
+	}
+
+	if err := notificationService.SendSMS(ctx, user.PhoneNumber, message); err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to send notice to %s with %s", userId.Hex(), err.Error()))
+	}
+}
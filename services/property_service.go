@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sample-web/dto"
+	"sample-web/mappers"
+	"sample-web/models"
+	"sample-web/repositories"
+	"sample-web/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type PropertyService interface {
+	CreateProperty(ctx context.Context, landLordId string, propertyRequest dto.PropertyRequest) (dto.PropertyResponse, error)
+	GetAllProperties(ctx context.Context, landLordId string) (dto.PropertyResponse, error)
+	GetPropertyById(ctx context.Context, landLordId string, propertyId string) (dto.PropertyResponse, error)
+	UpdateProperty(ctx context.Context, landLordId string, propertyId string, propertyRequest dto.PropertyRequest) (dto.PropertyResponse, error)
+	DeleteProperty(ctx context.Context, landLordId string, propertyId string) error
+	GetPropertyOccupancy(ctx context.Context, landLordId string, propertyId string) (dto.PropertyOccupancyResponse, error)
+}
+
+type propertyService struct {
+	propertyRepo repositories.PropertyRepository
+	unitRepo     repositories.UnitRepository
+	rentRepo     repositories.RentRepository
+	userRepo     repositories.UserRepository
+}
+
+func NewPropertyService(propertyRepo repositories.PropertyRepository, unitRepo repositories.UnitRepository, rentRepo repositories.RentRepository, userRepo repositories.UserRepository) PropertyService {
+	return &propertyService{
+		propertyRepo: propertyRepo,
+		unitRepo:     unitRepo,
+		rentRepo:     rentRepo,
+		userRepo:     userRepo,
+	}
+}
+
+func (p *propertyService) CreateProperty(ctx context.Context, landLordId string, propertyRequest dto.PropertyRequest) (dto.PropertyResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "PropertyService.CreateProperty")
+	defer span.End()
+
+	landLord, err := p.userRepo.FindUserById(spanCtx, landLordId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find landlord with %s", err.Error()))
+		return dto.PropertyResponse{}, errors.New("landlord not found")
+	}
+
+	now := time.Now()
+
+	property := models.Property{
+		LandLord: models.PersonRef{
+			Id:   landLord.Id,
+			Name: landLord.Name,
+		},
+		Name:      propertyRequest.Name,
+		Type:      models.PropertyType(propertyRequest.Type),
+		Address:   mappers.ToAddressModel(propertyRequest.Address),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Creating property with name: %s", property.Name))
+
+	createdProperty, err := p.propertyRepo.CreateProperty(spanCtx, property)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to create property with %s", err.Error()))
+		return dto.PropertyResponse{}, errors.New("failed to create property")
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Property created successfully with ID: %s", createdProperty.Id.Hex()))
+
+	return dto.PropertyResponse{
+		Properties: []models.Property{createdProperty},
+	}, nil
+}
+
+// GetAllProperties implements PropertyService.
+func (p *propertyService) GetAllProperties(ctx context.Context, landLordId string) (dto.PropertyResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "PropertyService.GetAllProperties")
+	defer span.End()
+
+	properties, err := p.propertyRepo.GetAllProperties(spanCtx, landLordId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get properties with %s", err.Error()))
+		return dto.PropertyResponse{}, err
+	}
+
+	return dto.PropertyResponse{
+		Properties: properties,
+	}, nil
+}
+
+// GetPropertyById implements PropertyService.
+func (p *propertyService) GetPropertyById(ctx context.Context, landLordId string, propertyId string) (dto.PropertyResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "PropertyService.GetPropertyById")
+	defer span.End()
+
+	property, err := p.propertyRepo.FindPropertyById(spanCtx, landLordId, propertyId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find property with %s", err.Error()))
+		return dto.PropertyResponse{}, err
+	}
+
+	return dto.PropertyResponse{
+		Properties: []models.Property{property},
+	}, nil
+}
+
+// UpdateProperty implements PropertyService.
+func (p *propertyService) UpdateProperty(ctx context.Context, landLordId string, propertyId string, propertyRequest dto.PropertyRequest) (dto.PropertyResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "PropertyService.UpdateProperty")
+	defer span.End()
+
+	property, err := p.propertyRepo.FindPropertyById(spanCtx, landLordId, propertyId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find property with %s", err.Error()))
+		return dto.PropertyResponse{}, err
+	}
+
+	property.Name = propertyRequest.Name
+	property.Type = models.PropertyType(propertyRequest.Type)
+	property.Address = mappers.ToAddressModel(propertyRequest.Address)
+	property.UpdatedAt = time.Now()
+
+	updatedProperty, err := p.propertyRepo.UpdateProperty(spanCtx, landLordId, property)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to update property with %s", err.Error()))
+		return dto.PropertyResponse{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Property updated successfully with ID: %s", propertyId))
+
+	return dto.PropertyResponse{
+		Properties: []models.Property{updatedProperty},
+	}, nil
+}
+
+// DeleteProperty implements PropertyService.
+func (p *propertyService) DeleteProperty(ctx context.Context, landLordId string, propertyId string) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "PropertyService.DeleteProperty")
+	defer span.End()
+
+	units, err := p.unitRepo.GetUnitsByProperty(spanCtx, landLordId, propertyId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get units with %s", err.Error()))
+		return err
+	}
+
+	if len(units) > 0 {
+		log.Error(spanCtx, fmt.Sprintf("Property %s still has %d units", propertyId, len(units)))
+		return errors.New("property still has units, delete them first")
+	}
+
+	if err := p.propertyRepo.DeleteProperty(spanCtx, landLordId, propertyId); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to delete property with %s", err.Error()))
+		return err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Property deleted successfully with ID: %s", propertyId))
+
+	return nil
+}
+
+// GetPropertyOccupancy implements PropertyService.
+func (p *propertyService) GetPropertyOccupancy(ctx context.Context, landLordId string, propertyId string) (dto.PropertyOccupancyResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "PropertyService.GetPropertyOccupancy")
+	defer span.End()
+
+	property, err := p.propertyRepo.FindPropertyById(spanCtx, landLordId, propertyId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find property with %s", err.Error()))
+		return dto.PropertyOccupancyResponse{}, err
+	}
+
+	units, err := p.unitRepo.GetUnitsByProperty(spanCtx, landLordId, propertyId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get units with %s", err.Error()))
+		return dto.PropertyOccupancyResponse{}, err
+	}
+
+	unitIds := make([]bson.ObjectID, 0, len(units))
+	for _, unit := range units {
+		unitIds = append(unitIds, unit.Id)
+	}
+
+	rents, err := p.rentRepo.GetRentsByUnitIds(spanCtx, landLordId, unitIds)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get rents with %s", err.Error()))
+		return dto.PropertyOccupancyResponse{}, err
+	}
+
+	rentsByUnit := make(map[bson.ObjectID][]models.Rent)
+	for _, rent := range rents {
+		rentsByUnit[rent.Unit.Id] = append(rentsByUnit[rent.Unit.Id], rent)
+	}
+
+	occupancy := dto.PropertyOccupancyResponse{
+		PropertyId: property.Id.Hex(),
+		Name:       property.Name,
+		Units:      make([]dto.UnitOccupancyEntry, 0, len(units)),
+	}
+
+	now := time.Now()
+
+	for _, unit := range units {
+		status, rent := unitOccupancy(rentsByUnit[unit.Id], now)
+
+		entry := dto.UnitOccupancyEntry{
+			UnitId:     unit.Id.Hex(),
+			UnitNumber: unit.UnitNumber,
+			Type:       string(unit.Type),
+			Occupancy:  string(status),
+		}
+		if rent != nil {
+			entry.RentId = rent.Id.Hex()
+			entry.RentTitle = rent.Title
+			entry.StartDate = rent.StartDate.Format("2006-01-02")
+			entry.EndDate = rent.EndDate.Format("2006-01-02")
+		}
+
+		switch status {
+		case models.UnitOccupancyLet:
+			occupancy.Let++
+		case models.UnitOccupancyUpcoming:
+			occupancy.Upcoming++
+		default:
+			occupancy.Vacant++
+		}
+
+		occupancy.Units = append(occupancy.Units, entry)
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Property %s has %d let, %d upcoming and %d vacant units", propertyId, occupancy.Let, occupancy.Upcoming, occupancy.Vacant))
+
+	return occupancy, nil
+}
+
+// unitOccupancy works out whether a unit is let, vacant or has an upcoming rent from the
+// rents referencing it, together with the rent that decided it.
+func unitOccupancy(rents []models.Rent, now time.Time) (models.UnitOccupancy, *models.Rent) {
+
+	var upcoming *models.Rent
+
+	for i := range rents {
+		rent := &rents[i]
+		if !isRentOpen(*rent) || rent.EndDate.Before(now) {
+			continue
+		}
+		if !rent.StartDate.After(now) {
+			return models.UnitOccupancyLet, rent
+		}
+		if upcoming == nil || rent.StartDate.Before(upcoming.StartDate) {
+			upcoming = rent
+		}
+	}
+
+	if upcoming != nil {
+		return models.UnitOccupancyUpcoming, upcoming
+	}
+	return models.UnitOccupancyVacant, nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+	"sample-web/models"
+	"sample-web/pdf"
+	"strings"
+)
+
+// receiptNumber formats the nth receipt of a landlord.
+func receiptNumber(n int64) string {
+	return fmt.Sprintf("RCT-%06d", n)
+}
+
+// renderReceipt writes the receipt of an approved rent record as a PDF.
+func renderReceipt(rent models.Rent, rentRecord models.RentRecord, number string) ([]byte, error) {
+	document := pdf.NewDocument("Rent receipt " + number)
+
+	document.Heading("Rent Receipt")
+	document.Field("Receipt number", number)
+	document.Field("Receipt date", rentRecord.ApprovedAt.Format("02 Jan 2006"))
+	document.Rule()
+
+	period := "-"
+	if start, end, ok := installmentPeriod(rent, rentRecord.DueDate); ok {
+		period = fmt.Sprintf("%s to %s", start.Format("02 Jan 2006"), end.Format("02 Jan 2006"))
+	}
+
+	// names and titles are entered by users in any script, the receipt is issued even when the fonts
+	// cannot show them
+	title := printable(rent.Title, "Rent "+rent.Id.Hex())
+	landLordName := printable(rentRecord.LandLord.Name, "Landlord "+rentRecord.LandLord.Id.Hex())
+	tenantFallback := "Tenant " + rentRecord.Tenant.Id.Hex()
+	if tenant, ok := rent.FindTenant(rentRecord.Tenant.Id); ok && tenant.PhoneNumber != "" {
+		tenantFallback = tenant.PhoneNumber
+	}
+	tenantName := printable(rentRecord.Tenant.Name, tenantFallback)
+
+	document.Field("Rent", title)
+	if rent.Unit != nil {
+		document.Field("Unit", printable(rent.Unit.UnitNumber, "-"))
+	}
+	document.Field("Landlord", landLordName)
+	document.Field("Tenant", tenantName)
+	document.Field("Period", period)
+	document.Rule()
+
+	amount := rentRecord.SettledAmount()
+	document.Field("Amount", amount.String()+" "+amount.Currency)
+	document.Field("Payment method", paymentMethodLabel(rentRecord.PaymentMethod))
+	if rentRecord.Reference != "" {
+		document.Field("Reference", printable(rentRecord.Reference, "-"))
+	}
+	document.Field("Paid on", rentRecord.PaymentDate().Format("02 Jan 2006"))
+	document.Rule()
+
+	document.Paragraph(fmt.Sprintf("Received with thanks from %s the sum of %s %s towards the rent of %s for the period %s.",
+		tenantName, amount.String(), amount.Currency, title, period))
+	document.Space(24)
+	document.Paragraph(landLordName)
+	document.Paragraph("This receipt was generated electronically when the payment was approved and does not need a signature.")
+
+	return document.Bytes()
+}
+
+// printable returns the text when a PDF can show it and fallback otherwise.
+func printable(text string, fallback string) string {
+	if pdf.Writable(text) {
+		return text
+	}
+	return fallback
+}
+
+func paymentMethodLabel(method models.PaymentMethod) string {
+	switch method {
+	case models.PaymentMethodBankTransfer:
+		return "Bank transfer"
+	case models.PaymentMethodUPI:
+		return "UPI"
+	case "":
+		return "-"
+	default:
+		label := string(method)
+		return strings.ToUpper(label[:1]) + label[1:]
+	}
+}
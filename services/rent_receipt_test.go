@@ -0,0 +1,59 @@
+package services
+
+import (
+	"sample-web/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestRenderReceipt(t *testing.T) {
+	tests := []struct {
+		name         string
+		title        string
+		landLordName string
+		tenantName   string
+	}{
+		{name: "latin", title: "Flat 3B", landLordName: "Anita Rao", tenantName: "Ravi Kumar"},
+		{name: "latin with accents", title: "Café Résidence", landLordName: "José Müller", tenantName: "Zoë"},
+		{name: "devanagari", title: "फ्लैट 3B", landLordName: "अनीता राव", tenantName: "रवि कुमार"},
+		{name: "tamil", title: "வீடு", landLordName: "அனிதா", tenantName: "ரவி"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			landLord := models.PersonRef{Id: bson.NewObjectID(), Name: test.landLordName}
+			tenant := models.RentTenant{Id: bson.NewObjectID(), Name: test.tenantName, PhoneNumber: "+919000000000"}
+			rent := models.Rent{
+				Id:        bson.NewObjectID(),
+				Title:     test.title,
+				LandLord:  landLord,
+				Tenants:   []models.RentTenant{tenant},
+				Amount:    models.NewMoney(1500000, "INR"),
+				Schedule:  models.RentScheduleMonthly,
+				StartDate: date(2025, time.January, 1),
+				EndDate:   date(2026, time.January, 1),
+			}
+			rentRecord := models.RentRecord{
+				Id:         bson.NewObjectID(),
+				RentId:     rent.Id,
+				Amount:     rent.Amount,
+				DueDate:    date(2025, time.February, 1),
+				PaidAt:     date(2025, time.February, 1),
+				ApprovedAt: date(2025, time.February, 2),
+				Status:     models.RentRecordStatusApproved,
+				LandLord:   landLord,
+				Tenant:     models.PersonRef{Id: tenant.Id, Name: tenant.Name},
+			}
+
+			content, err := renderReceipt(rent, rentRecord, receiptNumber(1))
+			if err != nil {
+				t.Fatalf("renderReceipt failed with %v", err)
+			}
+			if len(content) == 0 {
+				t.Error("renderReceipt returned an empty document")
+			}
+		})
+	}
+}
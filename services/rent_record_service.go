@@ -1,35 +1,67 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"sample-web/configs"
 	"sample-web/dto"
+	customerr "sample-web/errors"
+	"sample-web/mappers"
 	"sample-web/models"
 	"sample-web/repositories"
+	"sample-web/storage"
 	"sample-web/utils"
+	"strings"
 	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type RentRecordService interface {
 	CreateRentRecord(ctx context.Context, tenantId string, rentId string, rentRecordRequest dto.RentRecordRequest) (dto.RentRecordResponse, error)
-	GetAllRentRecords(ctx context.Context, userId string,userRole string, rentId string) ([]dto.RentRecordResponse, error)
+	GetAllRentRecords(ctx context.Context, userId string,userRole string, rentId string, paymentMethod string) ([]dto.RentRecordResponse, error)
 	GetRentRecordById(ctx context.Context, userId string, rentId string, rentRecordId string) (dto.RentRecordResponse, error)
-	ApproveRentRecord(ctx context.Context, landLordId string,rentId string, rentRecordId string) (dto.RentRecordResponse, error)
-	RejectRentRecord(ctx context.Context, landLordId string, rentId string,rentRecordId string) (dto.RentRecordResponse, error)
+	ApproveRentRecord(ctx context.Context, landLordId string,rentId string, rentRecordId string, approveRequest dto.ApproveRentRecordRequest) (dto.RentRecordResponse, error)
+	RejectRentRecord(ctx context.Context, landLordId string, rentId string,rentRecordId string, rejectRequest dto.RejectRentRecordRequest) (dto.RentRecordResponse, error)
+	ResubmitRentRecord(ctx context.Context, tenantId string, rentId string, rentRecordId string, rentRecordRequest dto.RentRecordRequest) (dto.RentRecordResponse, error)
+	EditRentRecord(ctx context.Context, tenantId string, rentId string, rentRecordId string, rentRecordRequest dto.RentRecordRequest) (dto.RentRecordResponse, error)
+	WithdrawRentRecord(ctx context.Context, tenantId string, rentId string, rentRecordId string) (dto.RentRecordResponse, error)
+	CreateLandLordRentRecord(ctx context.Context, landLordId string, rentId string, rentRecordRequest dto.LandLordRentRecordRequest) (dto.RentRecordResponse, error)
+	ConfirmRentRecord(ctx context.Context, tenantId string, rentId string, rentRecordId string) (dto.RentRecordResponse, error)
+	DisputeRentRecord(ctx context.Context, tenantId string, rentId string, rentRecordId string, disputeRequest dto.DisputeRentRecordRequest) (dto.RentRecordResponse, error)
+	BatchReviewRentRecords(ctx context.Context, landLordId string, batchRequest dto.BatchRentRecordRequest) (dto.BatchRentRecordResponse, error)
+	ReverseRentRecord(ctx context.Context, landLordId string, rentId string, rentRecordId string, reverseRequest dto.ReverseRentRecordRequest) (dto.RentRecordReversalResponse, error)
+	GetRentLedger(ctx context.Context, userId string, rentId string) (dto.RentLedgerResponse, error)
+	AddAttachment(ctx context.Context, userId string, rentId string, rentRecordId string, fileName string, body io.Reader) (dto.AttachmentResponse, error)
+	GetAttachment(ctx context.Context, userId string, rentId string, rentRecordId string, attachmentId string) (dto.AttachmentResponse, error)
+	GetReceipt(ctx context.Context, userId string, rentId string, rentRecordId string) (models.Receipt, io.ReadCloser, error)
 }
 
 type rentRecordService struct {
 	rentRecordRepository repositories.RentRecordRepository
 	rentRepository       repositories.RentRepository
 	userRepository       repositories.UserRepository
+	counterRepository    repositories.CounterRepository
+	blobStorage          storage.BlobStorage
+	attachments          attachmentUploader
+	notificationService  NotificationService
+	rentConfig           configs.RentConfig
 }
 
-func NewRentRecordService(rentRecordRepository repositories.RentRecordRepository, rentRepository repositories.RentRepository, userRepository repositories.UserRepository) RentRecordService {
+func NewRentRecordService(rentRecordRepository repositories.RentRecordRepository, rentRepository repositories.RentRepository, userRepository repositories.UserRepository, counterRepository repositories.CounterRepository, blobStorage storage.BlobStorage, storageConfig configs.StorageConfig, notificationService NotificationService, rentConfig configs.RentConfig) RentRecordService {
 	return &rentRecordService{
 		rentRecordRepository: rentRecordRepository,
 		rentRepository:       rentRepository,
 		userRepository:       userRepository,
+		counterRepository:    counterRepository,
+		blobStorage:          blobStorage,
+		attachments:          newAttachmentUploader(blobStorage, storageConfig),
+		notificationService:  notificationService,
+		rentConfig:           rentConfig,
 	}
 }
 
@@ -45,7 +77,7 @@ func (r *rentRecordService) CreateRentRecord(ctx context.Context, tenantId strin
 		return dto.RentRecordResponse{}, errors.New("rent ID is empty")
 	}
 
-	_, err := r.userRepository.FindUserById(spanCtx, tenantId)
+	user, err := r.userRepository.FindUserById(spanCtx, tenantId)
 	if err != nil {
 		log.Error(spanCtx, fmt.Sprintf("Error fetching tenant with ID %s: %v", tenantId, err))
 		return dto.RentRecordResponse{}, err
@@ -62,20 +94,24 @@ func (r *rentRecordService) CreateRentRecord(ctx context.Context, tenantId strin
 
 	log.Info(spanCtx, fmt.Sprintf("Fetched rent with ID %s: %+v", rentId, rent))
 
+	tenant, ok := rent.FindTenant(user.Id)
+	if !ok {
+		log.Error(spanCtx, fmt.Sprintf("User %s is not a tenant of rent %s", tenantId, rentId))
+		return dto.RentRecordResponse{}, errors.New("user is not a tenant of this rent")
+	}
+
+	dueDate, err := r.nextDueDate(spanCtx, rent, tenant)
+	if err != nil {
+		return dto.RentRecordResponse{}, err
+	}
+
 	now := time.Now()
 
-	var newRentRecord models.RentRecord
-	newRentRecord.Amount = rentRecordRequest.Amount
-	newRentRecord.RentId = rent.Id
-	newRentRecord.SubmittedAt = now
-	newRentRecord.Status = models.RentRecordStatusPending
-	newRentRecord.Rent = models.RentInfo{
-		Amount:   rent.Amount,
-		Schedule: rent.Schedule,
+	newRentRecord, err := r.newRentRecord(spanCtx, rent, tenant, rentRecordRequest, now, bson.NilObjectID)
+	if err != nil {
+		return dto.RentRecordResponse{}, err
 	}
-	newRentRecord.LandLord = rent.LandLord
-	newRentRecord.Tenant = rent.Tenant
-	newRentRecord.CreatedAt = now
+	newRentRecord.DueDate = dueDate
 	log.Info(spanCtx, fmt.Sprintf("Creating rent record for tenant %s and rent %s", tenantId, rentId))
 
 	rentRecord, err := r.rentRecordRepository.CreateRentRecord(spanCtx, newRentRecord)
@@ -87,17 +123,102 @@ func (r *rentRecordService) CreateRentRecord(ctx context.Context, tenantId strin
 
 	log.Info(spanCtx, fmt.Sprintf("Created rent record: %+v", rentRecord))
 
-	return dto.RentRecordResponse{
-		Id:          rentRecord.Id.Hex(),
-		RentId:      rentRecord.RentId.Hex(),
-		Amount:      rentRecord.Amount,
-		SubmittedAt: rentRecord.SubmittedAt.Format(time.RFC3339),
-		Status:      string(rentRecord.Status),
-	}, nil
+	return mappers.ToRentRecordResponse(rentRecord), nil
+}
+
+// nextDueDate is the due date of the earliest installment without a pending or approved record of the
+// co-tenant, it is zero once every installment has one.
+func (r *rentRecordService) nextDueDate(ctx context.Context, rent models.Rent, tenant models.RentTenant) (time.Time, error) {
+
+	log := utils.GetLogger()
+
+	covered, err := r.rentRecordRepository.GetCoveredDueDates(ctx, rent.Id.Hex(), tenant.Id.Hex())
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Error finding the paid installments of tenant %s: %v", tenant.Id.Hex(), err))
+		return time.Time{}, err
+	}
+
+	dueDate, _ := nextUncoveredDueDate(rent, covered)
+	return dueDate, nil
+}
+
+// newRentRecord validates the payment details of the request and returns the pending record of the
+// co-tenant, the due date is left to the caller. editedRecordId is the record being edited, whose own
+// reference is not a duplicate.
+func (r *rentRecordService) newRentRecord(ctx context.Context, rent models.Rent, tenant models.RentTenant, rentRecordRequest dto.RentRecordRequest, now time.Time, editedRecordId bson.ObjectID) (models.RentRecord, error) {
+
+	log := utils.GetLogger()
+
+	if rentRecordRequest.Currency != "" && rentRecordRequest.Currency != rent.Amount.Currency {
+		log.Error(ctx, fmt.Sprintf("Rent record currency %s does not match rent currency %s", rentRecordRequest.Currency, rent.Amount.Currency))
+		return models.RentRecord{}, errors.New("currency does not match the rent currency")
+	}
+
+	amount, err := models.ParseMoney(rentRecordRequest.Amount.String(), rent.Amount.Currency)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Invalid rent record amount: %v", err))
+		return models.RentRecord{}, err
+	}
+
+	paidAt := now
+	if rentRecordRequest.PaidAt != "" {
+		paidAt, err = time.Parse("2006-01-02", rentRecordRequest.PaidAt)
+		if err != nil {
+			log.Error(ctx, fmt.Sprintf("Failed to parse payment date with %s", err.Error()))
+			return models.RentRecord{}, errors.New("failed to parse payment date")
+		}
+		if paidAt.After(now) {
+			log.Error(ctx, "Payment date is in the future")
+			return models.RentRecord{}, errors.New("payment date cannot be in the future")
+		}
+	}
+
+	reference := normaliseReference(rentRecordRequest.Reference)
+	if reference != "" {
+		// a reference used twice on the same rent is most likely the same payment submitted again
+		duplicate, err := r.rentRecordRepository.FindRecordByReference(ctx, rent.Id.Hex(), reference)
+		if err == nil && duplicate.Id != editedRecordId {
+			log.Error(ctx, fmt.Sprintf("Reference %s is already used by rent record %s", reference, duplicate.Id.Hex()))
+			return models.RentRecord{}, customerr.DuplicatePaymentReferenceError{Reference: reference, RecordId: duplicate.Id.Hex()}
+		}
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Error(ctx, fmt.Sprintf("Error checking reference %s: %v", reference, err))
+			return models.RentRecord{}, err
+		}
+	}
+
+	var newRentRecord models.RentRecord
+	newRentRecord.Amount = amount
+	newRentRecord.PaymentMethod = models.PaymentMethod(rentRecordRequest.PaymentMethod)
+	newRentRecord.Reference = reference
+	newRentRecord.PaidAt = paidAt
+	newRentRecord.RentId = rent.Id
+	newRentRecord.SubmittedAt = now
+	newRentRecord.Status = models.RentRecordStatusPending
+	newRentRecord.RecordedBy = models.Tenant
+	newRentRecord.Rent = models.RentInfo{
+		Amount:      rent.Amount,
+		ShareAmount: rent.ShareAmount(tenant),
+		Schedule:    rent.Schedule,
+	}
+	newRentRecord.LandLord = rent.LandLord
+	newRentRecord.Tenant = models.PersonRef{
+		Id:   tenant.Id,
+		Name: tenant.Name,
+	}
+	newRentRecord.CreatedAt = now
+	newRentRecord.StatusHistory = []models.RecordStatusChange{{
+		Status:    models.RentRecordStatusPending,
+		Actor:     newRentRecord.Tenant,
+		Role:      models.Tenant,
+		ChangedAt: now,
+	}}
+
+	return newRentRecord, nil
 }
 
 // GetAllRentRecords implements RentRecordService.
-func (r *rentRecordService) GetAllRentRecords(ctx context.Context, userId string,userRole string, rentId string) ([]dto.RentRecordResponse, error) {
+func (r *rentRecordService) GetAllRentRecords(ctx context.Context, userId string,userRole string, rentId string, paymentMethod string) ([]dto.RentRecordResponse, error) {
 	
 	log := utils.GetLogger()
 	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.GetAllRentRecords")
@@ -128,24 +249,13 @@ func (r *rentRecordService) GetAllRentRecords(ctx context.Context, userId string
 
 	log.Info(spanCtx, fmt.Sprintf("Fetched rent with ID %s: %+v", rentId, rent))
 
-	rentRecords, err := r.rentRecordRepository.GetAllRentRecords(spanCtx, userId, userRole,rentId)
+	rentRecords, err := r.rentRecordRepository.GetAllRentRecords(spanCtx, userId, userRole,rentId, paymentMethod)
 	if err != nil {
 		log.Error(spanCtx, fmt.Sprintf("Error fetching rent records: %v", err))
 		return nil, err
 	}
 	log.Info(spanCtx, fmt.Sprintf("Fetched rent records: %+v", rentRecords))
-	var rentRecordResponses []dto.RentRecordResponse
-	for _, rentRecord := range rentRecords {
-		rentRecordResponses = append(rentRecordResponses, dto.RentRecordResponse{
-			Id:          rentRecord.Id.Hex(),
-			RentId:      rentRecord.RentId.Hex(),
-			Amount:      rentRecord.Amount,
-			SubmittedAt: rentRecord.SubmittedAt.Format(time.RFC3339),
-			ApprovedAt:  rentRecord.ApprovedAt.Format(time.RFC3339),
-			Status:      string(rentRecord.Status),
-		})
-	}
-	return rentRecordResponses, nil
+	return toRentRecordChains(rentRecords), nil
 }
 
 // GetRentRecordById implements RentRecordService.
@@ -187,17 +297,12 @@ func (r *rentRecordService) GetRentRecordById(ctx context.Context, userId string
 
 	log.Info(spanCtx, fmt.Sprintf("Fetched rent record with ID %s: %+v", rentRecordId, rentRecord))
 
-	return dto.RentRecordResponse{
-		Id:          rentRecord.Id.Hex(),
-		RentId:      rentRecord.RentId.Hex(),
-		Amount:      rentRecord.Amount,
-		SubmittedAt: rentRecord.SubmittedAt.Format(time.RFC3339),
-		Status:      string(rentRecord.Status),
-	}, nil
+	return mappers.ToRentRecordResponse(rentRecord), nil
 }
 
-// ApproveRentRecord implements RentRecordService.
-func (r *rentRecordService) ApproveRentRecord(ctx context.Context, landLordId string, rentId string,rentRecordId string) (dto.RentRecordResponse, error) {
+// ApproveRentRecord implements RentRecordService. An approved amount that differs from the submitted
+// one is kept next to it and the tenant is told about the difference.
+func (r *rentRecordService) ApproveRentRecord(ctx context.Context, landLordId string, rentId string,rentRecordId string, approveRequest dto.ApproveRentRecordRequest) (dto.RentRecordResponse, error) {
 	
 	log := utils.GetLogger()
 	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.ApproveRentRecord")
@@ -219,17 +324,15 @@ func (r *rentRecordService) ApproveRentRecord(ctx context.Context, landLordId st
 		return dto.RentRecordResponse{}, err
 	}
 
-	rent, err := r.rentRepository.FindRentById(spanCtx, landLordId, rentId)
+	// the record has to belong to the rent of the path, its receipt is issued for that rent
+	rent, rentRecord, err := r.findRentRecord(spanCtx, landLordId, rentId, rentRecordId)
 	if err != nil {
-		log.Error(spanCtx, fmt.Sprintf("Error fetching rent with ID %s: %v", rentId, err))
 		return dto.RentRecordResponse{}, err
 	}
 
-	log.Info(spanCtx, fmt.Sprintf("Fetched rent with ID %s: %+v", rentId, rent))
-	rentRecord, err := r.rentRecordRepository.GetRentRecordById(spanCtx, rentRecordId)
-	if err != nil {
-		log.Error(spanCtx, fmt.Sprintf("Error fetching rent record with ID %s: %v", rentRecordId, err))
-		return dto.RentRecordResponse{}, err
+	if rent.LandLord.Id.Hex() != landLordId {
+		log.Error(spanCtx, fmt.Sprintf("User %s is not the landlord of rent %s", landLordId, rentId))
+		return dto.RentRecordResponse{}, errors.New("only the landlord can review a rent record")
 	}
 
 	log.Info(spanCtx, fmt.Sprintf("Fetched rent record with ID %s: %+v", rentRecordId, rentRecord))
@@ -237,10 +340,29 @@ func (r *rentRecordService) ApproveRentRecord(ctx context.Context, landLordId st
 		log.Error(spanCtx, fmt.Sprintf("Rent record with ID %s is not pending", rentRecordId))
 		return dto.RentRecordResponse{}, errors.New("rent record is not pending")
 	}
+	if approveRequest.ApprovedAmount != "" {
+		approvedAmount, err := models.ParseMoney(approveRequest.ApprovedAmount.String(), rentRecord.Amount.Currency)
+		if err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Invalid approved amount: %v", err))
+			return dto.RentRecordResponse{}, err
+		}
+		if approvedAmount != rentRecord.Amount {
+			rentRecord.ApprovedAmount = &approvedAmount
+			rentRecord.ApprovalNote = strings.TrimSpace(approveRequest.Note)
+		}
+	}
+
 	now := time.Now()
 	rentRecord.Status = models.RentRecordStatusApproved
 	rentRecord.UpdatedAt = now
 	rentRecord.ApprovedAt = now
+	rentRecord.StatusHistory = append(rentRecord.StatusHistory, models.RecordStatusChange{
+		Status:    models.RentRecordStatusApproved,
+		Actor:     rent.LandLord,
+		Role:      models.LandLord,
+		Reason:    rentRecord.ApprovalNote,
+		ChangedAt: now,
+	})
 
 	
 	updatedRentRecord, err := r.rentRecordRepository.UpdateRentRecord(spanCtx, landLordId, rentRecordId,rentRecord)
@@ -251,18 +373,23 @@ func (r *rentRecordService) ApproveRentRecord(ctx context.Context, landLordId st
 	}
 
 	log.Info(spanCtx, fmt.Sprintf("Updated rent record with ID %s: %+v", rentRecordId, updatedRentRecord))
-	return dto.RentRecordResponse{
-		Id:          updatedRentRecord.Id.Hex(),
-		RentId:      updatedRentRecord.RentId.Hex(),
-		Amount:      updatedRentRecord.Amount,
-		SubmittedAt: updatedRentRecord.SubmittedAt.Format(time.RFC3339),
-		ApprovedAt:  updatedRentRecord.ApprovedAt.Format(time.RFC3339),
-		Status:      string(updatedRentRecord.Status),
-	}, nil
+
+	if updatedRentRecord.ApprovedAmount != nil {
+		r.notifyAdjustedApproval(spanCtx, rent, updatedRentRecord)
+	}
+
+	// the approval stands without a receipt, a missing one is issued when it is first downloaded
+	if receiptRecord, err := r.issueReceipt(spanCtx, rent, updatedRentRecord); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error issuing receipt for rent record %s: %v", rentRecordId, err))
+	} else {
+		updatedRentRecord = receiptRecord
+	}
+
+	return mappers.ToRentRecordResponse(updatedRentRecord), nil
 }
 
 // RejectRentRecord implements RentRecordService.
-func (r *rentRecordService) RejectRentRecord(ctx context.Context, landLordId string, rentId string,rentRecordId string) (dto.RentRecordResponse, error) {
+func (r *rentRecordService) RejectRentRecord(ctx context.Context, landLordId string, rentId string,rentRecordId string, rejectRequest dto.RejectRentRecordRequest) (dto.RentRecordResponse, error) {
 	
 	log := utils.GetLogger()
 	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.RejectRentRecord")
@@ -284,17 +411,15 @@ func (r *rentRecordService) RejectRentRecord(ctx context.Context, landLordId str
 		return dto.RentRecordResponse{}, err
 	}
 
-	rent, err := r.rentRepository.FindRentById(spanCtx, landLordId, rentId)
+	// the record has to belong to the rent of the path, its receipt is issued for that rent
+	rent, rentRecord, err := r.findRentRecord(spanCtx, landLordId, rentId, rentRecordId)
 	if err != nil {
-		log.Error(spanCtx, fmt.Sprintf("Error fetching rent with ID %s: %v", rentId, err))
 		return dto.RentRecordResponse{}, err
 	}
 
-	log.Info(spanCtx, fmt.Sprintf("Fetched rent with ID %s: %+v", rentId, rent))
-	rentRecord, err := r.rentRecordRepository.GetRentRecordById(spanCtx, rentRecordId)
-	if err != nil {
-		log.Error(spanCtx, fmt.Sprintf("Error fetching rent record with ID %s: %v", rentRecordId, err))
-		return dto.RentRecordResponse{}, err
+	if rent.LandLord.Id.Hex() != landLordId {
+		log.Error(spanCtx, fmt.Sprintf("User %s is not the landlord of rent %s", landLordId, rentId))
+		return dto.RentRecordResponse{}, errors.New("only the landlord can review a rent record")
 	}
 
 	log.Info(spanCtx, fmt.Sprintf("Fetched rent record with ID %s: %+v", rentRecordId, rentRecord))
@@ -306,7 +431,18 @@ func (r *rentRecordService) RejectRentRecord(ctx context.Context, landLordId str
 	now := time.Now()
 	rentRecord.Status = models.RentRecordStatusRejected
 	rentRecord.UpdatedAt = now
-	// rentRecord.ApprovedAt = time.Now()
+	rentRecord.Rejection = &models.RecordRejection{
+		Code:       models.RejectionReasonCode(rejectRequest.Code),
+		Message:    strings.TrimSpace(rejectRequest.Message),
+		RejectedAt: now,
+	}
+	rentRecord.StatusHistory = append(rentRecord.StatusHistory, models.RecordStatusChange{
+		Status:    models.RentRecordStatusRejected,
+		Actor:     rent.LandLord,
+		Role:      models.LandLord,
+		Reason:    rentRecord.Rejection.Message,
+		ChangedAt: now,
+	})
 
 	updatedRentRecord, err := r.rentRecordRepository.UpdateRentRecord(spanCtx, landLordId,rentRecordId,rentRecord)
 
@@ -316,11 +452,914 @@ func (r *rentRecordService) RejectRentRecord(ctx context.Context, landLordId str
 	}
 	log.Info(spanCtx, fmt.Sprintf("Updated rent record with ID %s: %+v", rentRecordId, updatedRentRecord))
 
-	return dto.RentRecordResponse{
-		Id:          updatedRentRecord.Id.Hex(),
-		RentId:      updatedRentRecord.RentId.Hex(),
-		Amount:      updatedRentRecord.Amount,
-		SubmittedAt: updatedRentRecord.SubmittedAt.Format(time.RFC3339),
-		Status:      string(updatedRentRecord.Status),
+	return mappers.ToRentRecordResponse(updatedRentRecord), nil
+}
+
+// GetRentLedger implements RentRecordService.
+func (r *rentRecordService) GetRentLedger(ctx context.Context, userId string, rentId string) (dto.RentLedgerResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.GetRentLedger")
+	defer span.End()
+
+	if rentId == "" {
+		log.Error(spanCtx, "Rent ID is empty")
+		return dto.RentLedgerResponse{}, errors.New("rent ID is empty")
+	}
+
+	rent, err := r.rentRepository.FindRentById(spanCtx, userId, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error fetching rent with ID %s: %v", rentId, err))
+		return dto.RentLedgerResponse{}, err
+	}
+
+	totals, err := r.rentRecordRepository.GetTenantTotals(spanCtx, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error fetching rent record totals: %v", err))
+		return dto.RentLedgerResponse{}, err
+	}
+
+	totalsByTenant := make(map[bson.ObjectID]models.TenantRecordTotals)
+	for _, total := range totals {
+		totalsByTenant[total.TenantId] = total
+	}
+
+	currency := rent.Amount.Currency
+	ledger := dto.RentLedgerResponse{
+		RentId:   rent.Id.Hex(),
+		Amount:   rent.Amount,
+		Schedule: string(rent.Schedule),
+		Paid:     models.NewMoney(0, currency),
+		Pending:  models.NewMoney(0, currency),
+		Reversed: models.NewMoney(0, currency),
+		Tenants:  make([]dto.TenantLedgerEntry, 0, len(rent.Tenants)),
+	}
+
+	for _, tenant := range rent.Tenants {
+		entry := dto.TenantLedgerEntry{
+			Name:        tenant.Name,
+			PhoneNumber: tenant.PhoneNumber,
+			Invited:     tenant.Invited,
+			ShareType:   string(tenant.Share.Type),
+			ShareValue:  tenant.Share.String(),
+			ShareAmount: rent.ShareAmount(tenant),
+			Paid:        models.NewMoney(0, currency),
+			Pending:     models.NewMoney(0, currency),
+			Reversed:    models.NewMoney(0, currency),
+		}
+		if !tenant.Invited {
+			entry.TenantId = tenant.Id.Hex()
+			total := totalsByTenant[tenant.Id]
+			entry.Paid = models.NewMoney(total.Approved, currency)
+			entry.Pending = models.NewMoney(total.Pending, currency)
+			entry.Reversed = models.NewMoney(total.Reversed, currency)
+		}
+		ledger.Paid = ledger.Paid.Add(entry.Paid)
+		ledger.Pending = ledger.Pending.Add(entry.Pending)
+		ledger.Reversed = ledger.Reversed.Add(entry.Reversed)
+		ledger.Tenants = append(ledger.Tenants, entry)
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Built ledger for rent %s with %d tenants", rentId, len(ledger.Tenants)))
+
+	return ledger, nil
+}
+
+// normaliseReference makes references comparable, banks print the same UTR with different spacing
+// and case.
+func normaliseReference(reference string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(reference), ""))
+}
+
+// maxAttachmentsPerRecord caps the proofs of payment a single record can carry.
+const maxAttachmentsPerRecord = 5
+
+// attachmentExtensions lists the content types accepted as attachments with the extension their
+// files are stored with.
+var attachmentExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// AddAttachment implements RentRecordService.
+func (r *rentRecordService) AddAttachment(ctx context.Context, userId string, rentId string, rentRecordId string, fileName string, body io.Reader) (dto.AttachmentResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.AddAttachment")
+	defer span.End()
+
+	rent, rentRecord, err := r.findRentRecord(spanCtx, userId, rentId, rentRecordId)
+	if err != nil {
+		return dto.AttachmentResponse{}, err
+	}
+
+	// co-tenants see each other's records but only the payer and the landlord attach proofs
+	if rentRecord.Tenant.Id.Hex() != userId && rent.LandLord.Id.Hex() != userId {
+		log.Error(spanCtx, fmt.Sprintf("User %s cannot attach files to rent record %s", userId, rentRecordId))
+		return dto.AttachmentResponse{}, errors.New("only the tenant who paid or the landlord can attach files")
+	}
+
+	if len(rentRecord.Attachments) >= maxAttachmentsPerRecord {
+		return dto.AttachmentResponse{}, fmt.Errorf("a rent record can have at most %d attachments", maxAttachmentsPerRecord)
+	}
+
+	user, err := r.userRepository.FindUserById(spanCtx, userId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error fetching user with ID %s: %v", userId, err))
+		return dto.AttachmentResponse{}, err
+	}
+
+	uploadedBy := models.PersonRef{
+		Id:   user.Id,
+		Name: user.Name,
+	}
+	keyPrefix := "rent-records/" + rentRecord.Id.Hex()
+	attachment, err := r.attachments.upload(spanCtx, keyPrefix, fileName, body, attachmentExtensions, uploadedBy, func(attachment models.Attachment) error {
+		_, err := r.rentRecordRepository.AddAttachment(spanCtx, rentRecordId, attachment, maxAttachmentsPerRecord)
+		return err
+	})
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error adding attachment to rent record %s: %v", rentRecordId, err))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dto.AttachmentResponse{}, fmt.Errorf("a rent record can have at most %d attachments", maxAttachmentsPerRecord)
+		}
+		return dto.AttachmentResponse{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Attachment %s added to rent record %s", attachment.Id.Hex(), rentRecordId))
+
+	return r.attachments.toAttachmentResponse(spanCtx, attachment)
+}
+
+// GetAttachment implements RentRecordService.
+func (r *rentRecordService) GetAttachment(ctx context.Context, userId string, rentId string, rentRecordId string, attachmentId string) (dto.AttachmentResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.GetAttachment")
+	defer span.End()
+
+	_, rentRecord, err := r.findRentRecord(spanCtx, userId, rentId, rentRecordId)
+	if err != nil {
+		return dto.AttachmentResponse{}, err
+	}
+
+	attachmentObjectId, err := bson.ObjectIDFromHex(attachmentId)
+	if err != nil {
+		return dto.AttachmentResponse{}, mongo.ErrNoDocuments
+	}
+
+	attachment, ok := rentRecord.FindAttachment(attachmentObjectId)
+	if !ok {
+		log.Error(spanCtx, fmt.Sprintf("Attachment %s not found on rent record %s", attachmentId, rentRecordId))
+		return dto.AttachmentResponse{}, mongo.ErrNoDocuments
+	}
+
+	return r.attachments.toAttachmentResponse(spanCtx, attachment)
+}
+
+// findRentRecord finds a record of the rent, the rent lookup makes sure the user is the landlord
+// or a co-tenant of the rent.
+func (r *rentRecordService) findRentRecord(ctx context.Context, userId string, rentId string, rentRecordId string) (models.Rent, models.RentRecord, error) {
+
+	log := utils.GetLogger()
+
+	rent, err := r.rentRepository.FindRentById(ctx, userId, rentId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Error fetching rent with ID %s: %v", rentId, err))
+		return models.Rent{}, models.RentRecord{}, err
+	}
+
+	rentRecord, err := r.rentRecordRepository.GetRentRecordById(ctx, rentRecordId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Error fetching rent record with ID %s: %v", rentRecordId, err))
+		return models.Rent{}, models.RentRecord{}, err
+	}
+
+	if rentRecord.RentId != rent.Id {
+		log.Error(ctx, fmt.Sprintf("Rent record %s does not belong to rent %s", rentRecordId, rentId))
+		return models.Rent{}, models.RentRecord{}, mongo.ErrNoDocuments
+	}
+
+	return rent, rentRecord, nil
+}
+
+// GetReceipt implements RentRecordService.
+func (r *rentRecordService) GetReceipt(ctx context.Context, userId string, rentId string, rentRecordId string) (models.Receipt, io.ReadCloser, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.GetReceipt")
+	defer span.End()
+
+	rent, rentRecord, err := r.findRentRecord(spanCtx, userId, rentId, rentRecordId)
+	if err != nil {
+		return models.Receipt{}, nil, err
+	}
+
+	if rentRecord.Status != models.RentRecordStatusApproved {
+		log.Error(spanCtx, fmt.Sprintf("Rent record %s is not approved", rentRecordId))
+		return models.Receipt{}, nil, errors.New("receipts are only issued for approved rent records")
+	}
+
+	if rentRecord.Receipt == nil {
+		rentRecord, err = r.issueReceipt(spanCtx, rent, rentRecord)
+		if err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Error issuing receipt for rent record %s: %v", rentRecordId, err))
+			return models.Receipt{}, nil, err
+		}
+	}
+
+	body, err := r.blobStorage.Get(spanCtx, rentRecord.Receipt.StorageKey)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error fetching receipt %s: %v", rentRecord.Receipt.Number, err))
+		return models.Receipt{}, nil, err
+	}
+
+	return *rentRecord.Receipt, body, nil
+}
+
+// issueReceipt numbers, renders and stores the receipt of an approved record. When another request
+// issued the receipt first, the stored one wins and the record holding it is returned.
+func (r *rentRecordService) issueReceipt(ctx context.Context, rent models.Rent, rentRecord models.RentRecord) (models.RentRecord, error) {
+
+	log := utils.GetLogger()
+
+	sequence, err := r.counterRepository.NextValue(ctx, "receipts:"+rentRecord.LandLord.Id.Hex())
+	if err != nil {
+		return models.RentRecord{}, err
+	}
+
+	receipt := models.Receipt{
+		Number:      receiptNumber(sequence),
+		GeneratedAt: time.Now(),
+	}
+	receipt.StorageKey = fmt.Sprintf("receipts/%s/%s.pdf", rentRecord.LandLord.Id.Hex(), receipt.Number)
+
+	content, err := renderReceipt(rent, rentRecord, receipt.Number)
+	if err != nil {
+		return models.RentRecord{}, err
+	}
+
+	if err := r.blobStorage.Put(ctx, receipt.StorageKey, "application/pdf", bytes.NewReader(content)); err != nil {
+		return models.RentRecord{}, err
+	}
+
+	updatedRentRecord, err := r.rentRecordRepository.SetReceipt(ctx, rentRecord.Id.Hex(), receipt)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// the number is skipped, receipt numbers only have to be unique and increasing
+		log.Info(ctx, fmt.Sprintf("Receipt of rent record %s was issued concurrently", rentRecord.Id.Hex()))
+		if deleteErr := r.blobStorage.Delete(ctx, receipt.StorageKey); deleteErr != nil {
+			log.Error(ctx, fmt.Sprintf("Error deleting unused receipt %s: %v", receipt.StorageKey, deleteErr))
+		}
+		return r.rentRecordRepository.GetRentRecordById(ctx, rentRecord.Id.Hex())
+	}
+	if err != nil {
+		return models.RentRecord{}, err
+	}
+
+	log.Info(ctx, fmt.Sprintf("Receipt %s issued for rent record %s", receipt.Number, rentRecord.Id.Hex()))
+
+	return updatedRentRecord, nil
+}
+
+// discardReversalEntry deletes the compensating entry of a record that is no longer approved, unless
+// a concurrent reversal reversed the record with it. A failure is only logged as the reversal failed.
+func (r *rentRecordService) discardReversalEntry(ctx context.Context, rentRecordId string, entryId bson.ObjectID) {
+
+	log := utils.GetLogger()
+
+	rentRecord, err := r.rentRecordRepository.GetRentRecordById(ctx, rentRecordId)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Error fetching rent record with ID %s: %v", rentRecordId, err))
+		return
+	}
+	if rentRecord.Reversal != nil && rentRecord.Reversal.EntryId == entryId {
+		return
+	}
+
+	if err := r.rentRecordRepository.DeleteReversalEntry(ctx, entryId); err != nil {
+		log.Error(ctx, fmt.Sprintf("Error deleting compensating entry %s of rent record %s: %v", entryId.Hex(), rentRecordId, err))
+	}
+}
+
+// ResubmitRentRecord implements RentRecordService.
+func (r *rentRecordService) ResubmitRentRecord(ctx context.Context, tenantId string, rentId string, rentRecordId string, rentRecordRequest dto.RentRecordRequest) (dto.RentRecordResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.ResubmitRentRecord")
+	defer span.End()
+
+	rent, rejectedRecord, err := r.findRentRecord(spanCtx, tenantId, rentId, rentRecordId)
+	if err != nil {
+		return dto.RentRecordResponse{}, err
+	}
+
+	if rejectedRecord.Tenant.Id.Hex() != tenantId {
+		log.Error(spanCtx, fmt.Sprintf("Rent record %s was not submitted by %s", rentRecordId, tenantId))
+		return dto.RentRecordResponse{}, errors.New("only the tenant who submitted the record can resubmit it")
+	}
+
+	if rejectedRecord.Status != models.RentRecordStatusRejected {
+		log.Error(spanCtx, fmt.Sprintf("Rent record %s is not rejected", rentRecordId))
+		return dto.RentRecordResponse{}, errors.New("only rejected rent records can be resubmitted")
+	}
+
+	if rejectedRecord.ResubmittedBy != nil {
+		log.Error(spanCtx, fmt.Sprintf("Rent record %s was already resubmitted as %s", rentRecordId, rejectedRecord.ResubmittedBy.Hex()))
+		return dto.RentRecordResponse{}, errors.New("rent record was already resubmitted")
+	}
+
+	// resolving the dispute could approve the record as well as its resubmission
+	if rejectedRecord.IsDisputed() {
+		log.Error(spanCtx, fmt.Sprintf("Rent record %s has open dispute %s", rentRecordId, rejectedRecord.Dispute.Id.Hex()))
+		return dto.RentRecordResponse{}, errors.New("rent record has an open dispute, wait for its resolution")
+	}
+
+	tenant, ok := rent.FindTenant(rejectedRecord.Tenant.Id)
+	if !ok {
+		log.Error(spanCtx, fmt.Sprintf("User %s is not a tenant of rent %s", tenantId, rentId))
+		return dto.RentRecordResponse{}, errors.New("user is not a tenant of this rent")
+	}
+
+	now := time.Now()
+
+	newRentRecord, err := r.newRentRecord(spanCtx, rent, tenant, rentRecordRequest, now, bson.NilObjectID)
+	if err != nil {
+		return dto.RentRecordResponse{}, err
+	}
+
+	// the resubmission pays the installment the rejected record was meant for
+	newRentRecord.Id = bson.NewObjectID()
+	newRentRecord.DueDate = rejectedRecord.DueDate
+	newRentRecord.ResubmissionOf = &rejectedRecord.Id
+
+	// linking first makes a concurrent second resubmission of the same record fail
+	if err := r.rentRecordRepository.MarkResubmitted(spanCtx, rentRecordId, newRentRecord.Id); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error linking rent record %s to its resubmission: %v", rentRecordId, err))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dto.RentRecordResponse{}, errors.New("rent record was already resubmitted or is disputed")
+		}
+		return dto.RentRecordResponse{}, err
+	}
+
+	rentRecord, err := r.rentRecordRepository.CreateRentRecord(spanCtx, newRentRecord)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error creating resubmitted rent record: %v", err))
+		return dto.RentRecordResponse{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent record %s resubmitted as %s", rentRecordId, rentRecord.Id.Hex()))
+
+	return mappers.ToRentRecordResponse(rentRecord), nil
+}
+
+// toRentRecordChains returns a response for the latest record of every resubmission chain with the
+// records it replaced in its history. Records are kept in their original order.
+func toRentRecordChains(rentRecords []models.RentRecord) []dto.RentRecordResponse {
+	byId := make(map[bson.ObjectID]models.RentRecord, len(rentRecords))
+	for _, rentRecord := range rentRecords {
+		byId[rentRecord.Id] = rentRecord
+	}
+
+	var rentRecordResponses []dto.RentRecordResponse
+	for _, rentRecord := range rentRecords {
+		// records that were resubmitted show up in the history of the resubmission
+		if rentRecord.ResubmittedBy != nil {
+			if _, ok := byId[*rentRecord.ResubmittedBy]; ok {
+				continue
+			}
+		}
+
+		var history []dto.RentRecordResponse
+		previous := rentRecord.ResubmissionOf
+		for previous != nil {
+			previousRecord, ok := byId[*previous]
+			if !ok {
+				break
+			}
+			history = append([]dto.RentRecordResponse{mappers.ToRentRecordResponse(previousRecord)}, history...)
+			previous = previousRecord.ResubmissionOf
+		}
+
+		rentRecordResponse := mappers.ToRentRecordResponse(rentRecord)
+		rentRecordResponse.History = history
+		rentRecordResponses = append(rentRecordResponses, rentRecordResponse)
+	}
+	return rentRecordResponses
+}
+
+// EditRentRecord implements RentRecordService.
+func (r *rentRecordService) EditRentRecord(ctx context.Context, tenantId string, rentId string, rentRecordId string, rentRecordRequest dto.RentRecordRequest) (dto.RentRecordResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.EditRentRecord")
+	defer span.End()
+
+	rent, rentRecord, err := r.findPendingTenantRecord(spanCtx, tenantId, rentId, rentRecordId)
+	if err != nil {
+		return dto.RentRecordResponse{}, err
+	}
+
+	tenant, ok := rent.FindTenant(rentRecord.Tenant.Id)
+	if !ok {
+		log.Error(spanCtx, fmt.Sprintf("User %s is not a tenant of rent %s", tenantId, rentId))
+		return dto.RentRecordResponse{}, errors.New("user is not a tenant of this rent")
+	}
+
+	now := time.Now()
+
+	editedRecord, err := r.newRentRecord(spanCtx, rent, tenant, rentRecordRequest, now, rentRecord.Id)
+	if err != nil {
+		return dto.RentRecordResponse{}, err
+	}
+	// an edit without a payment date keeps the date the record was submitted with
+	if rentRecordRequest.PaidAt == "" {
+		editedRecord.PaidAt = rentRecord.PaidAt
+		editedRecord.SubmittedAt = rentRecord.SubmittedAt
+	}
+
+	changes := rentRecordChanges(rentRecord, editedRecord)
+	if len(changes) == 0 {
+		log.Info(spanCtx, fmt.Sprintf("Rent record %s is unchanged", rentRecordId))
+		return mappers.ToRentRecordResponse(rentRecord), nil
+	}
+
+	edit := models.RentRecordEdit{
+		Changes:  changes,
+		EditedBy: rentRecord.Tenant,
+		EditedAt: now,
+	}
+
+	updatedRentRecord, err := r.rentRecordRepository.EditPendingRentRecord(spanCtx, tenantId, rentRecordId, editedRecord, edit)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error editing rent record %s: %v", rentRecordId, err))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dto.RentRecordResponse{}, errors.New("rent record is not pending")
+		}
+		return dto.RentRecordResponse{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Edited rent record %s: %+v", rentRecordId, changes))
+
+	return mappers.ToRentRecordResponse(updatedRentRecord), nil
+}
+
+// WithdrawRentRecord implements RentRecordService.
+func (r *rentRecordService) WithdrawRentRecord(ctx context.Context, tenantId string, rentId string, rentRecordId string) (dto.RentRecordResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.WithdrawRentRecord")
+	defer span.End()
+
+	_, rentRecord, err := r.findPendingTenantRecord(spanCtx, tenantId, rentId, rentRecordId)
+	if err != nil {
+		return dto.RentRecordResponse{}, err
+	}
+
+	updatedRentRecord, err := r.rentRecordRepository.WithdrawRentRecord(spanCtx, tenantId, rentRecordId, models.RecordStatusChange{
+		Status:    models.RentRecordStatusWithdrawn,
+		Actor:     rentRecord.Tenant,
+		Role:      models.Tenant,
+		ChangedAt: time.Now(),
+	})
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error withdrawing rent record %s: %v", rentRecordId, err))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dto.RentRecordResponse{}, errors.New("rent record is not pending")
+		}
+		return dto.RentRecordResponse{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Withdrew rent record %s", rentRecordId))
+
+	return mappers.ToRentRecordResponse(updatedRentRecord), nil
+}
+
+// findPendingTenantRecord returns the record of the rent if it is pending and was submitted by the
+// tenant.
+func (r *rentRecordService) findPendingTenantRecord(ctx context.Context, tenantId string, rentId string, rentRecordId string) (models.Rent, models.RentRecord, error) {
+
+	log := utils.GetLogger()
+
+	rent, rentRecord, err := r.findRentRecord(ctx, tenantId, rentId, rentRecordId)
+	if err != nil {
+		return models.Rent{}, models.RentRecord{}, err
+	}
+
+	if rentRecord.Tenant.Id.Hex() != tenantId {
+		log.Error(ctx, fmt.Sprintf("Rent record %s was not submitted by %s", rentRecordId, tenantId))
+		return models.Rent{}, models.RentRecord{}, errors.New("only the tenant who submitted the record can change it")
+	}
+
+	if rentRecord.Status != models.RentRecordStatusPending {
+		log.Error(ctx, fmt.Sprintf("Rent record %s is not pending", rentRecordId))
+		return models.Rent{}, models.RentRecord{}, errors.New("rent record is not pending")
+	}
+
+	return rent, rentRecord, nil
+}
+
+// rentRecordChanges lists the payment details that differ between the record and its edit.
+func rentRecordChanges(rentRecord models.RentRecord, editedRecord models.RentRecord) []models.RentRecordChange {
+	var changes []models.RentRecordChange
+	addChange := func(field string, from string, to string) {
+		if from != to {
+			changes = append(changes, models.RentRecordChange{Field: field, From: from, To: to})
+		}
+	}
+	addChange("amount", rentRecord.Amount.String(), editedRecord.Amount.String())
+	addChange("payment_method", string(rentRecord.PaymentMethod), string(editedRecord.PaymentMethod))
+	addChange("reference", rentRecord.Reference, editedRecord.Reference)
+	addChange("paid_at", rentRecord.PaymentDate().Format("2006-01-02"), editedRecord.PaymentDate().Format("2006-01-02"))
+	return changes
+}
+
+// CreateLandLordRentRecord implements RentRecordService.
+func (r *rentRecordService) CreateLandLordRentRecord(ctx context.Context, landLordId string, rentId string, rentRecordRequest dto.LandLordRentRecordRequest) (dto.RentRecordResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.CreateLandLordRentRecord")
+	defer span.End()
+
+	rent, err := r.rentRepository.FindRentById(spanCtx, landLordId, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error fetching rent with ID %s: %v", rentId, err))
+		return dto.RentRecordResponse{}, err
+	}
+
+	if rent.LandLord.Id.Hex() != landLordId {
+		log.Error(spanCtx, fmt.Sprintf("User %s is not the landlord of rent %s", landLordId, rentId))
+		return dto.RentRecordResponse{}, errors.New("only the landlord can record payments for tenants")
+	}
+
+	tenant, err := findRecordTenant(rent, rentRecordRequest.TenantId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error finding tenant of rent %s: %v", rentId, err))
+		return dto.RentRecordResponse{}, err
+	}
+
+	dueDate, err := r.nextDueDate(spanCtx, rent, tenant)
+	if err != nil {
+		return dto.RentRecordResponse{}, err
+	}
+
+	now := time.Now()
+
+	newRentRecord, err := r.newRentRecord(spanCtx, rent, tenant, rentRecordRequest.RentRecordRequest, now, bson.NilObjectID)
+	if err != nil {
+		return dto.RentRecordResponse{}, err
+	}
+
+	// the landlord collected the payment, so it needs no review but the tenant can still dispute it
+	newRentRecord.DueDate = dueDate
+	newRentRecord.Status = models.RentRecordStatusApproved
+	newRentRecord.ApprovedAt = now
+	newRentRecord.UpdatedAt = now
+	newRentRecord.RecordedBy = models.LandLord
+	newRentRecord.ConfirmBy = now.AddDate(0, 0, r.rentConfig.RecordConfirmationWindowInDays)
+	newRentRecord.StatusHistory = []models.RecordStatusChange{{
+		Status:    models.RentRecordStatusApproved,
+		Actor:     rent.LandLord,
+		Role:      models.LandLord,
+		ChangedAt: now,
+	}}
+
+	rentRecord, err := r.rentRecordRepository.CreateRentRecord(spanCtx, newRentRecord)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error creating rent record: %v", err))
+		return dto.RentRecordResponse{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Landlord %s recorded rent record %s for tenant %s", landLordId, rentRecord.Id.Hex(), tenant.Id.Hex()))
+
+	if receiptRecord, err := r.issueReceipt(spanCtx, rent, rentRecord); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error issuing receipt for rent record %s: %v", rentRecord.Id.Hex(), err))
+	} else {
+		rentRecord = receiptRecord
+	}
+
+	message := fmt.Sprintf("%s recorded a payment of %s %s from you for %q. Open the app to confirm or dispute it by %s.",
+		rent.LandLord.Name, rentRecord.Amount.String(), rentRecord.Amount.Currency, rent.Title, rentRecord.ConfirmBy.Format("2006-01-02"))
+	if err := r.notificationService.SendSMS(spanCtx, tenant.PhoneNumber, message); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to notify tenant of rent record %s with %s", rentRecord.Id.Hex(), err.Error()))
+	}
+
+	return mappers.ToRentRecordResponse(rentRecord), nil
+}
+
+// ConfirmRentRecord implements RentRecordService.
+func (r *rentRecordService) ConfirmRentRecord(ctx context.Context, tenantId string, rentId string, rentRecordId string) (dto.RentRecordResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.ConfirmRentRecord")
+	defer span.End()
+
+	_, rentRecord, err := r.answerRentRecord(spanCtx, tenantId, rentId, rentRecordId, models.RecordConfirmation{
+		Status:      models.RecordConfirmationStatusConfirmed,
+		RespondedAt: time.Now(),
+	})
+	if err != nil {
+		return dto.RentRecordResponse{}, err
+	}
+
+	return mappers.ToRentRecordResponse(rentRecord), nil
+}
+
+// DisputeRentRecord implements RentRecordService.
+func (r *rentRecordService) DisputeRentRecord(ctx context.Context, tenantId string, rentId string, rentRecordId string, disputeRequest dto.DisputeRentRecordRequest) (dto.RentRecordResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.DisputeRentRecord")
+	defer span.End()
+
+	rent, rentRecord, err := r.answerRentRecord(spanCtx, tenantId, rentId, rentRecordId, models.RecordConfirmation{
+		Status:      models.RecordConfirmationStatusDisputed,
+		Comment:     strings.TrimSpace(disputeRequest.Comment),
+		RespondedAt: time.Now(),
+	})
+	if err != nil {
+		return dto.RentRecordResponse{}, err
+	}
+
+	landLord, err := r.userRepository.FindUserById(spanCtx, rent.LandLord.Id.Hex())
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find landlord with %s", err.Error()))
+		return mappers.ToRentRecordResponse(rentRecord), nil
+	}
+
+	message := fmt.Sprintf("%s disputed the payment of %s %s you recorded for %q: %s",
+		rentRecord.Tenant.Name, rentRecord.Amount.String(), rentRecord.Amount.Currency, rent.Title, rentRecord.Confirmation.Comment)
+	if err := r.notificationService.SendSMS(spanCtx, landLord.PhoneNumber, message); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to notify landlord of disputed rent record %s with %s", rentRecordId, err.Error()))
+	}
+
+	return mappers.ToRentRecordResponse(rentRecord), nil
+}
+
+// answerRentRecord stores the answer of the tenant to a record the landlord recorded for them.
+func (r *rentRecordService) answerRentRecord(ctx context.Context, tenantId string, rentId string, rentRecordId string, confirmation models.RecordConfirmation) (models.Rent, models.RentRecord, error) {
+
+	log := utils.GetLogger()
+
+	rent, rentRecord, err := r.findRentRecord(ctx, tenantId, rentId, rentRecordId)
+	if err != nil {
+		return models.Rent{}, models.RentRecord{}, err
+	}
+
+	if rentRecord.Tenant.Id.Hex() != tenantId {
+		log.Error(ctx, fmt.Sprintf("Rent record %s is not a payment of %s", rentRecordId, tenantId))
+		return models.Rent{}, models.RentRecord{}, errors.New("only the tenant the payment was recorded for can answer it")
+	}
+
+	if rentRecord.RecordedBy != models.LandLord {
+		log.Error(ctx, fmt.Sprintf("Rent record %s was not recorded by the landlord", rentRecordId))
+		return models.Rent{}, models.RentRecord{}, errors.New("only payments recorded by the landlord can be confirmed or disputed")
+	}
+
+	if rentRecord.Confirmation != nil {
+		log.Error(ctx, fmt.Sprintf("Rent record %s was already %s", rentRecordId, rentRecord.Confirmation.Status))
+		return models.Rent{}, models.RentRecord{}, fmt.Errorf("rent record was already %s", rentRecord.Confirmation.Status)
+	}
+
+	if !confirmation.RespondedAt.Before(rentRecord.ConfirmBy) {
+		log.Error(ctx, fmt.Sprintf("Confirmation window of rent record %s ended at %s", rentRecordId, rentRecord.ConfirmBy))
+		return models.Rent{}, models.RentRecord{}, errors.New("the confirmation window of the rent record has passed")
+	}
+
+	updatedRentRecord, err := r.rentRecordRepository.SetConfirmation(ctx, tenantId, rentRecordId, confirmation)
+	if err != nil {
+		log.Error(ctx, fmt.Sprintf("Error answering rent record %s: %v", rentRecordId, err))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Rent{}, models.RentRecord{}, errors.New("rent record was already answered")
+		}
+		return models.Rent{}, models.RentRecord{}, err
+	}
+
+	log.Info(ctx, fmt.Sprintf("Rent record %s %s by tenant %s", rentRecordId, confirmation.Status, tenantId))
+
+	return rent, updatedRentRecord, nil
+}
+
+// findRecordTenant returns the registered co-tenant with the ID, which can be empty when the rent
+// has a single registered co-tenant.
+func findRecordTenant(rent models.Rent, tenantId string) (models.RentTenant, error) {
+	if tenantId == "" {
+		tenants := registeredTenants(rent)
+		if len(tenants) != 1 {
+			return models.RentTenant{}, errors.New("tenant ID is required when the rent does not have exactly one registered tenant")
+		}
+		return tenants[0], nil
+	}
+
+	tenantObjectId, err := bson.ObjectIDFromHex(tenantId)
+	if err != nil {
+		return models.RentTenant{}, err
+	}
+
+	tenant, ok := rent.FindTenant(tenantObjectId)
+	if !ok {
+		return models.RentTenant{}, errors.New("user is not a registered tenant of this rent")
+	}
+	return tenant, nil
+}
+
+// BatchReviewRentRecords implements RentRecordService. Every record goes through ApproveRentRecord or
+// RejectRentRecord on its own, a failed record does not undo the records reviewed before it.
+func (r *rentRecordService) BatchReviewRentRecords(ctx context.Context, landLordId string, batchRequest dto.BatchRentRecordRequest) (dto.BatchRentRecordResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.BatchReviewRentRecords")
+	defer span.End()
+
+	if batchRequest.Action == "reject" && batchRequest.Reason == nil {
+		log.Error(spanCtx, "Reason is missing to reject rent records")
+		return dto.BatchRentRecordResponse{}, errors.New("reason is required to reject rent records")
+	}
+
+	batchResponse := dto.BatchRentRecordResponse{
+		Results: make([]dto.BatchRentRecordResult, 0, len(batchRequest.RecordIds)),
+	}
+
+	for _, rentRecordId := range batchRequest.RecordIds {
+		rentRecordResponse, err := r.reviewRentRecord(spanCtx, landLordId, rentRecordId, batchRequest)
+		if err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to %s rent record %s: %v", batchRequest.Action, rentRecordId, err))
+			message := err.Error()
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				message = "rent record not found"
+			}
+			batchResponse.Results = append(batchResponse.Results, dto.BatchRentRecordResult{RecordId: rentRecordId, Error: message})
+			batchResponse.Failed++
+			continue
+		}
+		batchResponse.Results = append(batchResponse.Results, dto.BatchRentRecordResult{RecordId: rentRecordId, Success: true, Record: &rentRecordResponse})
+		batchResponse.Succeeded++
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Batch %s of %d rent records: %d succeeded, %d failed", batchRequest.Action, len(batchRequest.RecordIds), batchResponse.Succeeded, batchResponse.Failed))
+
+	return batchResponse, nil
+}
+
+// reviewRentRecord applies the action of the batch to a single record, the rent is the one of the
+// record and the landlord has to own it.
+func (r *rentRecordService) reviewRentRecord(ctx context.Context, landLordId string, rentRecordId string, batchRequest dto.BatchRentRecordRequest) (dto.RentRecordResponse, error) {
+	rentRecord, err := r.rentRecordRepository.GetRentRecordById(ctx, rentRecordId)
+	if err != nil {
+		return dto.RentRecordResponse{}, err
+	}
+
+	if rentRecord.LandLord.Id.Hex() != landLordId {
+		return dto.RentRecordResponse{}, mongo.ErrNoDocuments
+	}
+
+	if batchRequest.Action == "reject" {
+		return r.RejectRentRecord(ctx, landLordId, rentRecord.RentId.Hex(), rentRecordId, *batchRequest.Reason)
+	}
+	return r.ApproveRentRecord(ctx, landLordId, rentRecord.RentId.Hex(), rentRecordId, dto.ApproveRentRecordRequest{})
+}
+
+// notifyAdjustedApproval texts the tenant the difference between what they submitted and what the
+// landlord approved, which can be more or less than the submitted amount. A failed message does not
+// undo the approval.
+func (r *rentRecordService) notifyAdjustedApproval(ctx context.Context, rent models.Rent, rentRecord models.RentRecord) {
+
+	log := utils.GetLogger()
+
+	tenant, ok := rent.FindTenant(rentRecord.Tenant.Id)
+	if !ok {
+		log.Error(ctx, fmt.Sprintf("Tenant %s of rent record %s is no longer a co-tenant", rentRecord.Tenant.Id.Hex(), rentRecord.Id.Hex()))
+		return
+	}
+
+	difference := rentRecord.ApprovedAmount.Sub(rentRecord.Amount)
+	comparison := "more"
+	if !difference.IsPositive() {
+		difference = rentRecord.Amount.Sub(*rentRecord.ApprovedAmount)
+		comparison = "less"
+	}
+	message := fmt.Sprintf("%s approved %s %s for your payment of %s %s for %q, %s %s %s than you submitted: %s",
+		rent.LandLord.Name, rentRecord.ApprovedAmount.String(), rentRecord.ApprovedAmount.Currency,
+		rentRecord.Amount.String(), rentRecord.Amount.Currency, rent.Title,
+		difference.String(), difference.Currency, comparison, rentRecord.ApprovalNote)
+
+	if err := r.notificationService.SendSMS(ctx, tenant.PhoneNumber, message); err != nil {
+		log.Error(ctx, fmt.Sprintf("Failed to notify tenant of adjusted rent record %s with %s", rentRecord.Id.Hex(), err.Error()))
+	}
+}
+
+// ReverseRentRecord implements RentRecordService. The approved record is marked reversed and a
+// compensating entry with the negative amount is added, neither counts as paid. The record keeps its
+// approval in its status history.
+func (r *rentRecordService) ReverseRentRecord(ctx context.Context, landLordId string, rentId string, rentRecordId string, reverseRequest dto.ReverseRentRecordRequest) (dto.RentRecordReversalResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentRecordService.ReverseRentRecord")
+	defer span.End()
+
+	rent, rentRecord, err := r.findRentRecord(spanCtx, landLordId, rentId, rentRecordId)
+	if err != nil {
+		return dto.RentRecordReversalResponse{}, err
+	}
+
+	if rent.LandLord.Id.Hex() != landLordId {
+		log.Error(spanCtx, fmt.Sprintf("User %s is not the landlord of rent %s", landLordId, rentId))
+		return dto.RentRecordReversalResponse{}, errors.New("only the landlord can reverse a rent record")
+	}
+
+	if rentRecord.Status != models.RentRecordStatusApproved {
+		log.Error(spanCtx, fmt.Sprintf("Rent record %s is %s", rentRecordId, rentRecord.Status))
+		return dto.RentRecordReversalResponse{}, errors.New("only approved rent records can be reversed")
+	}
+
+	if rentRecord.IsDisputed() {
+		log.Error(spanCtx, fmt.Sprintf("Rent record %s has open dispute %s", rentRecordId, rentRecord.Dispute.Id.Hex()))
+		return dto.RentRecordReversalResponse{}, errors.New("rent record has an open dispute, resolve it instead")
+	}
+
+	now := time.Now()
+
+	reversibleUntil := rentRecord.ApprovedAt.AddDate(0, 0, r.rentConfig.ReversalWindowInDays)
+	if now.After(reversibleUntil) {
+		log.Error(spanCtx, fmt.Sprintf("Reversal window of rent record %s ended at %s", rentRecordId, reversibleUntil))
+		return dto.RentRecordReversalResponse{}, fmt.Errorf("rent records can only be reversed within %d days of their approval", r.rentConfig.ReversalWindowInDays)
+	}
+
+	reason := strings.TrimSpace(reverseRequest.Reason)
+	change := models.RecordStatusChange{
+		Status:    models.RentRecordStatusReversed,
+		Actor:     rent.LandLord,
+		Role:      models.LandLord,
+		Reason:    reason,
+		ChangedAt: now,
+	}
+
+	entry := models.RentRecord{
+		Id:            bson.NewObjectID(),
+		RentId:        rentRecord.RentId,
+		Rent:          rentRecord.Rent,
+		Amount:        models.NewMoney(-rentRecord.SettledAmount().Minor, rentRecord.Amount.Currency),
+		DueDate:       rentRecord.DueDate,
+		PaymentMethod: rentRecord.PaymentMethod,
+		PaidAt:        now,
+		SubmittedAt:   now,
+		Status:        models.RentRecordStatusReversed,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		LandLord:      rentRecord.LandLord,
+		Tenant:        rentRecord.Tenant,
+		RecordedBy:    models.LandLord,
+		Reverses:      &rentRecord.Id,
+		StatusHistory: []models.RecordStatusChange{change},
+	}
+
+	// the entry is added first so a reversed record always has one, the unique reverses index makes
+	// concurrent reversals add a single entry
+	created, err := r.rentRecordRepository.CreateRentRecord(spanCtx, entry)
+	if mongo.IsDuplicateKeyError(err) {
+		// an earlier reversal added the entry without reversing the record, it is reused
+		created, err = r.rentRecordRepository.FindReversalEntry(spanCtx, rentRecord.Id)
+	}
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error creating compensating entry of rent record %s: %v", rentRecordId, err))
+		return dto.RentRecordReversalResponse{}, err
+	}
+	entry = created
+
+	if err := r.rentRecordRepository.ReverseApproval(spanCtx, rentRecord.Id, models.RecordReversal{
+		Reason:     reason,
+		EntryId:    entry.Id,
+		ReversedBy: rent.LandLord,
+		ReversedAt: now,
+	}, change); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error reversing rent record %s: %v", rentRecordId, err))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			r.discardReversalEntry(spanCtx, rentRecordId, entry.Id)
+			return dto.RentRecordReversalResponse{}, errors.New("rent record is no longer approved")
+		}
+		// the entry is kept, the record may have been reversed and a retry reuses it otherwise
+		return dto.RentRecordReversalResponse{}, err
+	}
+
+	reversedRecord, err := r.rentRecordRepository.GetRentRecordById(spanCtx, rentRecordId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Error fetching rent record with ID %s: %v", rentRecordId, err))
+		return dto.RentRecordReversalResponse{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Rent record %s reversed, compensated by %s", rentRecordId, entry.Id.Hex()))
+
+	message := fmt.Sprintf("%s reversed your payment of %s %s for %q: %s",
+		rent.LandLord.Name, rentRecord.SettledAmount().String(), rentRecord.Amount.Currency, rent.Title, reason)
+	if tenant, ok := rent.FindTenant(rentRecord.Tenant.Id); ok {
+		if err := r.notificationService.SendSMS(spanCtx, tenant.PhoneNumber, message); err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to notify tenant of reversed rent record %s with %s", rentRecordId, err.Error()))
+		}
+	}
+
+	return dto.RentRecordReversalResponse{
+		Record:            mappers.ToRentRecordResponse(reversedRecord),
+		CompensatingEntry: mappers.ToRentRecordResponse(entry),
 	}, nil
 }
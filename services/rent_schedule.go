@@ -0,0 +1,166 @@
+package services
+
+import (
+	"errors"
+	"sample-web/models"
+	"time"
+)
+
+// schedulePeriod returns the length of a period of the schedule in months and days, only one of
+// them is set.
+func schedulePeriod(schedule models.RentSchedule, interval *models.ScheduleInterval) (int, int) {
+	switch schedule {
+	case models.RentScheduleWeekly:
+		return 0, 7
+	case models.RentScheduleMonthly:
+		return 1, 0
+	case models.RentScheduleQuarterly:
+		return 3, 0
+	case models.RentScheduleHalfYearly:
+		return 6, 0
+	case models.RentScheduleYearly:
+		return 12, 0
+	case models.RentScheduleCustom:
+		if interval == nil {
+			return 0, 0
+		}
+		if interval.Unit == models.IntervalUnitMonths {
+			return interval.Every, 0
+		}
+		return 0, interval.Every
+	default:
+		return 0, 0
+	}
+}
+
+// installmentDueDate returns the due date of the nth installment of the rent, the first one is due
+// on the start date. Month based installments are computed from the start date rather than the
+// previous installment so a short month does not move the following due dates.
+func installmentDueDate(rent models.Rent, n int) time.Time {
+	months, days := schedulePeriod(rent.Schedule, rent.Interval)
+	if n == 0 || months == 0 {
+		return rent.StartDate.AddDate(0, 0, n*days)
+	}
+
+	anchorDay := rent.AnchorDay
+	if anchorDay == 0 {
+		anchorDay = rent.StartDate.Day()
+	}
+
+	start := rent.StartDate
+	firstOfMonth := time.Date(start.Year(), start.Month()+time.Month(n*months), 1,
+		start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+
+	// the day after the last day of the month is the first of the next one
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	if anchorDay > lastDay {
+		anchorDay = lastDay
+	}
+
+	return firstOfMonth.AddDate(0, 0, anchorDay-1)
+}
+
+// installmentDueDates returns the due dates of the rent installments, an installment is due at the
+// start of each period between the start and end date of the rent.
+func installmentDueDates(rent models.Rent) []time.Time {
+	var dueDates []time.Time
+	if months, days := schedulePeriod(rent.Schedule, rent.Interval); months == 0 && days == 0 {
+		return dueDates
+	}
+	for n := 0; ; n++ {
+		due := installmentDueDate(rent, n)
+		if !due.Before(rent.EndDate) {
+			break
+		}
+		dueDates = append(dueDates, due)
+	}
+	return dueDates
+}
+
+// nextUncoveredDueDate returns the earliest installment of the rent that is not in covered, the due
+// dates of the records paying an installment. An installment whose record was withdrawn, rejected or
+// reversed is owed again.
+func nextUncoveredDueDate(rent models.Rent, covered []time.Time) (time.Time, bool) {
+	coveredAt := make(map[int64]bool, len(covered))
+	for _, dueDate := range covered {
+		coveredAt[dueDate.UnixMilli()] = true
+	}
+	for _, dueDate := range installmentDueDates(rent) {
+		if !coveredAt[dueDate.UnixMilli()] {
+			return dueDate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// installmentsDueBy returns the number of installments that are due on or before the given time.
+func installmentsDueBy(rent models.Rent, at time.Time) int {
+	count := 0
+	for _, due := range installmentDueDates(rent) {
+		if due.After(at) {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// validateSchedule checks the schedule of the rent is complete and the lease runs for at least one
+// full period of it.
+func validateSchedule(rent models.Rent) error {
+	switch rent.Schedule {
+	case models.RentScheduleWeekly, models.RentScheduleMonthly, models.RentScheduleQuarterly,
+		models.RentScheduleHalfYearly, models.RentScheduleYearly:
+		if rent.Interval != nil {
+			return errors.New("interval is only supported for custom schedules")
+		}
+	case models.RentScheduleCustom:
+		if rent.Interval == nil || rent.Interval.Every < 1 {
+			return errors.New("custom schedule requires an interval of at least one")
+		}
+		if rent.Interval.Unit != models.IntervalUnitDays && rent.Interval.Unit != models.IntervalUnitMonths {
+			return errors.New("interval unit must be days or months")
+		}
+	default:
+		return errors.New("invalid rent schedule")
+	}
+
+	months, days := schedulePeriod(rent.Schedule, rent.Interval)
+	if rent.AnchorDay != 0 {
+		if months == 0 {
+			return errors.New("anchor day is only supported for month based schedules")
+		}
+		if rent.AnchorDay < 1 || rent.AnchorDay > 31 {
+			return errors.New("anchor day must be between 1 and 31")
+		}
+	}
+
+	if rent.StartDate.AddDate(0, months, days).After(rent.EndDate) {
+		return errors.New("rent must last at least one period of its schedule")
+	}
+
+	return nil
+}
+
+// installmentPeriod returns the first and last day of the installment due on the given date, the
+// last period ends the day before the rent does.
+func installmentPeriod(rent models.Rent, dueDate time.Time) (time.Time, time.Time, bool) {
+	dueDates := installmentDueDates(rent)
+	for n, due := range dueDates {
+		if !sameDay(due, dueDate) {
+			continue
+		}
+		end := rent.EndDate
+		if n+1 < len(dueDates) {
+			end = dueDates[n+1]
+		}
+		return due, end.AddDate(0, 0, -1), true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+func sameDay(a time.Time, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
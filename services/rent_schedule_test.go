@@ -0,0 +1,56 @@
+package services
+
+import (
+	"sample-web/models"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestInstallmentDueDate(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedule  models.RentSchedule
+		interval  *models.ScheduleInterval
+		start     time.Time
+		anchorDay int
+		n         int
+		want      time.Time
+	}{
+		{name: "first installment is due on the start date", schedule: models.RentScheduleMonthly, start: date(2025, time.January, 20), anchorDay: 5, n: 0, want: date(2025, time.January, 20)},
+		{name: "start day is the anchor by default", schedule: models.RentScheduleMonthly, start: date(2025, time.January, 20), n: 1, want: date(2025, time.February, 20)},
+		{name: "anchor day", schedule: models.RentScheduleMonthly, start: date(2025, time.January, 20), anchorDay: 5, n: 1, want: date(2025, time.February, 5)},
+		{name: "anchor day later in the month", schedule: models.RentScheduleMonthly, start: date(2025, time.January, 20), anchorDay: 25, n: 2, want: date(2025, time.March, 25)},
+		{name: "clamped to the end of february", schedule: models.RentScheduleMonthly, start: date(2025, time.January, 31), n: 1, want: date(2025, time.February, 28)},
+		{name: "clamped to the end of a leap february", schedule: models.RentScheduleMonthly, start: date(2024, time.January, 31), n: 1, want: date(2024, time.February, 29)},
+		{name: "short month does not move later due dates", schedule: models.RentScheduleMonthly, start: date(2025, time.January, 31), n: 2, want: date(2025, time.March, 31)},
+		{name: "clamped to the end of a 30 day month", schedule: models.RentScheduleMonthly, start: date(2025, time.April, 10), anchorDay: 31, n: 2, want: date(2025, time.June, 30)},
+		{name: "anchor day 31 in a long month", schedule: models.RentScheduleMonthly, start: date(2025, time.April, 10), anchorDay: 31, n: 1, want: date(2025, time.May, 31)},
+		{name: "across the year", schedule: models.RentScheduleMonthly, start: date(2024, time.November, 15), n: 3, want: date(2025, time.February, 15)},
+		{name: "quarterly clamped", schedule: models.RentScheduleQuarterly, start: date(2024, time.November, 30), n: 1, want: date(2025, time.February, 28)},
+		{name: "half yearly", schedule: models.RentScheduleHalfYearly, start: date(2025, time.March, 31), n: 1, want: date(2025, time.September, 30)},
+		{name: "yearly from a leap day", schedule: models.RentScheduleYearly, start: date(2024, time.February, 29), n: 1, want: date(2025, time.February, 28)},
+		{name: "yearly back on a leap day", schedule: models.RentScheduleYearly, start: date(2024, time.February, 29), n: 4, want: date(2028, time.February, 29)},
+		{name: "weekly ignores the anchor day", schedule: models.RentScheduleWeekly, start: date(2025, time.January, 1), anchorDay: 5, n: 3, want: date(2025, time.January, 22)},
+		{name: "custom days", schedule: models.RentScheduleCustom, interval: &models.ScheduleInterval{Every: 10, Unit: models.IntervalUnitDays}, start: date(2025, time.January, 1), n: 2, want: date(2025, time.January, 21)},
+		{name: "custom months clamped", schedule: models.RentScheduleCustom, interval: &models.ScheduleInterval{Every: 2, Unit: models.IntervalUnitMonths}, start: date(2024, time.December, 31), n: 1, want: date(2025, time.February, 28)},
+		{name: "time of day is kept", schedule: models.RentScheduleMonthly, start: time.Date(2025, time.January, 31, 9, 30, 0, 0, time.UTC), n: 1, want: time.Date(2025, time.February, 28, 9, 30, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rent := models.Rent{
+				Schedule:  test.schedule,
+				Interval:  test.interval,
+				StartDate: test.start,
+				AnchorDay: test.anchorDay,
+			}
+			if got := installmentDueDate(rent, test.n); !got.Equal(test.want) {
+				t.Errorf("installmentDueDate(%d) = %s, want %s", test.n, got.Format(time.RFC3339), test.want.Format(time.RFC3339))
+			}
+		})
+	}
+}
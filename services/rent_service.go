@@ -4,11 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"sample-web/configs"
 	"sample-web/dto"
+	"sample-web/events"
+	"sample-web/mappers"
 	"sample-web/models"
 	"sample-web/repositories"
 	"sample-web/utils"
 	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 type RentService interface {
@@ -17,17 +24,35 @@ type RentService interface {
 	GetRentById(ctx context.Context, userId string, rentId string) (dto.RentResponse, error)
 	UpdateRent(ctx context.Context, landLordId string, rentId string, rentRequest dto.RentUpdateRequest) (dto.RentResponse, error)
 	CloseRent(ctx context.Context, landLordId string, rentId string) (dto.RentResponse, error)
+	SummariseRent(ctx context.Context, userId string, rentId string) (dto.RentSummaryResponse, error)
+	GetRentAmendments(ctx context.Context, userId string, rentId string) (dto.RentAmendmentResponse, error)
+	AcknowledgeAmendment(ctx context.Context, tenantId string, rentId string, amendmentId string) (dto.RentAmendmentResponse, error)
+	TerminateRent(ctx context.Context, userId string, rentId string, terminationRequest dto.RentTerminationRequest) (dto.RentResponse, error)
+	CloseTerminatedRents(ctx context.Context, at time.Time) (int64, error)
+	TransitionRentLifecycles(ctx context.Context, at time.Time) (int, error)
 }
 
 type rentService struct {
-	rentRepo repositories.RentRepository
-	userRepo repositories.UserRepository
+	rentRepo            repositories.RentRepository
+	rentRecordRepo      repositories.RentRecordRepository
+	userRepo            repositories.UserRepository
+	unitRepo            repositories.UnitRepository
+	amendmentRepo       repositories.RentAmendmentRepository
+	notificationService NotificationService
+	rentConfig          configs.RentConfig
+	publisher           events.Publisher
 }
 
-func NewRentService(rentRepo repositories.RentRepository, userRepo repositories.UserRepository) RentService {
+func NewRentService(rentRepo repositories.RentRepository, rentRecordRepo repositories.RentRecordRepository, userRepo repositories.UserRepository, unitRepo repositories.UnitRepository, amendmentRepo repositories.RentAmendmentRepository, notificationService NotificationService, rentConfig configs.RentConfig, publisher events.Publisher) RentService {
 	return &rentService{
-		rentRepo: rentRepo,
-		userRepo: userRepo,
+		rentRepo:            rentRepo,
+		rentRecordRepo:      rentRecordRepo,
+		userRepo:            userRepo,
+		unitRepo:            unitRepo,
+		amendmentRepo:       amendmentRepo,
+		notificationService: notificationService,
+		rentConfig:          rentConfig,
+		publisher:           publisher,
 	}
 }
 
@@ -49,19 +74,29 @@ func (r *rentService) CreateRent(ctx context.Context, landLordId string, rentReq
 
 	log.Info(spanCtx, "landlord found with ID: %s", landLord.Id)
 
-	log.Info(spanCtx, "finding tenant with phone number: %s", rentRequest.TenantPhoneNumber)
+	tenantRequests := rentRequest.Tenants
+	if len(tenantRequests) == 0 {
+		tenantRequests = []dto.RentTenantRequest{{
+			PhoneNumber: rentRequest.TenantPhoneNumber,
+			ShareType:   string(models.ShareTypePercentage),
+			ShareValue:  "100",
+		}}
+	}
 
-	tenant, err := r.userRepo.FindUserByPhoneNumber(spanCtx, rentRequest.TenantPhoneNumber)
+	amount, err := models.ParseMoney(rentRequest.Amount.String(), rentRequest.Currency)
 	if err != nil {
-		log.Error(spanCtx, "Failed to find tenant with %s", err.Error())
-		return dto.RentResponse{}, errors.New("tenant not found")
+		log.Error(spanCtx, fmt.Sprintf("Invalid rent amount: %s", err.Error()))
+		return dto.RentResponse{}, err
 	}
 
-	log.Info(spanCtx, "tenant found with ID: %s", tenant.Id)
+	tenants, err := r.resolveTenants(spanCtx, landLord, tenantRequests, rentRequest.Currency)
+	if err != nil {
+		return dto.RentResponse{}, err
+	}
 
-	if landLord.Id == tenant.Id {
-		log.Error(spanCtx, "landlord and tenant cannot be the same person")
-		return dto.RentResponse{}, errors.New("landlord and tenant cannot be the same person")
+	if err := validateTenantShares(tenants, amount); err != nil {
+		log.Error(spanCtx, err.Error())
+		return dto.RentResponse{}, err
 	}
 
 	now := time.Now()
@@ -82,46 +117,42 @@ func (r *rentService) CreateRent(ctx context.Context, landLordId string, rentReq
 		return dto.RentResponse{}, errors.New("start date must be after current date and end date must be after start date")
 	}
 
-	switch models.RentSchedule(rentRequest.Schedule) {
-	case models.RentScheduleWeekly:
-		if endDate.Sub(startDate).Hours() < 168 || endDate.Sub(startDate).Hours() > 672 {
-			log.Error(spanCtx, "Weekly rent must be at least 7 days and at most 28 days")
-			return dto.RentResponse{}, errors.New("weekly rent must be at least 7 days and at most 28 days")
-		}
-	case models.RentScheduleMonthly:
-		if endDate.Sub(startDate).Hours() < 672 || endDate.Sub(startDate).Hours() > 2016 {
-			log.Error(spanCtx, "Monthly rent must be at least 28 days and at most 84 days")
-			return dto.RentResponse{}, errors.New("monthly rent must be at least 28 days and at most 84 days")
-		}
-	case models.RentScheduleQuarterly:
-		if endDate.Sub(startDate).Hours() < 2016 || endDate.Sub(startDate).Hours() > 6720 {
-			log.Error(spanCtx, "Quarterly rent must be at least 84 days and at most 280 days")
-			return dto.RentResponse{}, errors.New("quarterly rent must be at least 84 days and at most 280 days")
-		}
-	default:
-		log.Error(spanCtx, "Invalid rent schedule")
-		return dto.RentResponse{}, errors.New("invalid rent schedule")
-	}
-
 	rent := models.Rent{
 		LandLord: models.PersonRef{
 			Id:   landLord.Id,
 			Name: landLord.Name,
 		},
-		Tenant: models.PersonRef{
-			Id:   tenant.Id,
-			Name: tenant.Name,
-		},
+		Tenants:   tenants,
 		Title:     rentRequest.Title,
-		Amount:    rentRequest.Amount,
+		Amount:    amount,
 		Schedule:  models.RentSchedule(rentRequest.Schedule),
-		Status:    models.RentStatusActive,
+		Interval:  mappers.ToScheduleIntervalModel(rentRequest.Interval),
+		AnchorDay: rentRequest.AnchorDay,
+		Status:    models.RentStatusUpcoming,
 		StartDate: startDate,
 		EndDate:   endDate,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
+	if err := validateSchedule(rent); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Invalid rent schedule with %s", err.Error()))
+		return dto.RentResponse{}, err
+	}
+
+	if rentRequest.UnitId != "" {
+		unit, err := r.findVacantUnit(spanCtx, landLordId, rentRequest.UnitId, startDate, endDate)
+		if err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to use unit %s with %s", rentRequest.UnitId, err.Error()))
+			return dto.RentResponse{}, err
+		}
+		rent.Unit = &models.UnitRef{
+			Id:         unit.Id,
+			PropertyId: unit.PropertyId,
+			UnitNumber: unit.UnitNumber,
+		}
+	}
+
 	log.Info(spanCtx, "Creating rent with title: %s", rent.Title)
 
 	createdRent, err := r.rentRepo.CreateRent(ctx, rent)
@@ -133,6 +164,21 @@ func (r *rentService) CreateRent(ctx context.Context, landLordId string, rentReq
 
 	log.Info(spanCtx, "Rent created successfully with ID: %s", createdRent.Id)
 
+	r.publisher.Publish(spanCtx, events.Event{
+		Name:       events.RentCreated,
+		OccurredAt: now,
+		Data: events.RentCreatedData{
+			RentId:     createdRent.Id,
+			LandLordId: createdRent.LandLord.Id,
+		},
+	})
+
+	for _, tenant := range createdRent.Tenants {
+		if tenant.Invited {
+			r.sendTenantInvite(spanCtx, landLord, createdRent, tenant.PhoneNumber)
+		}
+	}
+
 	return dto.RentResponse{
 		Rents: []models.Rent{createdRent},
 	}, nil
@@ -189,19 +235,38 @@ func (r *rentService) UpdateRent(ctx context.Context, landLordId, rentId string,
 	}
 	log.Info(spanCtx, "Rent found with ID: %s", rent.Id)
 
-	if rent.Status == models.RentStatusInactive {
+	if rent.Status == models.RentStatusClosed {
 		log.Error(spanCtx, "Failed to update rent as it is already closed")
 		return dto.RentResponse{}, errors.New("rent is already closed")
 	}
 
+	if rent.Status == models.RentStatusNoticeGiven {
+		log.Error(spanCtx, "Failed to update rent as it is under notice")
+		return dto.RentResponse{}, errors.New("rent is under notice and cannot be amended")
+	}
+
+	previousTerms := rent.Terms()
+
 	if rentRequest.Title != "" {
 		rent.Title = rentRequest.Title
 	}
-	if rentRequest.Amount != 0 {
-		rent.Amount = rentRequest.Amount
+	if rentRequest.Amount != "" {
+		// the currency of a rent cannot change, amounts are always in the rent currency
+		amount, err := models.ParseMoney(rentRequest.Amount.String(), rent.Amount.Currency)
+		if err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Invalid rent amount: %s", err.Error()))
+			return dto.RentResponse{}, err
+		}
+		if err := validateTenantShares(rent.Tenants, amount); err != nil {
+			log.Error(spanCtx, err.Error())
+			return dto.RentResponse{}, err
+		}
+		rent.Amount = amount
 	}
 	if rentRequest.Schedule != "" {
 		rent.Schedule = models.RentSchedule(rentRequest.Schedule)
+		rent.Interval = mappers.ToScheduleIntervalModel(rentRequest.Interval)
+		rent.AnchorDay = rentRequest.AnchorDay
 	}
 	if rentRequest.EndDate != "" {
 		endDate, err := time.Parse("2006-01-02", rentRequest.EndDate)
@@ -215,6 +280,67 @@ func (r *rentService) UpdateRent(ctx context.Context, landLordId, rentId string,
 		}
 		rent.EndDate = endDate
 	}
+
+	if err := validateSchedule(rent); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Invalid rent schedule with %s", err.Error()))
+		return dto.RentResponse{}, err
+	}
+
+	amendment := models.RentAmendment{
+		RentId:    rent.Id,
+		ChangedBy: rent.LandLord,
+		Reason:    rentRequest.Reason,
+		Fields:    changedTermFields(previousTerms, rent.Terms()),
+		Previous:  previousTerms,
+		Proposed:  rent.Terms(),
+		CreatedAt: now,
+	}
+
+	if len(amendment.Fields) == 0 {
+		log.Info(spanCtx, "Rent terms are unchanged")
+		return dto.RentResponse{
+			Rents: []models.Rent{rent},
+		}, nil
+	}
+
+	version, err := r.amendmentRepo.CountAmendments(spanCtx, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to count rent amendments with %s", err.Error()))
+		return dto.RentResponse{}, err
+	}
+	amendment.Version = int(version) + 1
+	amendment.Acknowledgements = []models.RentAcknowledgement{}
+
+	// changes to what the tenants pay wait until every registered co-tenant acknowledged them
+	if affectsMoney(amendment.Fields) && len(registeredTenants(rent)) > 0 {
+		if _, err := r.amendmentRepo.FindPendingAmendment(spanCtx, rentId); err == nil {
+			return dto.RentResponse{}, errors.New("rent has an amendment waiting for acknowledgement")
+		} else if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Error(spanCtx, fmt.Sprintf("Failed to find pending amendment with %s", err.Error()))
+			return dto.RentResponse{}, err
+		}
+
+		amendment.Status = models.RentAmendmentStatusPending
+		if _, err := r.amendmentRepo.CreateAmendment(spanCtx, amendment); err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to create rent amendment with %s", err.Error()))
+			return dto.RentResponse{}, err
+		}
+
+		for _, tenant := range registeredTenants(rent) {
+			r.sendAmendmentNotice(spanCtx, rent, tenant.PhoneNumber)
+		}
+
+		log.Info(spanCtx, fmt.Sprintf("Rent amendment %d of rent %s is waiting for acknowledgement", amendment.Version, rentId))
+
+		unchangedRent, err := r.rentRepo.FindRentById(spanCtx, landLordId, rentId)
+		if err != nil {
+			return dto.RentResponse{}, err
+		}
+		return dto.RentResponse{
+			Rents: []models.Rent{unchangedRent},
+		}, nil
+	}
+
 	rent.UpdatedAt = now
 
 	updatedRent, err := r.rentRepo.UpdateRent(spanCtx, landLordId, rent)
@@ -224,6 +350,15 @@ func (r *rentService) UpdateRent(ctx context.Context, landLordId, rentId string,
 		return dto.RentResponse{}, err
 	}
 
+	amendment.Status = models.RentAmendmentStatusApplied
+	amendment.AppliedAt = &now
+	if _, err := r.amendmentRepo.CreateAmendment(spanCtx, amendment); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to create rent amendment with %s", err.Error()))
+		return dto.RentResponse{}, err
+	}
+
+	r.publishTermsChanged(spanCtx, updatedRent, amendment.Fields, now)
+
 	log.Info(spanCtx, "Rent updated successfully with ID: %s", updatedRent.Id)
 
 	return dto.RentResponse{
@@ -240,11 +375,11 @@ func (r *rentService) CloseRent(ctx context.Context, landLordId, rentId string)
 		return dto.RentResponse{}, err
 	}
 
-	if rent.Status == models.RentStatusInactive {
+	if rent.Status == models.RentStatusClosed {
 		return dto.RentResponse{}, errors.New("rent is already closed")
 	}
 
-	rent.Status = models.RentStatusInactive
+	rent.Status = models.RentStatusClosed
 
 	updatedRent, err := r.rentRepo.UpdateRent(ctx, landLordId, rent)
 
@@ -255,3 +390,657 @@ func (r *rentService) CloseRent(ctx context.Context, landLordId, rentId string)
 		Rents: []models.Rent{updatedRent},
 	}, nil
 }
+
+// SummariseRent implements RentService. The landlord gets the summary of the whole rent, a co-tenant
+// gets the summary of their own share.
+func (r *rentService) SummariseRent(ctx context.Context, userId string, rentId string) (dto.RentSummaryResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentService.SummariseRent")
+	defer span.End()
+
+	rent, err := r.rentRepo.FindRentById(spanCtx, userId, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent with %s", err.Error()))
+		return dto.RentSummaryResponse{}, err
+	}
+
+	installmentAmount := rent.Amount
+	tenantId := ""
+
+	if rent.LandLord.Id.Hex() != userId {
+		userObjectId, err := bson.ObjectIDFromHex(userId)
+		if err != nil {
+			log.Error(spanCtx, "Error converting user ID to ObjectID")
+			return dto.RentSummaryResponse{}, err
+		}
+		tenant, ok := rent.FindTenant(userObjectId)
+		if !ok {
+			log.Error(spanCtx, fmt.Sprintf("User %s is not a tenant of rent %s", userId, rentId))
+			return dto.RentSummaryResponse{}, errors.New("user is not a tenant of this rent")
+		}
+		installmentAmount = rent.ShareAmount(tenant)
+		tenantId = userId
+	}
+
+	summary, err := r.rentRecordRepo.SummariseRentRecords(spanCtx, rentId, tenantId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to summarise rent records with %s", err.Error()))
+		return dto.RentSummaryResponse{}, err
+	}
+
+	now := time.Now()
+	installmentsDue := installmentsDueBy(rent, now)
+	totalExpected := installmentAmount.Times(installmentsDue)
+	totalApproved := models.NewMoney(summary.Approved, rent.Amount.Currency)
+
+	outstanding := totalExpected.Sub(totalApproved)
+	if !outstanding.IsPositive() {
+		outstanding = models.NewMoney(0, rent.Amount.Currency)
+	}
+
+	response := dto.RentSummaryResponse{
+		RentId:          rent.Id.Hex(),
+		InstallmentsDue: installmentsDue,
+		TotalExpected:   totalExpected,
+		TotalApproved:   totalApproved,
+		Pending:         models.NewMoney(summary.Pending, rent.Amount.Currency),
+		Rejected:        models.NewMoney(summary.Rejected, rent.Amount.Currency),
+		DisputedRecords: summary.Disputed,
+		Outstanding:     outstanding,
+		OnTimePayments:  summary.OnTimePayments,
+		LatePayments:    summary.LatePayments,
+		DaysUntilEnd:    int(math.Max(0, math.Ceil(rent.EndDate.Sub(now).Hours()/24))),
+	}
+
+	if summary.LastPaymentDate != nil {
+		response.LastPaymentDate = summary.LastPaymentDate.Format(time.RFC3339)
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Summarised rent %s", rentId))
+
+	return response, nil
+}
+
+// findVacantUnit finds the landlord's unit and makes sure no open rent on it overlaps the given dates.
+func (r *rentService) findVacantUnit(ctx context.Context, landLordId string, unitId string, startDate time.Time, endDate time.Time) (models.Unit, error) {
+
+	unit, err := r.unitRepo.FindUnitById(ctx, landLordId, unitId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Unit{}, errors.New("unit not found")
+		}
+		return models.Unit{}, err
+	}
+
+	rents, err := r.rentRepo.GetRentsByUnitIds(ctx, landLordId, []bson.ObjectID{unit.Id})
+	if err != nil {
+		return models.Unit{}, err
+	}
+
+	for _, rent := range rents {
+		if isRentOpen(rent) && rent.StartDate.Before(endDate) && startDate.Before(rent.EndDate) {
+			return models.Unit{}, fmt.Errorf("unit %s is already let for these dates", unit.UnitNumber)
+		}
+	}
+
+	return unit, nil
+}
+
+// isRentOpen reports whether the rent has not been closed.
+func isRentOpen(rent models.Rent) bool {
+	return rent.Status != models.RentStatusClosed
+}
+
+// resolveTenants looks up the co-tenants by phone number. Unregistered co-tenants are invited,
+// the rent gets attached to them once they register.
+func (r *rentService) resolveTenants(ctx context.Context, landLord models.User, tenantRequests []dto.RentTenantRequest, currency string) ([]models.RentTenant, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentService.resolveTenants")
+	defer span.End()
+
+	seen := make(map[string]bool)
+	tenants := make([]models.RentTenant, 0, len(tenantRequests))
+
+	for _, tenantRequest := range tenantRequests {
+
+		if landLord.PhoneNumber == tenantRequest.PhoneNumber {
+			log.Error(spanCtx, "landlord and tenant cannot be the same person")
+			return nil, errors.New("landlord and tenant cannot be the same person")
+		}
+
+		if seen[tenantRequest.PhoneNumber] {
+			log.Error(spanCtx, fmt.Sprintf("tenant %s is added more than once", tenantRequest.PhoneNumber))
+			return nil, fmt.Errorf("tenant %s is added more than once", tenantRequest.PhoneNumber)
+		}
+		seen[tenantRequest.PhoneNumber] = true
+
+		share, err := parseTenantShare(tenantRequest, currency)
+		if err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Invalid share for tenant %s: %s", tenantRequest.PhoneNumber, err.Error()))
+			return nil, err
+		}
+
+		tenant := models.RentTenant{
+			PhoneNumber: tenantRequest.PhoneNumber,
+			Share:       share,
+		}
+
+		log.Info(spanCtx, fmt.Sprintf("finding tenant with phone number: %s", tenantRequest.PhoneNumber))
+
+		user, err := r.userRepo.FindUserByPhoneNumber(spanCtx, tenantRequest.PhoneNumber)
+		if err != nil {
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				log.Error(spanCtx, fmt.Sprintf("Failed to find tenant with %s", err.Error()))
+				return nil, errors.New("failed to find tenant")
+			}
+			log.Info(spanCtx, fmt.Sprintf("tenant with phone number %s is not registered, inviting", tenantRequest.PhoneNumber))
+			tenant.Invited = true
+		} else {
+			log.Info(spanCtx, fmt.Sprintf("tenant found with ID: %s", user.Id.Hex()))
+			tenant.Id = user.Id
+			tenant.Name = user.Name
+		}
+
+		tenants = append(tenants, tenant)
+	}
+
+	return tenants, nil
+}
+
+// parseTenantShare parses the share of a co-tenant, fixed amounts are in the rent currency.
+func parseTenantShare(tenantRequest dto.RentTenantRequest, currency string) (models.TenantShare, error) {
+	share := models.TenantShare{Type: models.ShareType(tenantRequest.ShareType)}
+
+	if share.Type == models.ShareTypePercentage {
+		percentage, err := models.ParsePercentage(tenantRequest.ShareValue.String())
+		if err != nil {
+			return models.TenantShare{}, err
+		}
+		share.Percentage = percentage
+		return share, nil
+	}
+
+	amount, err := models.ParseMoney(tenantRequest.ShareValue.String(), currency)
+	if err != nil {
+		return models.TenantShare{}, err
+	}
+	share.Amount = &amount
+	return share, nil
+}
+
+// validateTenantShares checks that the co-tenant shares add up exactly to the rent amount. The
+// check is done in basis points of minor units so percentages that do not divide the amount evenly
+// are still accepted, the leftover is spread by Rent.ShareAmounts.
+func validateTenantShares(tenants []models.RentTenant, rentAmount models.Money) error {
+	var amounts, basisPoints int64
+	for _, tenant := range tenants {
+		if tenant.Share.Type == models.ShareTypePercentage {
+			if tenant.Share.Percentage > 10000 {
+				return errors.New("tenant share percentage cannot be more than 100")
+			}
+			basisPoints += tenant.Share.Percentage
+			continue
+		}
+		if tenant.Share.Amount == nil || tenant.Share.Amount.Currency != rentAmount.Currency {
+			return errors.New("tenant share amount must be in the rent currency")
+		}
+		amounts += tenant.Share.Amount.Minor
+	}
+	if basisPoints > 10000 || amounts > rentAmount.Minor ||
+		amounts*10000+basisPoints*rentAmount.Minor != rentAmount.Minor*10000 {
+		return fmt.Errorf("tenant shares do not add up to the rent amount of %s %s", rentAmount, rentAmount.Currency)
+	}
+	return nil
+}
+
+// GetRentAmendments implements RentService.
+func (r *rentService) GetRentAmendments(ctx context.Context, userId string, rentId string) (dto.RentAmendmentResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentService.GetRentAmendments")
+	defer span.End()
+
+	// the rent lookup makes sure the user is the landlord or a co-tenant of the rent
+	if _, err := r.rentRepo.FindRentById(spanCtx, userId, rentId); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent with %s", err.Error()))
+		return dto.RentAmendmentResponse{}, err
+	}
+
+	amendments, err := r.amendmentRepo.GetAmendmentsByRent(spanCtx, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get rent amendments with %s", err.Error()))
+		return dto.RentAmendmentResponse{}, err
+	}
+
+	return dto.RentAmendmentResponse{
+		Amendments: amendments,
+	}, nil
+}
+
+// AcknowledgeAmendment implements RentService.
+func (r *rentService) AcknowledgeAmendment(ctx context.Context, tenantId string, rentId string, amendmentId string) (dto.RentAmendmentResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentService.AcknowledgeAmendment")
+	defer span.End()
+
+	rent, err := r.rentRepo.FindRentById(spanCtx, tenantId, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent with %s", err.Error()))
+		return dto.RentAmendmentResponse{}, err
+	}
+
+	tenantObjectId, err := bson.ObjectIDFromHex(tenantId)
+	if err != nil {
+		return dto.RentAmendmentResponse{}, errors.New("invalid tenant id")
+	}
+
+	tenant, ok := rent.FindTenant(tenantObjectId)
+	if !ok {
+		return dto.RentAmendmentResponse{}, errors.New("only co-tenants can acknowledge an amendment")
+	}
+
+	amendment, err := r.amendmentRepo.FindAmendmentById(spanCtx, rentId, amendmentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent amendment with %s", err.Error()))
+		return dto.RentAmendmentResponse{}, err
+	}
+
+	if amendment.Status != models.RentAmendmentStatusPending {
+		return dto.RentAmendmentResponse{}, errors.New("amendment is not waiting for acknowledgement")
+	}
+
+	if rent.Status == models.RentStatusClosed || rent.Status == models.RentStatusNoticeGiven {
+		return dto.RentAmendmentResponse{}, errors.New("rent can no longer be amended")
+	}
+
+	now := time.Now()
+
+	amendment, err = r.amendmentRepo.AddAcknowledgement(spanCtx, amendmentId, models.RentAcknowledgement{
+		Tenant:         models.PersonRef{Id: tenant.Id, Name: tenant.Name},
+		AcknowledgedAt: now,
+	})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dto.RentAmendmentResponse{}, errors.New("amendment is already acknowledged")
+		}
+		log.Error(spanCtx, fmt.Sprintf("Failed to acknowledge rent amendment with %s", err.Error()))
+		return dto.RentAmendmentResponse{}, err
+	}
+
+	for _, coTenant := range registeredTenants(rent) {
+		if !amendment.IsAcknowledgedBy(coTenant.Id) {
+			log.Info(spanCtx, fmt.Sprintf("Rent amendment %s is waiting for other co-tenants", amendmentId))
+			return dto.RentAmendmentResponse{
+				Amendments: []models.RentAmendment{amendment},
+			}, nil
+		}
+	}
+
+	// other terms may have been amended while this one was pending, only its own fields are applied
+	applyTerms(&rent, amendment.Proposed, amendment.Fields)
+	if err := validateSchedule(rent); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Amended rent schedule is no longer valid with %s", err.Error()))
+		return dto.RentAmendmentResponse{}, err
+	}
+
+	// marking the amendment applied first makes sure concurrent acknowledgements apply it once
+	if err := r.amendmentRepo.MarkApplied(spanCtx, amendmentId, now); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dto.RentAmendmentResponse{}, errors.New("amendment is already applied")
+		}
+		log.Error(spanCtx, fmt.Sprintf("Failed to apply rent amendment with %s", err.Error()))
+		return dto.RentAmendmentResponse{}, err
+	}
+
+	// only the amended fields are written, the status and agreement of the rent may have moved on
+	if err := r.rentRepo.ApplyTerms(spanCtx, rent.Id, amendment.Proposed, amendment.Fields, now); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to update rent with %s", err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dto.RentAmendmentResponse{}, errors.New("rent can no longer be amended")
+		}
+		return dto.RentAmendmentResponse{}, err
+	}
+
+	amendment.Status = models.RentAmendmentStatusApplied
+	amendment.AppliedAt = &now
+
+	r.publishTermsChanged(spanCtx, rent, amendment.Fields, now)
+
+	log.Info(spanCtx, fmt.Sprintf("Rent amendment %s applied to rent %s", amendmentId, rentId))
+
+	return dto.RentAmendmentResponse{
+		Amendments: []models.RentAmendment{amendment},
+	}, nil
+}
+
+// TerminateRent implements RentService. Either the landlord or a co-tenant can give notice, the rent
+// ends on the move-out date which must leave at least the configured notice period.
+func (r *rentService) TerminateRent(ctx context.Context, userId string, rentId string, terminationRequest dto.RentTerminationRequest) (dto.RentResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentService.TerminateRent")
+	defer span.End()
+
+	rent, err := r.rentRepo.FindRentById(spanCtx, userId, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent with %s", err.Error()))
+		return dto.RentResponse{}, err
+	}
+
+	switch rent.Status {
+	case models.RentStatusClosed:
+		return dto.RentResponse{}, errors.New("rent is already closed")
+	case models.RentStatusNoticeGiven:
+		return dto.RentResponse{}, errors.New("notice has already been given for this rent")
+	}
+
+	userObjectId, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return dto.RentResponse{}, errors.New("invalid user id")
+	}
+
+	var requestedBy models.PersonRef
+	var requestedByRole models.UserRole
+	if rent.LandLord.Id == userObjectId {
+		requestedBy = rent.LandLord
+		requestedByRole = models.LandLord
+	} else if tenant, ok := rent.FindTenant(userObjectId); ok {
+		requestedBy = models.PersonRef{Id: tenant.Id, Name: tenant.Name}
+		requestedByRole = models.Tenant
+	} else {
+		return dto.RentResponse{}, errors.New("only the landlord or a co-tenant can give notice")
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	earliestMoveOut := today.AddDate(0, 0, r.rentConfig.NoticePeriodInDays)
+
+	moveOutDate := earliestMoveOut
+	if terminationRequest.MoveOutDate != "" {
+		moveOutDate, err = time.Parse("2006-01-02", terminationRequest.MoveOutDate)
+		if err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to parse move-out date with %s", err.Error()))
+			return dto.RentResponse{}, errors.New("failed to parse move-out date")
+		}
+	}
+
+	if moveOutDate.Before(earliestMoveOut) {
+		return dto.RentResponse{}, fmt.Errorf("move-out date must be at least %d days from today", r.rentConfig.NoticePeriodInDays)
+	}
+	if !moveOutDate.After(rent.StartDate) {
+		return dto.RentResponse{}, errors.New("move-out date must be after the start date")
+	}
+	if !moveOutDate.Before(rent.EndDate) {
+		return dto.RentResponse{}, errors.New("move-out date must be before the end date, the rent ends on its own")
+	}
+
+	termination := models.RentTermination{
+		RequestedBy:     requestedBy,
+		RequestedByRole: requestedByRole,
+		Reason:          terminationRequest.Reason,
+		NoticeGivenAt:   now,
+		MoveOutDate:     moveOutDate,
+		OriginalEndDate: rent.EndDate,
+	}
+
+	// only the notice is written, the rent may have been closed or noticed concurrently
+	if err := r.rentRepo.SetTermination(spanCtx, rent.Id, termination, now); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to give notice for rent with %s", err.Error()))
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return dto.RentResponse{}, errors.New("rent is closed or notice has already been given")
+		}
+		return dto.RentResponse{}, err
+	}
+
+	updatedRent, err := r.rentRepo.FindRentById(spanCtx, userId, rentId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rent with %s", err.Error()))
+		return dto.RentResponse{}, err
+	}
+
+	// notice uses a right the agreement grants, its terms are unchanged so it stays valid
+	r.sendTerminationNotice(spanCtx, updatedRent)
+
+	log.Info(spanCtx, fmt.Sprintf("Notice given for rent %s by %s, move-out on %s", rentId, requestedByRole, moveOutDate.Format("2006-01-02")))
+
+	return dto.RentResponse{
+		Rents: []models.Rent{updatedRent},
+	}, nil
+}
+
+// CloseTerminatedRents implements RentService.
+func (r *rentService) CloseTerminatedRents(ctx context.Context, at time.Time) (int64, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentService.CloseTerminatedRents")
+	defer span.End()
+
+	closed, err := r.rentRepo.CloseTerminatedRents(spanCtx, at)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to close terminated rents with %s", err.Error()))
+		return 0, err
+	}
+
+	return closed, nil
+}
+
+// TransitionRentLifecycles implements RentService. Each rent is moved with a compare-and-set on its
+// status so running it again, or on another replica at the same time, does not repeat a transition.
+func (r *rentService) TransitionRentLifecycles(ctx context.Context, at time.Time) (int, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentService.TransitionRentLifecycles")
+	defer span.End()
+
+	rents, err := r.rentRepo.FindRentsDueForTransition(spanCtx, at)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find rents due for a transition with %s", err.Error()))
+		return 0, err
+	}
+
+	transitioned := 0
+	for _, rent := range rents {
+		status := lifecycleStatus(rent, at)
+		if status == rent.Status {
+			continue
+		}
+
+		updated, err := r.rentRepo.UpdateRentStatus(spanCtx, rent.Id, rent.Status, status, at)
+		if err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to move rent %s to %s with %s", rent.Id.Hex(), status, err.Error()))
+			continue
+		}
+		if !updated {
+			// the rent was changed in the meantime, the next run picks it up if still needed
+			continue
+		}
+
+		tenantIds := make([]bson.ObjectID, 0, len(rent.Tenants))
+		for _, tenant := range registeredTenants(rent) {
+			tenantIds = append(tenantIds, tenant.Id)
+		}
+
+		r.publisher.Publish(spanCtx, events.Event{
+			Name:       events.RentStatusChanged,
+			OccurredAt: at,
+			Data: events.RentStatusChangedData{
+				RentId:     rent.Id,
+				LandLordId: rent.LandLord.Id,
+				TenantIds:  tenantIds,
+				From:       rent.Status,
+				To:         status,
+			},
+		})
+		transitioned++
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Moved %d of %d rents to their lifecycle status", transitioned, len(rents)))
+
+	return transitioned, nil
+}
+
+// lifecycleStatus returns the status the dates of the rent call for, rents under notice or closed
+// keep their status.
+func lifecycleStatus(rent models.Rent, at time.Time) models.RentStatus {
+	switch rent.Status {
+	case models.RentStatusUpcoming, models.RentStatusActive, models.RentStatusExpired:
+	default:
+		return rent.Status
+	}
+
+	if rent.StartDate.After(at) {
+		return models.RentStatusUpcoming
+	}
+	if !rent.EndDate.After(at) {
+		return models.RentStatusExpired
+	}
+	return models.RentStatusActive
+}
+
+// sendTerminationNotice lets everyone on the rent apart from whoever gave notice know about the
+// move-out date, failed SMS are only logged.
+func (r *rentService) sendTerminationNotice(ctx context.Context, rent models.Rent) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentService.sendTerminationNotice")
+	defer span.End()
+
+	termination := rent.Termination
+	message := fmt.Sprintf("%s has given notice to end the rent of %q, the move-out date is %s.",
+		termination.RequestedBy.Name, rent.Title, termination.MoveOutDate.Format("2006-01-02"))
+
+	var phoneNumbers []string
+	if termination.RequestedByRole == models.Tenant {
+		landLord, err := r.userRepo.FindUserById(spanCtx, rent.LandLord.Id.Hex())
+		if err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to find landlord with %s", err.Error()))
+		} else {
+			phoneNumbers = append(phoneNumbers, landLord.PhoneNumber)
+		}
+	}
+	for _, tenant := range registeredTenants(rent) {
+		if tenant.Id != termination.RequestedBy.Id {
+			phoneNumbers = append(phoneNumbers, tenant.PhoneNumber)
+		}
+	}
+
+	for _, phoneNumber := range phoneNumbers {
+		if err := r.notificationService.SendSMS(spanCtx, phoneNumber, message); err != nil {
+			log.Error(spanCtx, fmt.Sprintf("Failed to send termination notice for rent %s with %s", rent.Id.Hex(), err.Error()))
+		}
+	}
+}
+
+// changedTermFields returns the names of the terms that differ, the interval and anchor day are
+// part of the schedule.
+func changedTermFields(previous models.RentTerms, proposed models.RentTerms) []string {
+	var fields []string
+	if previous.Title != proposed.Title {
+		fields = append(fields, "title")
+	}
+	if previous.Amount != proposed.Amount {
+		fields = append(fields, "amount")
+	}
+	if previous.Schedule != proposed.Schedule || previous.AnchorDay != proposed.AnchorDay ||
+		!sameInterval(previous.Interval, proposed.Interval) {
+		fields = append(fields, "schedule")
+	}
+	if !previous.EndDate.Equal(proposed.EndDate) {
+		fields = append(fields, "end_date")
+	}
+	return fields
+}
+
+func sameInterval(a *models.ScheduleInterval, b *models.ScheduleInterval) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (r *rentService) publishTermsChanged(ctx context.Context, rent models.Rent, fields []string, at time.Time) {
+	r.publisher.Publish(ctx, events.Event{
+		Name:       events.RentTermsChanged,
+		OccurredAt: at,
+		Data: events.RentTermsChangedData{
+			RentId:     rent.Id,
+			LandLordId: rent.LandLord.Id,
+			Fields:     fields,
+		},
+	})
+}
+
+// affectsMoney reports whether the amended fields change what the tenants pay.
+func affectsMoney(fields []string) bool {
+	for _, field := range fields {
+		if field == "amount" || field == "schedule" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTerms sets the given fields of the terms on the rent.
+func applyTerms(rent *models.Rent, terms models.RentTerms, fields []string) {
+	for _, field := range fields {
+		switch field {
+		case "title":
+			rent.Title = terms.Title
+		case "amount":
+			rent.Amount = terms.Amount
+		case "schedule":
+			rent.Schedule = terms.Schedule
+			rent.Interval = terms.Interval
+			rent.AnchorDay = terms.AnchorDay
+		case "end_date":
+			rent.EndDate = terms.EndDate
+		}
+	}
+}
+
+// registeredTenants returns the co-tenants of the rent that have an account.
+func registeredTenants(rent models.Rent) []models.RentTenant {
+	var tenants []models.RentTenant
+	for _, tenant := range rent.Tenants {
+		if !tenant.Invited {
+			tenants = append(tenants, tenant)
+		}
+	}
+	return tenants
+}
+
+// sendAmendmentNotice asks a co-tenant to acknowledge an amendment, a failed SMS does not fail the
+// amendment as it is listed on the rent anyway.
+func (r *rentService) sendAmendmentNotice(ctx context.Context, rent models.Rent, phoneNumber string) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentService.sendAmendmentNotice")
+	defer span.End()
+
+	message := fmt.Sprintf("%s has proposed changes to the rent of %q. Open the app to review and acknowledge them.", rent.LandLord.Name, rent.Title)
+
+	if err := r.notificationService.SendSMS(spanCtx, phoneNumber, message); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to send amendment notice for rent %s with %s", rent.Id.Hex(), err.Error()))
+	}
+}
+
+// sendTenantInvite notifies an unregistered co-tenant about the rent. A failed SMS does not fail the
+// rent creation, the rent is still attached to the tenant when they register.
+func (r *rentService) sendTenantInvite(ctx context.Context, landLord models.User, rent models.Rent, phoneNumber string) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "RentService.sendTenantInvite")
+	defer span.End()
+
+	message := fmt.Sprintf("%s has added you as a tenant of %q. Register with this phone number to view and pay your rent.", landLord.Name, rent.Title)
+
+	if err := r.notificationService.SendSMS(spanCtx, phoneNumber, message); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to send invite for rent %s with %s", rent.Id.Hex(), err.Error()))
+		return
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Invite sent for rent %s", rent.Id.Hex()))
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"sample-web/models"
+	"testing"
+)
+
+func percentageTenant(basisPoints int64) models.RentTenant {
+	return models.RentTenant{Share: models.TenantShare{Type: models.ShareTypePercentage, Percentage: basisPoints}}
+}
+
+func amountTenant(amount models.Money) models.RentTenant {
+	return models.RentTenant{Share: models.TenantShare{Type: models.ShareTypeAmount, Amount: &amount}}
+}
+
+func TestValidateTenantShares(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  models.Money
+		tenants []models.RentTenant
+		// shares is what each co-tenant pays per installment when the shares are valid
+		shares  []int64
+		wantErr bool
+	}{
+		{
+			name:    "single tenant pays everything",
+			amount:  models.NewMoney(100000, "INR"),
+			tenants: []models.RentTenant{percentageTenant(10000)},
+			shares:  []int64{100000},
+		},
+		{
+			name:    "even split",
+			amount:  models.NewMoney(100000, "INR"),
+			tenants: []models.RentTenant{percentageTenant(5000), percentageTenant(5000)},
+			shares:  []int64{50000, 50000},
+		},
+		{
+			name:    "leftover minor unit goes to the first percentage",
+			amount:  models.NewMoney(10001, "INR"),
+			tenants: []models.RentTenant{percentageTenant(3333), percentageTenant(3333), percentageTenant(3334)},
+			shares:  []int64{3334, 3333, 3334},
+		},
+		{
+			name:    "leftover minor units are handed out one by one",
+			amount:  models.NewMoney(100, "JPY"),
+			tenants: []models.RentTenant{percentageTenant(3333), percentageTenant(3333), percentageTenant(3334)},
+			shares:  []int64{34, 33, 33},
+		},
+		{
+			name:    "fixed amount and percentage of the rest",
+			amount:  models.NewMoney(100000, "INR"),
+			tenants: []models.RentTenant{amountTenant(models.NewMoney(60000, "INR")), percentageTenant(4000)},
+			shares:  []int64{60000, 40000},
+		},
+		{
+			name:    "fixed amounts only",
+			amount:  models.NewMoney(100000, "INR"),
+			tenants: []models.RentTenant{amountTenant(models.NewMoney(70000, "INR")), amountTenant(models.NewMoney(30000, "INR"))},
+			shares:  []int64{70000, 30000},
+		},
+		{
+			name:    "percentages below the rent",
+			amount:  models.NewMoney(100000, "INR"),
+			tenants: []models.RentTenant{percentageTenant(5000), percentageTenant(4000)},
+			wantErr: true,
+		},
+		{
+			name:    "percentages above the rent",
+			amount:  models.NewMoney(100000, "INR"),
+			tenants: []models.RentTenant{percentageTenant(6000), percentageTenant(5000)},
+			wantErr: true,
+		},
+		{
+			name:    "percentage above 100",
+			amount:  models.NewMoney(100000, "INR"),
+			tenants: []models.RentTenant{percentageTenant(10001)},
+			wantErr: true,
+		},
+		{
+			name:    "fixed amount above the rent",
+			amount:  models.NewMoney(100000, "INR"),
+			tenants: []models.RentTenant{amountTenant(models.NewMoney(100001, "INR")), percentageTenant(0)},
+			wantErr: true,
+		},
+		{
+			name:    "fixed amount and percentage above the rent",
+			amount:  models.NewMoney(100000, "INR"),
+			tenants: []models.RentTenant{amountTenant(models.NewMoney(60000, "INR")), percentageTenant(5000)},
+			wantErr: true,
+		},
+		{
+			name:    "fixed amount in another currency",
+			amount:  models.NewMoney(100000, "INR"),
+			tenants: []models.RentTenant{amountTenant(models.NewMoney(100000, "USD"))},
+			wantErr: true,
+		},
+		{
+			name:    "fixed share without an amount",
+			amount:  models.NewMoney(100000, "INR"),
+			tenants: []models.RentTenant{{Share: models.TenantShare{Type: models.ShareTypeAmount}}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateTenantShares(test.tenants, test.amount)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("validateTenantShares succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateTenantShares failed with %v", err)
+			}
+
+			rent := models.Rent{Amount: test.amount, Tenants: test.tenants}
+			shareAmounts := rent.ShareAmounts()
+			var total int64
+			for i, share := range shareAmounts {
+				if share.Minor != test.shares[i] || share.Currency != test.amount.Currency {
+					t.Errorf("share of tenant %d = %v, want %d %s", i, share, test.shares[i], test.amount.Currency)
+				}
+				total += share.Minor
+			}
+			if total != test.amount.Minor {
+				t.Errorf("shares add up to %d, want %d", total, test.amount.Minor)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"sample-web/clients"
+	"sample-web/utils"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	signatureOTPExpiryInMinutes = 10
+	signatureOTPTotalRetries    = 3
+	signatureOTPKeyPrefix       = "signature_otp"
+)
+
+// SignatureOTPService sends the codes that confirm a signature. A code is bound to the hash of the
+// document it was sent for, so neither a login OTP nor the code of another agreement can sign it.
+type SignatureOTPService interface {
+	SendOTP(ctx context.Context, phoneNumber string, documentHash string) error
+	VerifyOTP(ctx context.Context, phoneNumber string, documentHash string, code string) (bool, error)
+}
+
+type redisSignatureOTPService struct {
+	redisClient         *clients.RedisClient
+	notificationService NotificationService
+	expiry              time.Duration
+}
+
+func NewSignatureOTPService(redisClient *clients.RedisClient, notificationService NotificationService) SignatureOTPService {
+	return &redisSignatureOTPService{
+		redisClient:         redisClient,
+		notificationService: notificationService,
+		expiry:              signatureOTPExpiryInMinutes * time.Minute,
+	}
+}
+
+// SendOTP replaces the code of the document with a new one and texts it, the message names the start
+// of the document hash so the signer can check it against the PDF they are signing.
+func (s *redisSignatureOTPService) SendOTP(ctx context.Context, phoneNumber string, documentHash string) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "SignatureOTPService.SendOTP")
+	defer span.End()
+
+	if blocked, ttl := s.isBlocked(spanCtx, phoneNumber, documentHash); blocked {
+		return &PhoneNumberBlockedError{PhoneNumber: phoneNumber, RetryAfter: ttl}
+	}
+
+	number, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	code := fmt.Sprintf("%06d", number.Int64())
+
+	if err := s.redisClient.Client.Set(spanCtx, s.buildCodeKey(phoneNumber, documentHash), code, s.expiry).Err(); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	message := fmt.Sprintf("Your code to sign the rental agreement %s is %s, it expires in %d minutes.",
+		documentHash[:8], code, signatureOTPExpiryInMinutes)
+	return s.notificationService.SendSMS(spanCtx, phoneNumber, message)
+}
+
+// VerifyOTP checks the code sent for the document, the code is used up once it matches.
+func (s *redisSignatureOTPService) VerifyOTP(ctx context.Context, phoneNumber string, documentHash string, code string) (bool, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "SignatureOTPService.VerifyOTP")
+	defer span.End()
+
+	if blocked, ttl := s.isBlocked(spanCtx, phoneNumber, documentHash); blocked {
+		return false, &PhoneNumberBlockedError{PhoneNumber: phoneNumber, RetryAfter: ttl}
+	}
+
+	codeKey := s.buildCodeKey(phoneNumber, documentHash)
+	retryKey := s.buildRetryKey(phoneNumber, documentHash)
+
+	expected, err := s.redisClient.Client.Get(spanCtx, codeKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, fmt.Errorf("OTP not found or expired")
+	}
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) != 1 {
+		pipe := s.redisClient.Client.TxPipeline()
+		count := pipe.Incr(spanCtx, retryKey)
+		pipe.Expire(spanCtx, retryKey, s.expiry)
+		_, _ = pipe.Exec(spanCtx)
+
+		remaining := signatureOTPTotalRetries - int(count.Val())
+		if remaining <= 0 {
+			_ = s.redisClient.Client.Del(spanCtx, codeKey).Err()
+			return false, &PhoneNumberBlockedError{PhoneNumber: phoneNumber, RetryAfter: s.expiry}
+		}
+		return false, fmt.Errorf("invalid OTP, %d attempt(s) remaining", remaining)
+	}
+
+	if err := s.redisClient.Client.Del(spanCtx, codeKey, retryKey).Err(); err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *redisSignatureOTPService) buildCodeKey(phoneNumber string, documentHash string) string {
+	return fmt.Sprintf("%s:%s:%s", signatureOTPKeyPrefix, phoneNumber, documentHash)
+}
+
+func (s *redisSignatureOTPService) buildRetryKey(phoneNumber string, documentHash string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", signatureOTPKeyPrefix, phoneNumber, documentHash, retryKeySuffix)
+}
+
+func (s *redisSignatureOTPService) isBlocked(ctx context.Context, phoneNumber string, documentHash string) (bool, time.Duration) {
+	retryKey := s.buildRetryKey(phoneNumber, documentHash)
+	count, _ := strconv.Atoi(s.redisClient.Client.Get(ctx, retryKey).Val())
+	if count >= signatureOTPTotalRetries {
+		ttl, _ := s.redisClient.Client.TTL(ctx, retryKey).Result()
+		return true, ttl
+	}
+	return false, 0
+}
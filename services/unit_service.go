@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sample-web/dto"
+	"sample-web/models"
+	"sample-web/repositories"
+	"sample-web/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type UnitService interface {
+	CreateUnit(ctx context.Context, landLordId string, propertyId string, unitRequest dto.UnitRequest) (dto.UnitResponse, error)
+	GetUnitsByProperty(ctx context.Context, landLordId string, propertyId string) (dto.UnitResponse, error)
+	GetUnitById(ctx context.Context, landLordId string, propertyId string, unitId string) (dto.UnitResponse, error)
+	UpdateUnit(ctx context.Context, landLordId string, propertyId string, unitId string, unitRequest dto.UnitRequest) (dto.UnitResponse, error)
+	DeleteUnit(ctx context.Context, landLordId string, propertyId string, unitId string) error
+}
+
+type unitService struct {
+	unitRepo     repositories.UnitRepository
+	propertyRepo repositories.PropertyRepository
+	rentRepo     repositories.RentRepository
+}
+
+func NewUnitService(unitRepo repositories.UnitRepository, propertyRepo repositories.PropertyRepository, rentRepo repositories.RentRepository) UnitService {
+	return &unitService{
+		unitRepo:     unitRepo,
+		propertyRepo: propertyRepo,
+		rentRepo:     rentRepo,
+	}
+}
+
+func (u *unitService) CreateUnit(ctx context.Context, landLordId string, propertyId string, unitRequest dto.UnitRequest) (dto.UnitResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "UnitService.CreateUnit")
+	defer span.End()
+
+	property, err := u.propertyRepo.FindPropertyById(spanCtx, landLordId, propertyId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find property with %s", err.Error()))
+		return dto.UnitResponse{}, err
+	}
+
+	if err := u.checkUnitNumberIsFree(spanCtx, landLordId, propertyId, unitRequest.UnitNumber, bson.NilObjectID); err != nil {
+		log.Error(spanCtx, err.Error())
+		return dto.UnitResponse{}, err
+	}
+
+	now := time.Now()
+
+	unit := models.Unit{
+		PropertyId: property.Id,
+		LandLord:   property.LandLord,
+		UnitNumber: unitRequest.UnitNumber,
+		Type:       models.UnitType(unitRequest.Type),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Creating unit %s in property %s", unit.UnitNumber, propertyId))
+
+	createdUnit, err := u.unitRepo.CreateUnit(spanCtx, unit)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to create unit with %s", err.Error()))
+		return dto.UnitResponse{}, errors.New("failed to create unit")
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Unit created successfully with ID: %s", createdUnit.Id.Hex()))
+
+	return dto.UnitResponse{
+		Units: []models.Unit{createdUnit},
+	}, nil
+}
+
+// GetUnitsByProperty implements UnitService.
+func (u *unitService) GetUnitsByProperty(ctx context.Context, landLordId string, propertyId string) (dto.UnitResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "UnitService.GetUnitsByProperty")
+	defer span.End()
+
+	units, err := u.unitRepo.GetUnitsByProperty(spanCtx, landLordId, propertyId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get units with %s", err.Error()))
+		return dto.UnitResponse{}, err
+	}
+
+	return dto.UnitResponse{
+		Units: units,
+	}, nil
+}
+
+// GetUnitById implements UnitService.
+func (u *unitService) GetUnitById(ctx context.Context, landLordId string, propertyId string, unitId string) (dto.UnitResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "UnitService.GetUnitById")
+	defer span.End()
+
+	unit, err := u.findPropertyUnit(spanCtx, landLordId, propertyId, unitId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find unit with %s", err.Error()))
+		return dto.UnitResponse{}, err
+	}
+
+	return dto.UnitResponse{
+		Units: []models.Unit{unit},
+	}, nil
+}
+
+// UpdateUnit implements UnitService.
+func (u *unitService) UpdateUnit(ctx context.Context, landLordId string, propertyId string, unitId string, unitRequest dto.UnitRequest) (dto.UnitResponse, error) {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "UnitService.UpdateUnit")
+	defer span.End()
+
+	unit, err := u.findPropertyUnit(spanCtx, landLordId, propertyId, unitId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find unit with %s", err.Error()))
+		return dto.UnitResponse{}, err
+	}
+
+	if err := u.checkUnitNumberIsFree(spanCtx, landLordId, propertyId, unitRequest.UnitNumber, unit.Id); err != nil {
+		log.Error(spanCtx, err.Error())
+		return dto.UnitResponse{}, err
+	}
+
+	unit.UnitNumber = unitRequest.UnitNumber
+	unit.Type = models.UnitType(unitRequest.Type)
+	unit.UpdatedAt = time.Now()
+
+	updatedUnit, err := u.unitRepo.UpdateUnit(spanCtx, landLordId, unit)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to update unit with %s", err.Error()))
+		return dto.UnitResponse{}, err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Unit updated successfully with ID: %s", unitId))
+
+	return dto.UnitResponse{
+		Units: []models.Unit{updatedUnit},
+	}, nil
+}
+
+// DeleteUnit implements UnitService.
+func (u *unitService) DeleteUnit(ctx context.Context, landLordId string, propertyId string, unitId string) error {
+
+	log := utils.GetLogger()
+	spanCtx, span := log.Tracer().Start(ctx, "UnitService.DeleteUnit")
+	defer span.End()
+
+	unit, err := u.findPropertyUnit(spanCtx, landLordId, propertyId, unitId)
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to find unit with %s", err.Error()))
+		return err
+	}
+
+	rents, err := u.rentRepo.GetRentsByUnitIds(spanCtx, landLordId, []bson.ObjectID{unit.Id})
+	if err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to get rents with %s", err.Error()))
+		return err
+	}
+
+	if len(rents) > 0 {
+		log.Error(spanCtx, fmt.Sprintf("Unit %s is referenced by %d rents", unitId, len(rents)))
+		return errors.New("unit is referenced by rents and cannot be deleted")
+	}
+
+	if err := u.unitRepo.DeleteUnit(spanCtx, landLordId, unitId); err != nil {
+		log.Error(spanCtx, fmt.Sprintf("Failed to delete unit with %s", err.Error()))
+		return err
+	}
+
+	log.Info(spanCtx, fmt.Sprintf("Unit deleted successfully with ID: %s", unitId))
+
+	return nil
+}
+
+// findPropertyUnit finds a landlord's unit and checks that it belongs to the given property.
+func (u *unitService) findPropertyUnit(ctx context.Context, landLordId string, propertyId string, unitId string) (models.Unit, error) {
+
+	unit, err := u.unitRepo.FindUnitById(ctx, landLordId, unitId)
+	if err != nil {
+		return models.Unit{}, err
+	}
+
+	if unit.PropertyId.Hex() != propertyId {
+		return models.Unit{}, errors.New("unit does not belong to this property")
+	}
+
+	return unit, nil
+}
+
+// checkUnitNumberIsFree makes sure no other unit of the property uses the unit number.
+func (u *unitService) checkUnitNumberIsFree(ctx context.Context, landLordId string, propertyId string, unitNumber string, unitId bson.ObjectID) error {
+
+	units, err := u.unitRepo.GetUnitsByProperty(ctx, landLordId, propertyId)
+	if err != nil {
+		return err
+	}
+
+	for _, unit := range units {
+		if unit.UnitNumber == unitNumber && unit.Id != unitId {
+			return fmt.Errorf("unit number %s already exists in this property", unitNumber)
+		}
+	}
+	return nil
+}
@@ -3,7 +3,9 @@ package services
 import (
 	"context"
 	"sample-web/dto"
+	customerr "sample-web/errors"
 	"sample-web/mappers"
+	"sample-web/models"
 	"sample-web/repositories"
 	"time"
 )
@@ -50,7 +52,14 @@ func (u *userService) UpdateUser(ctx context.Context, userRequestDto dto.UserReq
 		return dto.UserResponse{}, err
 	}
 	user.PhoneNumber = userRequestDto.PhoneNumber
+	// the admin role is granted in the database, users can only switch to it once they hold it
+	if mappers.ToUserRole(userRequestDto.CurrentRole) == models.Admin && !user.HasRole(models.Admin) {
+		return dto.UserResponse{}, customerr.RoleNotHeldError{Role: string(models.Admin)}
+	}
+	// switching roles keeps the previous one, the user holds both from now on
+	user.AddRole(user.CurrentRole)
 	user.CurrentRole = mappers.ToUserRole(userRequestDto.CurrentRole)
+	user.AddRole(user.CurrentRole)
 	user.UpdatedAt = time.Now()
 	updatedUser, err := u.userRepo.UpdateUser(ctx, user)
 	if err != nil {
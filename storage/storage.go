@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sample-web/configs"
+	"time"
+)
+
+// ErrNotFound is returned when no blob is stored under the key.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStorage stores files by key. Files are never served by the key directly, clients get a
+// signed URL that stops working after a while.
+type BlobStorage interface {
+	Put(ctx context.Context, key string, contentType string, body io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	SignedURL(ctx context.Context, key string, expiresIn time.Duration) (string, error)
+}
+
+// NewBlobStorage returns the storage of the configured driver.
+func NewBlobStorage(cfg configs.StorageConfig) (BlobStorage, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocalStorage(cfg.LocalPath, cfg.BaseURL, cfg.SigningKey)
+	case "s3":
+		return NewS3Storage(cfg.S3), nil
+	default:
+		return nil, errors.New("unknown storage driver " + cfg.Driver)
+	}
+}